@@ -17,7 +17,8 @@ const assertPRBitbucketShortDesc = `Assert a Bitbucket pull request for a git co
 
 const assertPRBitbucketLongDesc = assertPRBitbucketShortDesc + `
 The command exits with non-zero exit code if no pull requests were found for the commit.
-Authentication to Bitbucket can be done with access token (recommended) or app passwords. Credentials need to have read access for both repos and pull requests.`
+Authentication to Bitbucket can be done with access token (recommended) or app passwords. Credentials need to have read access for both repos and pull requests.
+For Bitbucket Data Center, set ^--bitbucket-base-url^ to your instance URL and ^--bitbucket-workspace^ to the project key.`
 
 const assertPRBitbucketExample = `
 kosli assert pullrequest bitbucket  \
@@ -25,6 +26,14 @@ kosli assert pullrequest bitbucket  \
 	--bitbucket-workspace yourBitbucketWorkspace \
 	--commit yourGitCommit \
 	--repository yourBitbucketGitRepository
+
+# assert a Bitbucket Data Center pull request exists for a commit:
+kosli assert pullrequest bitbucket  \
+	--bitbucket-base-url https://bitbucket.example.com \
+	--bitbucket-access-token yourBitbucketHTTPAccessToken \
+	--bitbucket-workspace yourBitbucketProjectKey \
+	--commit yourGitCommit \
+	--repository yourBitbucketGitRepository
 `
 
 func newAssertPullRequestBitbucketCmd(out io.Writer) *cobra.Command {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/kosli-dev/cli/internal/testHelpers"
+	"github.com/maxcnunes/httpfake"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -61,8 +62,69 @@ func (suite *AssertPRBitbucketCommandTestSuite) TestAssertPRBitbucketCmd() {
 	runTestCmd(suite.Suite.T(), tests)
 }
 
+// AssertPRBitbucketDataCenterCommandTestSuite runs against a fake Bitbucket Data Center server
+type AssertPRBitbucketDataCenterCommandTestSuite struct {
+	suite.Suite
+	fakeBitbucket         *httpfake.HTTPFake
+	defaultBitbucketFlags string
+}
+
+// create a fake Bitbucket Data Center server before the suite execution
+func (suite *AssertPRBitbucketDataCenterCommandTestSuite) SetupSuite() {
+	repoPath := "/rest/api/1.0/projects/ACME/repos/shop"
+	suite.fakeBitbucket = httpfake.New()
+	suite.fakeBitbucket.NewHandler().
+		Get(repoPath + "/commits/9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6/pull-requests").
+		Reply(200).
+		BodyString(`{"isLastPage": true, "values": [{"id": 7, "state": "MERGED", "author": {"user": {"displayName": "Alice Smith"}},
+			"reviewers": [{"user": {"displayName": "Carol White"}, "approved": true, "status": "APPROVED"}],
+			"links": {"self": [{"href": "https://bitbucket.acme.com/projects/ACME/repos/shop/pull-requests/7"}]}}]}`)
+	suite.fakeBitbucket.NewHandler().
+		Get(repoPath + "/commits/3dce097040987c4693d2e4be817474d9d0063c93/pull-requests").
+		Reply(200).
+		BodyString(`{"isLastPage": true, "values": []}`)
+	suite.fakeBitbucket.NewHandler().
+		Get(repoPath + "/pull-requests/7/activities").
+		Reply(200).
+		BodyString(`{"isLastPage": true, "values": []}`)
+	suite.fakeBitbucket.NewHandler().
+		Get(repoPath + "/pull-requests/7/commits").
+		Reply(200).
+		BodyString(`{"isLastPage": true, "values": []}`)
+
+	suite.defaultBitbucketFlags = fmt.Sprintf(" --bitbucket-base-url %s --bitbucket-access-token some-token --bitbucket-workspace ACME --repository shop",
+		suite.fakeBitbucket.ResolveURL(""))
+}
+
+// shutdown the fake Bitbucket Data Center server after the suite execution
+func (suite *AssertPRBitbucketDataCenterCommandTestSuite) TearDownSuite() {
+	suite.fakeBitbucket.Close()
+}
+
+func (suite *AssertPRBitbucketDataCenterCommandTestSuite) TestAssertPRBitbucketDataCenterCmd() {
+	tests := []cmdTestCase{
+		{
+			name:   "assert Bitbucket Data Center PR evidence passes when commit has a PR",
+			cmd:    "assert pullrequest bitbucket --commit 9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6" + suite.defaultBitbucketFlags,
+			golden: "found [1] pull request(s) in Bitbucket for commit: 9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6\n",
+		},
+		{
+			wantError: true,
+			name:      "assert Bitbucket Data Center PR evidence fails when commit has no PRs",
+			cmd:       "assert pullrequest bitbucket --commit 3dce097040987c4693d2e4be817474d9d0063c93" + suite.defaultBitbucketFlags,
+			golden:    "Error: assert failed: found no pull request(s) in Bitbucket for commit: 3dce097040987c4693d2e4be817474d9d0063c93\n",
+		},
+	}
+
+	runTestCmd(suite.Suite.T(), tests)
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestAssertPRBitbucketCommandTestSuite(t *testing.T) {
 	suite.Run(t, new(AssertPRBitbucketCommandTestSuite))
 }
+
+func TestAssertPRBitbucketDataCenterCommandTestSuite(t *testing.T) {
+	suite.Run(t, new(AssertPRBitbucketDataCenterCommandTestSuite))
+}
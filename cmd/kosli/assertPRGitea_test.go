@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/maxcnunes/httpfake"
+	"github.com/stretchr/testify/suite"
+)
+
+// Define the suite, and absorb the built-in basic suite
+// functionality from testify - including a T() method which
+// returns the current testing context
+type AssertPRGiteaCommandTestSuite struct {
+	suite.Suite
+	fakeGitea         *httpfake.HTTPFake
+	defaultGiteaFlags string
+}
+
+// create a fake Gitea server before the suite execution
+func (suite *AssertPRGiteaCommandTestSuite) SetupSuite() {
+	suite.fakeGitea = httpfake.New()
+	suite.fakeGitea.NewHandler().
+		Get("/api/v1/repos/acme/shop/commits/9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6/pull").
+		Reply(200).
+		BodyString(`{"number": 7, "state": "closed", "merged": true, "html_url": "https://gitea.acme.com/acme/shop/pulls/7",
+			"merge_commit_sha": "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6", "user": {"login": "alice"},
+			"head": {"sha": "b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1"}}`)
+	suite.fakeGitea.NewHandler().
+		Get("/api/v1/repos/acme/shop/pulls").
+		Reply(200).
+		BodyString(`[]`)
+	suite.fakeGitea.NewHandler().
+		Get("/api/v1/repos/acme/shop/pulls/7/reviews").
+		Reply(200).
+		BodyString(`[{"user": {"login": "carol"}, "state": "APPROVED", "submitted_at": "2024-03-02T15:00:00Z"}]`)
+	suite.fakeGitea.NewHandler().
+		Get("/api/v1/repos/acme/shop/pulls/7/commits").
+		Reply(200).
+		BodyString(`[{"sha": "b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1", "author": {"login": "alice"},
+			"commit": {"author": {"name": "Alice Smith"}, "committer": {"date": "2024-03-02T09:00:00Z"}}}]`)
+
+	suite.defaultGiteaFlags = fmt.Sprintf(" --gitea-token some-token --gitea-base-url %s --gitea-org acme --repository shop",
+		suite.fakeGitea.ResolveURL(""))
+}
+
+// shutdown the fake Gitea server after the suite execution
+func (suite *AssertPRGiteaCommandTestSuite) TearDownSuite() {
+	suite.fakeGitea.Close()
+}
+
+func (suite *AssertPRGiteaCommandTestSuite) TestAssertPRGiteaCmd() {
+	tests := []cmdTestCase{
+		{
+			name:   "assert Gitea PR evidence passes when commit has a PR in Gitea",
+			cmd:    "assert pullrequest gitea --commit 9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6" + suite.defaultGiteaFlags,
+			golden: "found [1] pull request(s) in Gitea for commit: 9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6\n",
+		},
+		{
+			name:   "assert Gitea PR evidence works with the forgejo alias",
+			cmd:    "assert pullrequest forgejo --commit 9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6" + suite.defaultGiteaFlags,
+			golden: "found [1] pull request(s) in Gitea for commit: 9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6\n",
+		},
+		{
+			wantError: true,
+			name:      "assert Gitea PR evidence fails when commit has no PRs in Gitea",
+			cmd:       "assert pullrequest gitea --commit 3dce097040987c4693d2e4be817474d9d0063c93" + suite.defaultGiteaFlags,
+			golden:    "Error: assert failed: found no pull request(s) in Gitea for commit: 3dce097040987c4693d2e4be817474d9d0063c93\n",
+		},
+		{
+			wantError: true,
+			name:      "assert Gitea PR evidence fails when --gitea-base-url is missing",
+			cmd:       "assert pullrequest gitea --commit 3dce097040987c4693d2e4be817474d9d0063c93 --gitea-token some-token --repository shop",
+			golden:    "Error: required flag(s) \"gitea-base-url\" not set\n",
+		},
+	}
+
+	runTestCmd(suite.Suite.T(), tests)
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestAssertPRGiteaCommandTestSuite(t *testing.T) {
+	suite.Run(t, new(AssertPRGiteaCommandTestSuite))
+}
@@ -20,8 +20,18 @@ func newAttestCmd(out io.Writer) *cobra.Command {
 		newAttestArtifactCmd(out),
 		newAttestGenericCmd(out),
 		newAttestSnykCmd(out),
+		newAttestSarifCmd(out),
+		newAttestSbomCmd(out),
+		newAttestProvenanceCmd(out),
+		newAttestSignatureCmd(out),
+		newAttestCommitSignaturesCmd(out),
+		newAttestChangelogCmd(out),
+		newAttestCoverageCmd(out),
+		newAttestSecretsCmd(out),
 		newAttestJunitCmd(out),
+		newAttestTestResultsCmd(out),
 		newAttestJiraCmd(out),
+		newAttestIssueCmd(out),
 		newAttestPRCmd(out),
 		newAttestSonarCmd(out),
 		newAttestCustomCmd(out),
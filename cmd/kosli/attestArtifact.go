@@ -42,6 +42,9 @@ const attestArtifactShortDesc = `Attest an artifact creation to a Kosli flow.  `
 
 const attestArtifactLongDesc = attestArtifactShortDesc + `
 ` + fingerprintDesc + kosliIgnoreDesc + `
+
+` + fingerprintGitTreeSynopsis + `
+
 This command requires access to a git repo to associate the artifact to the git commit it is originating from. 
 You can optionally redact some of the git commit data sent to Kosli using ^--redact-commit-info^`
 
@@ -59,6 +62,18 @@ kosli attest artifact FILE.tgz \
 	--org yourOrgName
 
 
+# Attest that the config/prod directory of the git tree at a commit has been released, and let Kosli calculate its fingerprint
+kosli attest artifact yourCommitShaThatThisArtifactWasBuiltFrom:config/prod \
+	--artifact-type git-tree \
+	--build-url https://exampleci.com \
+	--commit-url https://github.com/YourOrg/YourProject/commit/yourCommitShaThatThisArtifactWasBuiltFrom \
+	--commit yourCommitShaThatThisArtifactWasBuiltFrom \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--name yourTemplateArtifactName \
+	--api-token yourApiToken \
+	--org yourOrgName
+
 # Attest that an artifact has been created and provide its fingerprint (sha256) 
 kosli attest artifact ANOTHER_FILE.txt \
 	--build-url https://exampleci.com \
@@ -173,13 +188,14 @@ func (o *attestArtifactOptions) run(args []string) error {
 	}
 
 	if o.payload.Fingerprint == "" {
+		o.fingerprintOptions.repoRoot = o.srcRepoRoot
 		o.payload.Fingerprint, err = GetSha256Digest(args[0], o.fingerprintOptions, logger)
 		if err != nil {
 			return err
 		}
 	}
 
-	gitView, err := gitview.New(o.srcRepoRoot)
+	gitView, err := newGitView(o.srcRepoRoot)
 	if err != nil {
 		return err
 	}
@@ -191,7 +207,7 @@ func (o *attestArtifactOptions) run(args []string) error {
 	o.payload.GitCommit = commitInfo.Sha1
 	o.payload.GitCommitInfo = &commitInfo.BasicCommitInfo
 
-	o.payload.RepoUrl, err = gitView.RepoURL()
+	o.payload.RepoUrl, err = gitView.RepoURL(commitInfo.Sha1)
 	if err != nil {
 		logger.Warn("Repo URL will not be reported, %s", err.Error())
 	}
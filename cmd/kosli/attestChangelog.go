@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/kosli-dev/cli/internal/changelog"
+	"github.com/kosli-dev/cli/internal/gitview"
+	"github.com/kosli-dev/cli/internal/jira"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+type attestChangelogOptions struct {
+	*CommonAttestationOptions
+	environment  string
+	oldestCommit string
+	issuePattern string
+	paths        []string
+	payload      GenericAttestationPayload
+}
+
+const attestChangelogShortDesc = `Report a changelog attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestChangelogLongDesc = attestChangelogShortDesc + `
+Computes the changelog of a deployment: the commits between the commit of the artifact of the flow currently
+running in the ^--environment^ (in its latest snapshot in Kosli) and the ^--commit^, e.g. the commit of the artifact
+to deploy. When several artifacts of the flow are running, the changelog starts at the one running the longest.
+Alternatively, the changelog starts after the ^--oldest-commit^. When no artifact of the flow is running in the
+environment, the changelog is only the ^--commit^.
+
+For each commit, the changelog records its message, author, the paths it changed, the pull requests it merged
+(from the merge commit messages of GitHub, GitLab, Bitbucket and Azure DevOps) and the issues it references
+(matching ^--issue-pattern^, Jira issue keys by default). The authors, paths, pull requests and issues of all
+the commits are summarized.
+
+In a monorepo, ^--paths^ scopes the changelog to the commits changing files in the given paths,
+e.g. ^--paths services/a/**,libs/common/**^. Files renamed into the paths are followed.
+
+The attestation is reported as a compliant generic attestation, with the changelog in its user data
+(under ^changelog^). The changelog is also uploaded to Kosli's evidence vault as ^changelog.json^.
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestChangelogExample = `
+# report a changelog attestation about a trail, with the commits since the artifact currently in production:
+kosli attest changelog \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--commit yourArtifactGitCommit \
+	--environment production \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a changelog attestation about an artifact, with the commits since a release tag,
+# finding Linear issue references:
+kosli attest changelog yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--commit yourArtifactGitCommit \
+	--oldest-commit v1.2.0 \
+	--issue-pattern '[A-Z][A-Z0-9]{0,6}-[0-9]+' \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestChangelogCmd(out io.Writer) *cobra.Command {
+	o := &attestChangelogOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "changelog [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestChangelogShortDesc,
+		Long:    attestChangelogLongDesc,
+		Example: attestChangelogExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"environment", "oldest-commit"}, true)
+			if err != nil {
+				return err
+			}
+
+			err = gitview.ValidatePaths(o.paths)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.environment, "environment", "", changelogEnvironmentFlag)
+	cmd.Flags().StringVar(&o.oldestCommit, "oldest-commit", "", changelogOldestCommitFlag)
+	cmd.Flags().StringVar(&o.issuePattern, "issue-pattern", jira.IssueKeyPattern, changelogIssuePatternFlag)
+	cmd.Flags().StringSliceVar(&o.paths, "paths", []string{}, commitPathsFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "commit"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestChangelogOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	gv, err := newGitView(o.srcRepoRoot)
+	if err != nil {
+		return err
+	}
+	newest := o.payload.Commit.Sha1
+	oldest := ""
+	if o.oldestCommit != "" {
+		oldest, err = gv.ResolveRevision(o.oldestCommit)
+		if err != nil {
+			return err
+		}
+	} else {
+		oldest, err = runningArtifactCommit(o.flowName, o.environment)
+		if err != nil {
+			if !global.DryRun {
+				return err
+			}
+			// in a dry run, the changelog is only the newest commit
+			oldest = ""
+		}
+		if oldest == "" {
+			logger.Info("no artifact of flow %s is running in environment %s, the changelog is only commit %s", o.flowName, o.environment, newest)
+		}
+	}
+
+	commitChangelog, err := changelog.New(gv, oldest, newest, o.paths, o.issuePattern, logger)
+	if err != nil {
+		return err
+	}
+	o.payload.Compliant = true
+	o.payload.UserData = mergeUserData(o.payload.UserData, "changelog", struct {
+		Environment string `json:"environment,omitempty"`
+		*changelog.Changelog
+	}{o.environment, commitChangelog})
+
+	changelogDir, err := os.MkdirTemp("", "changelog")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(changelogDir)
+	changelogPath := filepath.Join(changelogDir, "changelog.json")
+	content, err := json.MarshalIndent(commitChangelog, "", "    ")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(changelogPath, content, 0600)
+	if err != nil {
+		return err
+	}
+	o.attachments = append(o.attachments, changelogPath)
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("changelog attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+	return wrapAttestationError(err)
+}
+
+// runningArtifactCommit returns the git commit of the artifact of the flow running in the latest snapshot of
+// the environment. When several artifacts of the flow are running, it is the commit of the one running the
+// longest, so that the changelog covers all of them. It is empty when no artifact of the flow is running.
+func runningArtifactCommit(flowName, environment string) (string, error) {
+	url := fmt.Sprintf("%s/api/v2/snapshots/%s/%s/-1", global.Host, global.Org, environment)
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodGet,
+		URL:    url,
+		Token:  global.ApiToken,
+	}
+	response, err := kosliClient.Do(reqParams)
+	if err != nil {
+		return "", err
+	}
+
+	var snapshot Snapshot
+	err = json.Unmarshal([]byte(response.Body), &snapshot)
+	if err != nil {
+		return "", err
+	}
+	return oldestRunningCommit(snapshot, flowName), nil
+}
+
+// oldestRunningCommit returns the git commit of the artifact of the flow running the longest in the snapshot
+func oldestRunningCommit(snapshot Snapshot, flowName string) string {
+	commit := ""
+	var runningSince int64
+	for _, artifact := range snapshot.Artifacts {
+		// artifacts which stopped running are still listed, with no instances now
+		if artifact.FlowName != flowName || artifact.GitCommit == "" || artifact.Annotation.Now == 0 {
+			continue
+		}
+		since := int64(0)
+		if len(artifact.CreationTimestamp) > 0 {
+			since = artifact.CreationTimestamp[0]
+			for _, timestamp := range artifact.CreationTimestamp {
+				if timestamp < since {
+					since = timestamp
+				}
+			}
+		}
+		if commit == "" || since < runningSince {
+			commit = artifact.GitCommit
+			runningSince = since
+		}
+	}
+	return commit
+}
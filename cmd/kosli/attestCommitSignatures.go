@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/commitsignature"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+type attestCommitSignaturesOptions struct {
+	*CommonAttestationOptions
+	gpgKeyRingPath     string
+	allowedSignersPath string
+	commitRange        commitRangeOptions
+	assert             bool
+	payload            GenericAttestationPayload
+}
+
+// CommitSignaturesResults is the outcome of verifying the signatures of a range of commits
+type CommitSignaturesResults struct {
+	*commitsignature.Results
+	// OldestCommit is the commit after which the signatures are verified, it is empty for a single commit
+	OldestCommit string `json:"oldest_commit,omitempty"`
+	NewestCommit string `json:"newest_commit"`
+}
+
+const attestCommitSignaturesShortDesc = `Report a commit signatures attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestCommitSignaturesLongDesc = attestCommitSignaturesShortDesc + `
+Verifies the GPG or SSH signature of the commits in a range, e.g. all the commits in a release, offline.
+With ^--since-commit^ or ^--commit-range^, all the commits in the range are verified. Otherwise, only the
+^--commit^ is verified.
+
+GPG signatures are verified against the public keys of the ^--gpg-keyring^ (e.g. exported with ^gpg --export --armor^),
+at the time of the commit. SSH signatures are verified against the keys of the ^--allowed-signers^ file, which has the
+format of git's ^gpg.ssh.allowedSignersFile^ (the ^namespaces^, ^valid-after^ and ^valid-before^ options are supported).
+x509 signatures are not supported.
+
+The unsigned commits, and the commits whose signature does not verify against a trusted key, are reported.
+The attestation is compliant only when all the commits have a verified signature.
+
+The attestation is reported as a generic attestation, with the verification result of each commit in its user data
+(under ^commit_signature_results^). The keyring and allowed signers files are uploaded to Kosli's evidence vault.
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestCommitSignaturesExample = `
+# report a commit signatures attestation about a trail, verifying the commits since the previous release with GPG keys:
+kosli attest commit-signatures \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--commit yourGitCommitSha1 \
+	--since-commit yourPreviousReleaseGitCommit \
+	--gpg-keyring trusted-keys.asc \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a commit signatures attestation about a trail, verifying a range of commits with GPG and SSH keys,
+# and fail if it is non-compliant:
+kosli attest commit-signatures \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--commit yourGitCommitSha1 \
+	--commit-range v1.2.0..v1.3.0 \
+	--gpg-keyring trusted-keys.asc \
+	--allowed-signers allowed_signers \
+	--assert \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestCommitSignaturesCmd(out io.Writer) *cobra.Command {
+	o := &attestCommitSignaturesOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "commit-signatures [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestCommitSignaturesShortDesc,
+		Long:    attestCommitSignaturesLongDesc,
+		Example: attestCommitSignaturesExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = RequireAtLeastOneOfFlags(cmd, []string{"gpg-keyring", "allowed-signers"})
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = o.commitRange.validate(cmd)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.gpgKeyRingPath, "gpg-keyring", "", commitSignaturesGPGKeyRingFlag)
+	cmd.Flags().StringVar(&o.allowedSignersPath, "allowed-signers", "", commitSignaturesAllowedSignersFlag)
+	cmd.Flags().StringVar(&o.commitRange.sinceCommit, "since-commit", "", commitSignaturesSinceCommitFlag)
+	cmd.Flags().StringVar(&o.commitRange.commitRange, "commit-range", "", commitSignaturesCommitRangeFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "commit"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestCommitSignaturesOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	verifier := &commitsignature.Verifier{}
+	if o.gpgKeyRingPath != "" {
+		verifier.KeyRing, err = commitsignature.LoadGPGKeyRing(o.gpgKeyRingPath)
+		if err != nil {
+			return err
+		}
+		o.attachments = append(o.attachments, o.gpgKeyRingPath)
+	}
+	if o.allowedSignersPath != "" {
+		verifier.AllowedSigners, err = commitsignature.LoadAllowedSigners(o.allowedSignersPath)
+		if err != nil {
+			return err
+		}
+		o.attachments = append(o.attachments, o.allowedSignersPath)
+	}
+
+	gv, err := newGitView(o.srcRepoRoot)
+	if err != nil {
+		return err
+	}
+	oldest, newest, isRange := o.commitRange.bounds(o.payload.Commit.Sha1)
+	if !isRange {
+		oldest = newest
+	}
+	commits, err := gv.SignedCommitsBetween(oldest, newest, logger)
+	if err != nil {
+		return err
+	}
+
+	signatureResults := &CommitSignaturesResults{Results: verifier.VerifyCommits(commits), NewestCommit: newest}
+	if isRange {
+		signatureResults.OldestCommit = oldest
+	}
+	failures := []string{}
+	for _, commit := range signatureResults.Commits {
+		logger.Debug("commit %s signature is %s %s", commit.Sha1, commit.Status, commit.Reason)
+		if commit.Status == commitsignature.StatusVerified {
+			continue
+		}
+		failure := fmt.Sprintf("%s: %s", commit.Sha1, commit.Status)
+		if commit.Reason != "" {
+			failure += fmt.Sprintf(" (%s)", commit.Reason)
+		}
+		logger.Info("commit %s", failure)
+		failures = append(failures, failure)
+	}
+	o.payload.Compliant = signatureResults.Compliant()
+	o.payload.UserData = mergeUserData(o.payload.UserData, "commit_signature_results", signatureResults)
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("commit-signatures attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if err == nil && !o.payload.Compliant && o.assert {
+		return fmt.Errorf("found %d commit(s) without a verified signature:\n\t%s", len(failures), strings.Join(failures, "\n\t"))
+	}
+	return wrapAttestationError(err)
+}
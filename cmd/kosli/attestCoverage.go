@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/coverage"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+type attestCoverageOptions struct {
+	*CommonAttestationOptions
+	reportFilePath     string
+	format             string
+	baselineReportPath string
+	thresholds         coverage.Thresholds
+	uploadReport       bool
+	assert             bool
+	payload            GenericAttestationPayload
+}
+
+const attestCoverageShortDesc = `Report a code coverage attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestCoverageLongDesc = attestCoverageShortDesc + `
+The ^--report^ file is parsed according to ^--format^, which is one of:
+- ^cobertura^: Cobertura XML (e.g. from coverage.py, gcovr or Istanbul's cobertura reporter).
+- ^lcov^: LCOV tracefiles (e.g. from Istanbul/nyc, c8 or lcov).
+- ^jacoco^: JaCoCo XML.
+- ^go^: profiles written by ^go test -coverprofile^. Go profiles count statements and have no branch data,
+  so the line coverage is the statement coverage.
+
+The line and branch coverage is computed overall and per package (directory, for formats without packages).
+The attestation is compliant when the overall coverage is at least ^--min-line^ and ^--min-branch^ percent.
+
+When a ^--baseline-report^ is given (e.g. the report of the main branch), coverage drops compared to it
+are recorded overall and per package. An overall drop larger than ^--max-drop^ percentage points
+(0 by default) makes the attestation non-compliant.
+
+The compliance is decided locally and the attestation is reported as a generic attestation,
+with the coverage summary in its user data (under ^coverage_results^).
+By default, the report files are also uploaded to Kosli's evidence vault.
+You can disable that by setting ^--upload-report=false^
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestCoverageExample = `
+# report a coverage attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest coverage yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--report coverage.xml \
+	--format cobertura \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a coverage attestation about a trail with minimum coverage thresholds:
+kosli attest coverage \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--report coverage/lcov.info \
+	--format lcov \
+	--min-line 80 \
+	--min-branch 60 \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a coverage attestation about a trail, comparing against the coverage of the main branch,
+# and fail if the coverage drops by more than 1%:
+kosli attest coverage \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--report coverage.out \
+	--format go \
+	--baseline-report main-coverage.out \
+	--max-drop 1 \
+	--assert \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestCoverageCmd(out io.Writer) *cobra.Command {
+	o := &attestCoverageOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "coverage [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestCoverageShortDesc,
+		Long:    attestCoverageLongDesc,
+		Example: attestCoverageExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = coverage.ValidateFormat(o.format)
+			if err != nil {
+				return fmt.Errorf("%s for --format", err.Error())
+			}
+
+			for flag, value := range map[string]float64{
+				"min-line":   o.thresholds.MinLine,
+				"min-branch": o.thresholds.MinBranch,
+				"max-drop":   o.thresholds.MaxDrop,
+			} {
+				if value < 0 || value > 100 {
+					return fmt.Errorf("--%s must be between 0 and 100", flag)
+				}
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.reportFilePath, "report", "", coverageReportFlag)
+	cmd.Flags().StringVar(&o.format, "format", "", coverageFormatFlag)
+	cmd.Flags().Float64Var(&o.thresholds.MinLine, "min-line", 0, coverageMinLineFlag)
+	cmd.Flags().Float64Var(&o.thresholds.MinBranch, "min-branch", 0, coverageMinBranchFlag)
+	cmd.Flags().StringVar(&o.baselineReportPath, "baseline-report", "", coverageBaselineReportFlag)
+	cmd.Flags().Float64Var(&o.thresholds.MaxDrop, "max-drop", 0, coverageMaxDropFlag)
+	cmd.Flags().BoolVar(&o.uploadReport, "upload-report", true, uploadCoverageReportFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "report", "format"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestCoverageOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	coverageResults, err := coverage.ProcessCoverageFile(o.reportFilePath, o.format)
+	if err != nil {
+		return err
+	}
+	if o.uploadReport {
+		o.attachments = append(o.attachments, o.reportFilePath)
+	}
+
+	if o.baselineReportPath != "" {
+		baselineResults, err := coverage.ProcessCoverageFile(o.baselineReportPath, o.format)
+		if err != nil {
+			return err
+		}
+		coverageResults.Compare(o.baselineReportPath, baselineResults)
+		if o.uploadReport {
+			o.attachments = append(o.attachments, o.baselineReportPath)
+		}
+	}
+
+	o.payload.Compliant = coverageResults.Evaluate(o.thresholds)
+	o.payload.UserData = mergeUserData(o.payload.UserData, "coverage_results", coverageResults)
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("coverage attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if err == nil && !o.payload.Compliant && o.assert {
+		return fmt.Errorf("%s", strings.Join(coverageResults.Violations, "\n"))
+	}
+	return wrapAttestationError(err)
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strings"
 
+	"github.com/kosli-dev/cli/internal/attestationtype"
 	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
 )
@@ -161,6 +164,11 @@ func (o *attestCustomOptions) run(args []string) error {
 		return fmt.Errorf("failed to load attestation data. %s", err)
 	}
 
+	err = o.validateAttestationData()
+	if err != nil {
+		return err
+	}
+
 	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
 	if err != nil {
 		return err
@@ -183,3 +191,33 @@ func (o *attestCustomOptions) run(args []string) error {
 	}
 	return wrapAttestationError(err)
 }
+
+// validateAttestationData checks the attestation data against the schema and jq rules of
+// its attestation type before uploading it, so that mistakes are reported without a round trip.
+// A schema violation fails the command. Rule results are only printed, since Kosli records
+// the attestation as non-compliant when a rule fails. A requested version of the attestation type
+// which does not exist fails the command, while other failures to fetch the type only skip the validation.
+func (o *attestCustomOptions) validateAttestationData() error {
+	definition, err := fetchAttestationTypeDefinition(o.payload.TypeName)
+	if err != nil {
+		var versionNotFound *attestationTypeVersionNotFoundError
+		if errors.As(err, &versionNotFound) {
+			return err
+		}
+		logger.Warn("skipping local validation of attestation data: %s", err)
+		return nil
+	}
+	result, err := attestationtype.Evaluate(definition, o.payload.AttestationData)
+	if err != nil {
+		logger.Warn("skipping local validation of attestation data: %s", err)
+		return nil
+	}
+	if !result.SchemaPassed() {
+		return fmt.Errorf("attestation data in %s does not match the schema of attestation type '%s':\n  %s",
+			o.attestationDataFile, o.payload.TypeName, strings.Join(result.SchemaErrors, "\n  "))
+	}
+	for _, rule := range result.Rules {
+		logger.Info("%s", formatRuleResult(rule))
+	}
+	return nil
+}
@@ -38,6 +38,7 @@ func (suite *AttestCustomCommandTestSuite) SetupTest() {
 	suite.defaultKosliArguments = fmt.Sprintf(" --type %s --attestation-data %s --flow %s --trail %s --repo-root ../.. --host %s --org %s --api-token %s", suite.typeName, suite.attestationDataFile, suite.flowName, suite.trailName, global.Host, global.Org, global.ApiToken)
 
 	CreateCustomAttestationType(suite.typeName, suite.schemaFilePath, suite.jqRules, suite.Suite.T())
+	CreateCustomAttestationType("adult", "testdata/attestation-type/adult-schema.json", []string{".age < 65", ".age > 40"}, suite.Suite.T())
 	CreateFlow(suite.flowName, suite.Suite.T())
 	CreateArtifactOnTrail(suite.flowName, suite.trailName, "cli", suite.artifactFingerprint, "file1", suite.Suite.T())
 }
@@ -158,6 +159,17 @@ func (suite *AttestCustomCommandTestSuite) TestAttestCustomCmd() {
 			cmd:       fmt.Sprintf("attest custom --name bar --annotate foo.baz=bar %s", suite.defaultKosliArguments),
 			golden:    "Error: --annotate flag should be in the format key=value. Invalid key: 'foo.baz'. Key can only contain [A-Za-z0-9_].\n",
 		},
+		{
+			wantError: true,
+			name:      "fails before uploading when data does not match the schema of the attestation type",
+			cmd:       fmt.Sprintf("attest custom --name bar --type adult --attestation-data testdata/attestation-type/minor.json --flow %s --trail %s --repo-root ../.. --host %s --org %s --api-token %s", suite.flowName, suite.trailName, global.Host, global.Org, global.ApiToken),
+			golden:    "Error: attestation data in testdata/attestation-type/minor.json does not match the schema of attestation type 'adult':\n  at '/age': minimum: got 12, want 18\n",
+		},
+		{
+			name:   "prints the result of each jq rule of the attestation type",
+			cmd:    fmt.Sprintf("attest custom --name bar --type adult --attestation-data %s --flow %s --trail %s --repo-root ../.. --host %s --org %s --api-token %s", suite.attestationDataFile, suite.flowName, suite.trailName, global.Host, global.Org, global.ApiToken),
+			golden: "rule '.age < 65': passed\nrule '.age > 40': failed (false)\ncustom:adult attestation 'bar' is reported to trail: test-321\n",
+		},
 	}
 
 	runTestCmd(suite.Suite.T(), tests)
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	azUtils "github.com/kosli-dev/cli/internal/azure"
+	ghUtils "github.com/kosli-dev/cli/internal/github"
+	gitlabUtils "github.com/kosli-dev/cli/internal/gitlab"
+	"github.com/kosli-dev/cli/internal/jira"
+	"github.com/kosli-dev/cli/internal/linear"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/spf13/cobra"
+)
+
+// issueTrackerNames are the display names of the supported issue trackers
+var issueTrackerNames = map[string]string{
+	"jira":         "Jira",
+	"github":       "GitHub",
+	"gitlab":       "GitLab",
+	"linear":       "Linear",
+	"azure-boards": "Azure Boards",
+}
+
+// issueTrackerRequiredFlags are the flags that must have a value for each issue tracker
+var issueTrackerRequiredFlags = map[string][]string{
+	"jira":         {"jira-base-url"},
+	"github":       {"github-token", "github-org", "repository"},
+	"gitlab":       {"gitlab-token", "gitlab-org", "repository"},
+	"linear":       {"linear-api-key"},
+	"azure-boards": {"azure-token", "azure-org-url", "project"},
+}
+
+type attestIssueOptions struct {
+	*CommonAttestationOptions
+	tracker           string
+	jiraConfig        jira.JiraConfig
+	githubConfig      ghUtils.GithubFlagsTempValueHolder
+	gitlabConfig      gitlabUtils.GitlabConfig
+	azureConfig       azUtils.AzureFlagsTempValueHolder
+	linearAPIKey      string
+	linearBaseURL     string
+	repository        string
+	secondarySource   string
+	ignoreBranchMatch bool
+	commitRange       commitRangeOptions
+	assert            bool
+	payload           GenericAttestationPayload
+}
+
+// IssueResults is the outcome of looking up the issue references of a commit in an issue tracker
+type IssueResults struct {
+	Tracker string             `json:"tracker"`
+	Issues  []*types.IssueInfo `json:"issues"`
+	// PullRequests are the references to pull requests, which are not issue references
+	PullRequests []*types.IssueInfo `json:"pull_requests,omitempty"`
+	// CommitRange is only set when searching a range of commits
+	CommitRange *CommitRangeReferences `json:"commit_range,omitempty"`
+}
+
+const attestIssueShortDesc = `Report an issue tracker attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestIssueLongDesc = attestIssueShortDesc + `
+Parses the given commit's message, current branch name or the content of the ^--secondary-source^
+argument for references to issues in the issue tracker given with ^--tracker^:
+- ^jira^: issue keys like ^ABC-12^ (see ^kosli attest jira^).
+- ^github^: issues of the ^--repository^ referenced like ^#12^ or ^GH-12^. The references to pull requests,
+  e.g. in merge and squash merge commit messages, are reported under ^pull_requests^ and are not issue references.
+- ^gitlab^: issues of the ^--repository^ referenced like ^#12^.
+- ^linear^: issue identifiers like ^ENG-12^.
+- ^azure-boards^: work items of the ^--project^ referenced like ^AB#12^.
+
+If the ^--ignore-branch-match^ is set, the branch name is not parsed for a match.
+
+With ^--since-commit^ or ^--commit-range^, the messages of all the commits in the range are parsed for
+references, e.g. all the commits in a release. The commits without references, other than merge commits,
+are reported.
+
+The found issue references are checked against the issue tracker to confirm their existence,
+and their status is recorded. The attestation is reported in all cases, and it is compliant
+when at least one issue is referenced, all the referenced issues exist and, when searching a range
+of commits, every commit has a reference.
+
+The attestation is reported as a generic attestation, with the issues in its user data (under ^issue_results^).
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestIssueExample = `
+# report a GitHub issues attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest issue yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--tracker github \
+	--github-token yourGithubToken \
+	--github-org yourGithubOrg \
+	--repository yourGithubGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a GitLab issues attestation about a trail:
+kosli attest issue \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--tracker gitlab \
+	--gitlab-token yourGitlabToken \
+	--gitlab-org yourGitlabOrg \
+	--repository yourGitlabGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a Linear issues attestation about a trail, and fail if no issue reference is found
+# or a referenced issue does not exist:
+kosli attest issue \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--tracker linear \
+	--linear-api-key yourLinearAPIKey \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+
+# report a GitHub issues attestation about all the commits since the previous release,
+# and fail if any of them has no issue reference:
+kosli attest issue \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--tracker github \
+	--github-token yourGithubToken \
+	--github-org yourGithubOrg \
+	--repository yourGithubGitRepository \
+	--since-commit yourPreviousReleaseGitCommit \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+
+# report an Azure Boards work items attestation about a trail:
+kosli attest issue \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--tracker azure-boards \
+	--azure-token yourAzureToken \
+	--azure-org-url yourAzureOrgUrl \
+	--project yourAzureDevOpsProject \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a Jira issues attestation about a trail, getting the reference from the original
+# branch name in a GitHub Pull Request merge job:
+kosli attest issue \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--tracker jira \
+	--secondary-source ${{ github.head_ref }} \
+	--jira-base-url https://kosli.atlassian.net \
+	--jira-username user@domain.com \
+	--jira-api-token yourJiraAPIToken \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestIssueCmd(out io.Writer) *cobra.Command {
+	o := &attestIssueOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "issue [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestIssueShortDesc,
+		Long:    attestIssueLongDesc,
+		Example: attestIssueExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = validateIssueTrackerFlags(cmd, o.tracker)
+			if err != nil {
+				return err
+			}
+
+			err = o.commitRange.validate(cmd)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.tracker, "tracker", "", issueTrackerFlag)
+	cmd.Flags().StringVar(&o.secondarySource, "secondary-source", "", issueSecondarySourceFlag)
+	cmd.Flags().BoolVar(&o.ignoreBranchMatch, "ignore-branch-match", false, ignoreBranchMatchFlag)
+	addCommitRangeFlags(cmd, &o.commitRange)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+	cmd.Flags().StringVar(&o.repository, "repository", DefaultValue(ci, "repository"), repositoryFlag)
+
+	cmd.Flags().StringVar(&o.jiraConfig.BaseURL, "jira-base-url", "", jiraBaseUrlFlag)
+	cmd.Flags().StringVar(&o.jiraConfig.Username, "jira-username", "", jiraUsernameFlag)
+	cmd.Flags().StringVar(&o.jiraConfig.APIToken, "jira-api-token", "", jiraAPITokenFlag)
+	cmd.Flags().StringVar(&o.jiraConfig.PAT, "jira-pat", "", jiraPATFlag)
+	cmd.Flags().StringVar(&o.jiraConfig.IssueFields, "jira-issue-fields", "", jiraIssueFieldFlag)
+
+	cmd.Flags().StringVar(&o.githubConfig.Token, "github-token", "", githubTokenFlag)
+	cmd.Flags().StringVar(&o.githubConfig.Org, "github-org", DefaultValue(ci, "org"), githubOrgFlag)
+	cmd.Flags().StringVar(&o.githubConfig.BaseURL, "github-base-url", "", githubBaseURLFlag)
+
+	cmd.Flags().StringVar(&o.gitlabConfig.Token, "gitlab-token", "", gitlabTokenFlag)
+	cmd.Flags().StringVar(&o.gitlabConfig.Org, "gitlab-org", DefaultValue(ci, "namespace"), gitlabOrgFlag)
+	cmd.Flags().StringVar(&o.gitlabConfig.BaseURL, "gitlab-base-url", "", gitlabBaseURLFlag)
+
+	cmd.Flags().StringVar(&o.linearAPIKey, "linear-api-key", "", linearAPIKeyFlag)
+	cmd.Flags().StringVar(&o.linearBaseURL, "linear-base-url", "", linearBaseURLFlag)
+
+	cmd.Flags().StringVar(&o.azureConfig.Token, "azure-token", "", azureTokenFlag)
+	cmd.Flags().StringVar(&o.azureConfig.OrgUrl, "azure-org-url", DefaultValue(ci, "org-url"), azureOrgUrlFlag)
+	cmd.Flags().StringVar(&o.azureConfig.Project, "project", DefaultValue(ci, "project"), azureProjectFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "commit", "tracker"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+// validateIssueTrackerFlags checks that the tracker is supported and that its flags are set
+func validateIssueTrackerFlags(cmd *cobra.Command, tracker string) error {
+	// a missing --tracker is reported with the other required flags
+	if tracker == "" {
+		return nil
+	}
+	requiredFlags, ok := issueTrackerRequiredFlags[tracker]
+	if !ok {
+		trackers := []string{}
+		for name := range issueTrackerRequiredFlags {
+			trackers = append(trackers, name)
+		}
+		sort.Strings(trackers)
+		return fmt.Errorf("%s is not a valid issue tracker. Allowed trackers are: %s for --tracker", tracker, strings.Join(trackers, ", "))
+	}
+
+	missingFlags := []string{}
+	for _, flagName := range requiredFlags {
+		// some of the flags are defaulted in CI, so their value is checked rather than whether they are set
+		if cmd.Flags().Lookup(flagName).Value.String() == "" {
+			missingFlags = append(missingFlags, flagName)
+		}
+	}
+	if len(missingFlags) > 0 {
+		return fmt.Errorf("flag(s) %s required when --tracker is %s", JoinFlagNames(missingFlags), tracker)
+	}
+
+	if tracker == "jira" {
+		err := MuXRequiredFlags(cmd, []string{"jira-pat", "jira-api-token"}, true)
+		if err != nil {
+			return err
+		}
+		return MuXRequiredFlags(cmd, []string{"jira-pat", "jira-username"}, true)
+	}
+	return nil
+}
+
+// issueTracker returns the issue tracker selected with --tracker
+func (o *attestIssueOptions) issueTracker() types.IssueTracker {
+	switch o.tracker {
+	case "jira":
+		jc := jira.NewJiraConfig(strings.TrimSuffix(o.jiraConfig.BaseURL, "/"), o.jiraConfig.Username,
+			o.jiraConfig.APIToken, o.jiraConfig.PAT)
+		jc.IssueFields = o.jiraConfig.IssueFields
+		return jc
+	case "github":
+		return ghUtils.NewGithubConfig(o.githubConfig.Token, o.githubConfig.BaseURL, o.githubConfig.Org, o.repository)
+	case "gitlab":
+		o.gitlabConfig.Repository = o.repository
+		return &o.gitlabConfig
+	case "linear":
+		lc := linear.NewLinearConfig(o.linearAPIKey, o.linearBaseURL)
+		lc.KosliClient = kosliClient
+		return lc
+	default:
+		ac := azUtils.NewAzureConfig(o.azureConfig.Token, o.azureConfig.OrgUrl, o.azureConfig.Project, o.repository)
+		ac.KosliClient = kosliClient
+		return ac
+	}
+}
+
+func (o *attestIssueOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	trackerName := issueTrackerNames[o.tracker]
+	tracker := o.issueTracker()
+
+	gv, err := newGitView(o.srcRepoRoot)
+	if err != nil {
+		return err
+	}
+	issueReferences, err := findIssueReferences(gv, tracker.ReferencePattern(), o.payload.Commit.Sha1,
+		o.secondarySource, o.ignoreBranchMatch, o.commitRange, trackerName)
+	if err != nil {
+		return err
+	}
+
+	issueResults := IssueResults{Tracker: o.tracker, Issues: []*types.IssueInfo{}, CommitRange: issueReferences.CommitRange}
+	issueLog := ""
+	issueFoundCount := 0
+	pullRequests := []string{}
+	for _, reference := range issueReferences.References {
+		result, err := tracker.GetIssueInfo(reference)
+		if err != nil {
+			return err
+		}
+		if result.IsPullRequest {
+			issueResults.PullRequests = append(issueResults.PullRequests, result)
+			pullRequests = append(pullRequests, reference)
+			continue
+		}
+		issueResults.Issues = append(issueResults.Issues, result)
+		issueExistLog := "issue not found"
+		if result.IssueExists {
+			issueExistLog = "issue found"
+			issueFoundCount++
+		}
+		issueLog += fmt.Sprintf("\n\t%s: %s", result.IssueID, issueExistLog)
+	}
+	issueReferences.excludePullRequests(pullRequests, trackerName)
+	references := issueReferences.References
+	unreferencedErr := issueReferences.unreferencedCommitsError(trackerName)
+	o.payload.Compliant = len(references) > 0 && issueFoundCount == len(references) && unreferencedErr == nil
+	o.payload.UserData = mergeUserData(o.payload.UserData, "issue_results", issueResults)
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("issue attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if len(references) == 0 && o.assert {
+		return fmt.Errorf("no %s references are found in commit message or branch name", trackerName)
+	}
+
+	if issueFoundCount != len(references) && o.assert {
+		return fmt.Errorf("missing %s issues from references found in commit message or branch name%s", trackerName, issueLog)
+	}
+
+	if unreferencedErr != nil && o.assert {
+		return unreferencedErr
+	}
+	return wrapAttestationError(err)
+}
@@ -7,7 +7,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/kosli-dev/cli/internal/gitview"
 	"github.com/kosli-dev/cli/internal/jira"
 	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
@@ -16,6 +15,7 @@ import (
 type JiraAttestationPayload struct {
 	*CommonAttestationPayload
 	JiraResults []*jira.JiraIssueInfo `json:"jira_results"`
+	Compliant   *bool                 `json:"is_compliant,omitempty"`
 }
 
 type attestJiraOptions struct {
@@ -28,6 +28,8 @@ type attestJiraOptions struct {
 	secondarySource   string
 	ignoreBranchMatch bool
 	assert            bool
+	issueRules        jira.IssueRules
+	commitRange       commitRangeOptions
 	payload           JiraAttestationPayload
 }
 
@@ -41,6 +43,10 @@ dash and one or more digits'.
 
 If the ^--ignore-branch-match^ is set, the branch name is not parsed for a match.
 
+With ^--since-commit^ or ^--commit-range^, the messages of all the commits in the range are parsed for
+references, e.g. all the commits in a release. The commits without references, other than merge commits,
+are reported in the attestation user data (under ^jira_commit_references^) and make the attestation non-compliant.
+
 The found issue references will be checked against Jira to confirm their existence.
 The attestation is reported in all cases, and its compliance status depends on referencing
 existing Jira issues.  
@@ -51,6 +57,13 @@ The ^--jira-issue-fields^ can be used to include fields from the jira issue. By
 are included. ^*all^ will give all fields. Using ^--jira-issue-fields "*all" --dry-run^ will give you
 the complete list so you can select the once you need. The issue fields uses the jira API that is documented here:
 https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issues/#api-rest-api-2-issue-issueidorkey-get-request
+
+The ^--require-status^ and ^--jq^ rules can be used to decide the compliance of each referenced issue.
+With ^--require-status^, the issue must be in one of the given statuses (ignoring case). Each ^--jq^ rule is
+a jq expression over the issue fields which must evaluate to true, e.g. ^'.fixVersions | length > 0'^.
+The rules are always evaluated over all the issue fields, and only the ^--jira-issue-fields^ are reported.
+The attestation is then compliant when every referenced issue satisfies all the rules, and the
+rule failures of each issue are reported in the attestation user data (under ^jira_rule_results^).
 ` + attestationBindingDesc + `
 
 ` + commitDescription
@@ -139,6 +152,34 @@ kosli attest jira \
 	--org yourOrgName \
 	--assert
 
+# fail unless the referenced issues are approved for release, have a fix version and are not spikes
+kosli attest jira \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--jira-base-url https://kosli.atlassian.net \
+	--jira-username user@domain.com \
+	--jira-api-token yourJiraAPIToken \
+	--require-status "Approved for release" \
+	--jq '.fixVersions | length > 0' \
+	--jq '.issuetype.name != "Spike"' \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+
+# fail if any commit since the previous release has no issue reference, or an issue is not found in your jira instance
+kosli attest jira \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--jira-base-url https://kosli.atlassian.net \
+	--jira-username user@domain.com \
+	--jira-api-token yourJiraAPIToken \
+	--since-commit yourPreviousReleaseGitCommit \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--assert
+
 # get jira reference from original branch name in a GitHub Pull Request merge job
 kosli attest jira \
 	--name yourAttestationName \
@@ -194,6 +235,11 @@ func newAttestJiraCmd(out io.Writer) *cobra.Command {
 				return err
 			}
 
+			err = o.commitRange.validate(cmd)
+			if err != nil {
+				return err
+			}
+
 			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
 			if err != nil {
 				return fmt.Errorf("%s for --redact-commit-info", err.Error())
@@ -221,6 +267,9 @@ func newAttestJiraCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&o.issueFields, "jira-issue-fields", "", jiraIssueFieldFlag)
 	cmd.Flags().StringVar(&o.secondarySource, "jira-secondary-source", "", jiraSecondarySourceFlag)
 	cmd.Flags().BoolVar(&o.ignoreBranchMatch, "ignore-branch-match", false, ignoreBranchMatchFlag)
+	addCommitRangeFlags(cmd, &o.commitRange)
+	cmd.Flags().StringSliceVar(&o.issueRules.RequiredStatuses, "require-status", []string{}, jiraRequireStatusFlag)
+	cmd.Flags().StringArrayVar(&o.issueRules.JqRules, "jq", []string{}, jiraJqFlag)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name", "commit", "jira-base-url"})
@@ -244,37 +293,62 @@ func (o *attestJiraOptions) run(args []string) error {
 
 	o.payload.JiraResults = []*jira.JiraIssueInfo{}
 
-	gv, err := gitview.New(o.srcRepoRoot)
+	gv, err := newGitView(o.srcRepoRoot)
 	if err != nil {
 		return err
 	}
-	// Jira issue keys consist of [project-key]-[sequential-number]
-	// project key must be at least 2 characters long and start with an uppercase letter
-	// more info: https://support.atlassian.com/jira-software-cloud/docs/what-is-an-issue/#Workingwithissues-Projectandissuekeys
-	jiraIssueKeyPattern := `[A-Z][A-Z0-9]{1,9}-[0-9]+`
-
-	issueIDs, commitInfo, err := gv.MatchPatternInCommitMessageORBranchName(jiraIssueKeyPattern, o.payload.Commit.Sha1,
-		o.secondarySource, o.ignoreBranchMatch)
+	references, err := findIssueReferences(gv, jc.ReferencePattern(), o.payload.Commit.Sha1,
+		o.secondarySource, o.ignoreBranchMatch, o.commitRange, "Jira")
 	if err != nil {
 		return err
 	}
-	logger.Debug("Checked for Jira issue references in Git commit %s on branch %s commit message:\n%s", commitInfo.Sha1, commitInfo.Branch, commitInfo.Message)
-	logger.Debug("the following Jira references are found in commit message or branch name: %v", issueIDs)
+	issueIDs := references.References
 
 	issueLog := ""
 	issueFoundCount := 0
+	ruleResults := []*jira.IssueRuleResult{}
+	ruleFailureLog := ""
 	for _, issueID := range issueIDs {
-		result, err := jc.GetJiraIssueInfo(issueID, o.issueFields)
+		result, err := jc.GetJiraIssueInfo(issueID, o.issueRules.FieldsToFetch(o.issueFields))
 		if err != nil {
 			return err
 		}
-		o.payload.JiraResults = append(o.payload.JiraResults, result)
 		issueExistLog := "issue not found"
 		if result.IssueExists {
 			issueExistLog = "issue found"
 			issueFoundCount++
 		}
 		issueLog += fmt.Sprintf("\n\t%s: %s", result.IssueID, issueExistLog)
+
+		if !o.issueRules.IsEmpty() {
+			ruleResult, err := o.issueRules.Evaluate(result)
+			if err != nil {
+				return err
+			}
+			ruleResults = append(ruleResults, ruleResult)
+			for _, failure := range ruleResult.Failures {
+				logger.Info("Jira issue %s does not meet the rules: %s", ruleResult.IssueID, failure)
+				ruleFailureLog += fmt.Sprintf("\n\t%s: %s", ruleResult.IssueID, failure)
+			}
+			// the fields fetched only to evaluate the rules are not reported
+			result.IssueFields, err = jira.TrimFields(result.IssueFields, o.issueFields)
+			if err != nil {
+				return err
+			}
+		}
+		o.payload.JiraResults = append(o.payload.JiraResults, result)
+	}
+
+	if !o.issueRules.IsEmpty() {
+		o.payload.UserData = mergeUserData(o.payload.UserData, "jira_rule_results", ruleResults)
+	}
+	if references.CommitRange != nil {
+		o.payload.UserData = mergeUserData(o.payload.UserData, "jira_commit_references", references.CommitRange)
+	}
+	unreferencedErr := references.unreferencedCommitsError("Jira")
+	if !o.issueRules.IsEmpty() || references.CommitRange != nil {
+		compliant := len(issueIDs) > 0 && issueFoundCount == len(issueIDs) && ruleFailureLog == "" && unreferencedErr == nil
+		o.payload.Compliant = &compliant
 	}
 
 	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
@@ -305,5 +379,13 @@ func (o *attestJiraOptions) run(args []string) error {
 	if issueFoundCount != len(issueIDs) && o.assert {
 		return fmt.Errorf("missing Jira issues from references found in commit message or branch name%s", issueLog)
 	}
+
+	if ruleFailureLog != "" && o.assert {
+		return fmt.Errorf("Jira issues found in commit message or branch name do not meet the rules%s", ruleFailureLog)
+	}
+
+	if unreferencedErr != nil && o.assert {
+		return unreferencedErr
+	}
 	return wrapAttestationError(err)
 }
@@ -121,6 +121,44 @@ func (suite *AttestJiraCommandTestSuite) TestAttestJiraCmd() {
 				commitMessage: "SAMI-1 test commit",
 			},
 		},
+		{
+			wantError: true,
+			name:      "assert for a Jira issue not in a required status gives an error",
+			cmd: fmt.Sprintf(`attest jira --name jira-validation
+					--jira-base-url https://kosli-test.atlassian.net  --jira-username tore@kosli.com
+					--require-status "Approved for release"
+					--repo-root %s
+					--assert %s`, suite.tmpDir, suite.defaultKosliArguments),
+			goldenRegex: "(?s).*Error: Jira issues found in commit message or branch name do not meet the rules\n\tEX-1: status '.*' is not one of: Approved for release\n",
+			additionalConfig: jiraTestsAdditionalConfig{
+				commitMessage: "EX-1 test commit",
+			},
+		},
+		{
+			wantError: true,
+			name:      "assert for a Jira issue failing a jq rule gives an error",
+			cmd: fmt.Sprintf(`attest jira --name jira-validation
+					--jira-base-url https://kosli-test.atlassian.net  --jira-username tore@kosli.com
+					--jq '.issuetype.name == "Spike"'
+					--repo-root %s
+					--assert %s`, suite.tmpDir, suite.defaultKosliArguments),
+			goldenRegex: "(?s).*Error: Jira issues found in commit message or branch name do not meet the rules\n\tEX-1: rule '.issuetype.name == \"Spike\"' is not true: false\n",
+			additionalConfig: jiraTestsAdditionalConfig{
+				commitMessage: "EX-1 test commit",
+			},
+		},
+		{
+			wantError: true,
+			name:      "an invalid jq rule gives an error",
+			cmd: fmt.Sprintf(`attest jira --name jira-validation
+					--jira-base-url https://kosli-test.atlassian.net  --jira-username tore@kosli.com
+					--jq '.fixVersions |'
+					--repo-root %s %s`, suite.tmpDir, suite.defaultKosliArguments),
+			goldenRegex: "Error: invalid jq rule '.fixVersions \\|'.*",
+			additionalConfig: jiraTestsAdditionalConfig{
+				commitMessage: "EX-1 test commit",
+			},
+		},
 		{
 			name: "can attest jira against an artifact using artifact name and --artifact-type",
 			cmd: fmt.Sprintf(`attest jira testdata/file1 --artifact-type file --name foo 
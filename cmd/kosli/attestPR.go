@@ -22,6 +22,7 @@ func newAttestPRCmd(out io.Writer) *cobra.Command {
 		newAttestGithubPRCmd(out),
 		newAttestBitbucketPRCmd(out),
 		newAttestAzurePRCmd(out),
+		newAttestGiteaPRCmd(out),
 	)
 
 	return cmd
@@ -137,6 +137,11 @@ func newAttestAzurePRCmd(out io.Writer) *cobra.Command {
 				return fmt.Errorf("%s for --redact-commit-info", err.Error())
 			}
 
+			err = o.validateIndependentApprovalFlags()
+			if err != nil {
+				return err
+			}
+
 			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
 			if err != nil {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
@@ -156,6 +161,7 @@ func newAttestAzurePRCmd(out io.Writer) *cobra.Command {
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	addAzureFlags(cmd, azureFlagsValues, ci)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
+	addIndependentApprovalFlags(cmd, o)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name",
 		"azure-token", "azure-org-url",
@@ -13,6 +13,8 @@ const attestPRBitbucketShortDesc = `Report a Bitbucket pull request attestation
 const attestPRBitbucketLongDesc = attestPRBitbucketShortDesc + `
 It checks if a pull request exists for a given merge commit and reports the pull-request attestation to Kosli.
 Authentication to Bitbucket can be done with access token (recommended) or app passwords. Credentials need to have read access for both repos and pull requests.
+For Bitbucket Data Center, set ^--bitbucket-base-url^ to your instance URL, ^--bitbucket-workspace^ to the project key,
+and authenticate with an HTTP access token (recommended) or a username and password.
 ` + attestationBindingDesc
 
 const attestPRBitbucketExample = `
@@ -79,6 +81,19 @@ kosli attest pullrequest bitbucket \
 	--api-token yourAPIToken \
 	--org yourOrgName
 
+# report a Bitbucket Data Center pull request attestation about a trail:
+kosli attest pullrequest bitbucket \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--bitbucket-base-url https://bitbucket.example.com \
+	--bitbucket-access-token yourBitbucketHTTPAccessToken \
+	--bitbucket-workspace yourBitbucketProjectKey \
+	--commit yourArtifactGitCommit \
+	--repository yourBitbucketGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
 # fail if a pull request does not exist for your artifact
 kosli attest pullrequest bitbucket \
 	--name yourTemplateArtifactName.yourAttestationName \
@@ -157,6 +172,11 @@ func newAttestBitbucketPRCmd(out io.Writer) *cobra.Command {
 				return fmt.Errorf("%s for --redact-commit-info", err.Error())
 			}
 
+			err = o.validateIndependentApprovalFlags()
+			if err != nil {
+				return err
+			}
+
 			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
 			if err != nil {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
@@ -174,6 +194,7 @@ func newAttestBitbucketPRCmd(out io.Writer) *cobra.Command {
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	addBitbucketFlags(cmd, o.getRetriever().(*bbUtils.Config), ci)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
+	addIndependentApprovalFlags(cmd, o)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name",
 		"bitbucket-workspace", "commit", "repository"})
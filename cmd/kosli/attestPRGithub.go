@@ -90,6 +90,21 @@ kosli attest pullrequest github \
 	--api-token yourAPIToken \
 	--org yourOrgName \
 	--assert
+
+# fail unless the pull request has two approvals given after its last commit by reviewers who are not commit authors
+kosli attest pullrequest github \
+	--name yourTemplateArtifactName.yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--github-token yourGithubToken \
+	--github-org yourGithubOrg \
+	--commit yourArtifactGitCommit \
+	--repository yourGithubGitRepository \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+	--require-independent-approval \
+	--independent-approvers 2 \
+	--assert
 `
 
 func newAttestGithubPRCmd(out io.Writer) *cobra.Command {
@@ -127,6 +142,11 @@ func newAttestGithubPRCmd(out io.Writer) *cobra.Command {
 				return fmt.Errorf("%s for --redact-commit-info", err.Error())
 			}
 
+			err = o.validateIndependentApprovalFlags()
+			if err != nil {
+				return err
+			}
+
 			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
 			if err != nil {
 				return err
@@ -151,6 +171,7 @@ func newAttestGithubPRCmd(out io.Writer) *cobra.Command {
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	addGithubFlags(cmd, githubFlagsValues, ci)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
+	addIndependentApprovalFlags(cmd, o)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name",
 		"github-token", "github-org", "commit", "repository"})
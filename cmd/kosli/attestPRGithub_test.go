@@ -69,6 +69,13 @@ func (suite *AttestGithubPRCommandTestSuite) TestAttestGithubPRCmd() {
 			    --github-org kosli-dev --repository cli  --flow %s --trail %s --repo-root ../.. --host %s --org %s --api-token %s`, suite.flowName, suite.trailName, global.Host, global.Org, global.ApiToken),
 			golden: "Error: flag '--commit' is required, but empty string was provided\n",
 		},
+		{
+			wantError: true,
+			name:      "fails when --independent-approvers is less than 1",
+			cmd: fmt.Sprintf(`attest pullrequest github --name foo --require-independent-approval --independent-approvers 0
+				--github-org kosli-dev --repository cli  %s`, suite.defaultKosliArguments),
+			golden: "Error: --independent-approvers must be at least 1\n",
+		},
 		{
 			wantError: true,
 			name:      "attesting against an artifact that does not exist fails",
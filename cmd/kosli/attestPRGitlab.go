@@ -132,6 +132,11 @@ func newAttestGitlabPRCmd(out io.Writer) *cobra.Command {
 				return fmt.Errorf("%s for --redact-commit-info", err.Error())
 			}
 
+			err = o.validateIndependentApprovalFlags()
+			if err != nil {
+				return err
+			}
+
 			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
 			if err != nil {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
@@ -149,6 +154,7 @@ func newAttestGitlabPRCmd(out io.Writer) *cobra.Command {
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	addGitlabFlags(cmd, o.getRetriever().(*gitlabUtils.GitlabConfig), ci)
 	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
+	addIndependentApprovalFlags(cmd, o)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name",
 		"gitlab-token", "gitlab-org", "commit", "repository"})
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/provenance"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+type attestProvenanceOptions struct {
+	*CommonAttestationOptions
+	provenanceFilePath string
+	policy             provenance.Policy
+	assert             bool
+	payload            GenericAttestationPayload
+}
+
+const attestProvenanceShortDesc = `Report a provenance attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestProvenanceLongDesc = attestProvenanceShortDesc + `
+The ^--provenance^ file can be an in-toto (v0.1 or v1) statement, a DSSE envelope containing one, or a sigstore
+bundle containing one. Files with one such document per line (e.g. ^.intoto.jsonl^) are also supported.
+The builder ID, build type, source repo and source commit are extracted from SLSA provenance (v0.1, v0.2 and v1)
+predicates, and the provenance file is uploaded to Kosli's evidence vault. Signatures are not verified by this command.
+
+When the attestation is for an artifact, one of the statement subjects must have a sha256 digest equal to the
+artifact fingerprint, otherwise the attestation is non-compliant.
+The ^--expected-builder^ and ^--expected-source-repo^ flags add policy checks on the provenance.
+
+The compliance is decided locally and the attestation is reported as a generic attestation, with
+the provenance summary in its user data (under ^provenance_results^).
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestProvenanceExample = `
+# report a provenance attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest provenance yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--provenance yourProvenanceFile \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a provenance attestation about a file artifact, checking where and how it was built,
+# and fail if it is non-compliant:
+kosli attest provenance yourFileArtifact \
+	--artifact-type file \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--provenance yourArtifact.intoto.jsonl \
+	--expected-builder https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml \
+	--expected-source-repo https://github.com/YourOrg/YourRepo \
+	--assert \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestProvenanceCmd(out io.Writer) *cobra.Command {
+	o := &attestProvenanceOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "provenance [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestProvenanceShortDesc,
+		Long:    attestProvenanceLongDesc,
+		Example: attestProvenanceExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.provenanceFilePath, "provenance", "", provenanceFileFlag)
+	cmd.Flags().StringVar(&o.policy.ExpectedBuilder, "expected-builder", "", expectedBuilderFlag)
+	cmd.Flags().StringVar(&o.policy.ExpectedSourceRepo, "expected-source-repo", "", expectedSourceRepoFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "provenance"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestProvenanceOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	provenanceResults, err := provenance.ProcessProvenanceFile(o.provenanceFilePath, o.payload.ArtifactFingerprint)
+	if err != nil {
+		return err
+	}
+	subjectOK := provenanceResults.SubjectCheck != provenance.SubjectMismatch
+	policyOK := provenanceResults.CheckPolicy(o.policy)
+	o.payload.Compliant = subjectOK && policyOK
+	o.payload.UserData = mergeUserData(o.payload.UserData, "provenance_results", provenanceResults)
+	o.attachments = append(o.attachments, o.provenanceFilePath)
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("provenance attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if err == nil && !o.payload.Compliant && o.assert {
+		reasons := []string{}
+		if !subjectOK {
+			reasons = append(reasons, fmt.Sprintf("no provenance subject has the artifact fingerprint %s", o.payload.ArtifactFingerprint))
+		}
+		reasons = append(reasons, provenanceResults.PolicyViolations...)
+		return fmt.Errorf("%s", strings.Join(reasons, "\n"))
+	}
+	return wrapAttestationError(err)
+}
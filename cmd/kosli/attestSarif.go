@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/baseline"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/sarif"
+	"github.com/spf13/cobra"
+)
+
+type attestSarifOptions struct {
+	*CommonAttestationOptions
+	sarifFilePaths    []string
+	uploadResultsFile bool
+	baselineFilePath  string
+	failOn            string
+	assert            bool
+	payload           GenericAttestationPayload
+}
+
+const attestSarifShortDesc = `Report a SARIF attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestSarifLongDesc = attestSarifShortDesc + `
+Any scanner producing SARIF 2.1.0 output is accepted. A file can contain several runs from
+different tools, and ^--scan-results^ can be repeated to combine several files.
+
+Each finding is given a severity of critical, high, medium, low or info. A numeric ^security-severity^
+property on the result or its rule takes precedence and is mapped using the CVSS bands
+(>= 9.0 critical, >= 7.0 high, >= 4.0 medium, >= 0.1 low, otherwise info).
+Otherwise, the result ^level^ (or the rule's default level) is mapped:
+error is high, warning is medium, note is low and none is info.
+
+The attestation is compliant when no finding has a severity at or above ^--fail-on^ (high by default).
+The compliance is decided locally and the attestation is reported as a generic attestation,
+with a summary of the findings per run in its user data (under ^sarif_results^).
+
+By default, the ^--scan-results^ files are also uploaded to Kosli's evidence vault.
+You can disable that by setting ^--upload-results=false^` + baselineDesc + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestSarifExample = `
+# report a SARIF attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest sarif yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--scan-results yourSARIFScanResults \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a SARIF attestation about a pre-built docker artifact (you provide the fingerprint):
+kosli attest sarif \
+	--fingerprint yourDockerImageFingerprint \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--scan-results yourSARIFScanResults \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a SARIF attestation about a trail combining the results of two scanners:
+kosli attest sarif \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--scan-results codeql.sarif \
+	--scan-results trivy.sarif \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a SARIF attestation which is only non-compliant for critical findings,
+# and fail if it is non-compliant:
+kosli attest sarif \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--scan-results yourSARIFScanResults \
+	--fail-on critical \
+	--assert \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a SARIF attestation about a trail with accepted risks:
+kosli attest sarif \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--scan-results yourSARIFScanResults \
+	--baseline yourBaselineFile.yml \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a SARIF attestation about a trail without uploading the SARIF results file:
+kosli attest sarif \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--scan-results yourSARIFScanResults \
+	--upload-results=false \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestSarifCmd(out io.Writer) *cobra.Command {
+	o := &attestSarifOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "sarif [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestSarifShortDesc,
+		Long:    attestSarifLongDesc,
+		Example: attestSarifExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			_, err = sarif.ParseThreshold(o.failOn)
+			if err != nil {
+				return fmt.Errorf("%s for --fail-on", err.Error())
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringSliceVarP(&o.sarifFilePaths, "scan-results", "R", []string{}, sarifResultsFileFlag)
+	cmd.Flags().StringVar(&o.baselineFilePath, "baseline", "", baselineFileFlag)
+	cmd.Flags().BoolVar(&o.uploadResultsFile, "upload-results", true, uploadSarifResultsFlag)
+	cmd.Flags().StringVar(&o.failOn, "fail-on", string(sarif.SeverityHigh), sarifFailOnFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "scan-results"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestSarifOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	threshold, err := sarif.ParseThreshold(o.failOn)
+	if err != nil {
+		return err
+	}
+
+	sarifResults, err := sarif.ProcessSarifFiles(o.sarifFilePaths)
+	if err != nil {
+		return err
+	}
+	if o.baselineFilePath != "" {
+		b, err := baseline.Load(o.baselineFilePath)
+		if err != nil {
+			return err
+		}
+		sarifResults.ApplyBaseline(b, time.Now())
+		o.attachments = append(o.attachments, o.baselineFilePath)
+	}
+	o.payload.Compliant = sarifResults.Evaluate(threshold)
+	o.payload.UserData = mergeUserData(o.payload.UserData, "sarif_results", sarifResults)
+
+	if o.uploadResultsFile {
+		o.attachments = append(o.attachments, o.sarifFilePaths...)
+	}
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("sarif attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if err == nil && !o.payload.Compliant && o.assert {
+		if sarifResults.FailingCount == 0 {
+			return fmt.Errorf("%d finding(s) with an expired acceptance", len(sarifResults.ExpiredAcceptances))
+		}
+		return fmt.Errorf("%d finding(s) with severity %s or above", sarifResults.FailingCount, threshold)
+	}
+	return wrapAttestationError(err)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/sbom"
+	"github.com/spf13/cobra"
+)
+
+type attestSbomOptions struct {
+	*CommonAttestationOptions
+	sbomFilePath    string
+	allowedLicenses []string
+	deniedLicenses  []string
+	assert          bool
+	payload         GenericAttestationPayload
+}
+
+const attestSbomShortDesc = `Report an SBOM attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestSbomLongDesc = attestSbomShortDesc + `
+The ^--sbom^ file can be a CycloneDX JSON, an SPDX JSON or an SPDX tag-value document. The format is detected
+from the file content. The component count, the components per ecosystem (package URL type) and the components
+per license are reported to Kosli, and the SBOM file is uploaded to Kosli's evidence vault.
+
+When the attestation is for an artifact, the digests of the SBOM subject (the CycloneDX metadata component, or the package
+the SPDX document describes) are checked against the artifact fingerprint. An SBOM without a subject digest matching the fingerprint makes the
+attestation non-compliant, with ^digest_check: mismatch^, or ^digest_check: not-found^ when the subject has no digest.
+
+The ^--allowed-licenses^ and ^--denied-licenses^ flags define a license policy. A component is compliant when its
+license (expression) can be satisfied without a denied license, and, if allowed licenses are given, with allowed licenses only.
+
+The compliance is decided locally and the attestation is reported as a generic attestation, with
+the SBOM summary in its user data (under ^sbom_results^).
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestSbomExample = `
+# report an SBOM attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest sbom yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--sbom yourSBOMFile \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report an SBOM attestation about a pre-built docker artifact (you provide the fingerprint):
+kosli attest sbom \
+	--fingerprint yourDockerImageFingerprint \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--sbom yourSBOMFile \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report an SBOM attestation about a trail with a license policy,
+# and fail if it is non-compliant:
+kosli attest sbom \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--sbom yourSBOMFile \
+	--denied-licenses GPL-3.0-only,AGPL-3.0-only \
+	--assert \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestSbomCmd(out io.Writer) *cobra.Command {
+	o := &attestSbomOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "sbom [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestSbomShortDesc,
+		Long:    attestSbomLongDesc,
+		Example: attestSbomExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.sbomFilePath, "sbom", "", sbomFileFlag)
+	cmd.Flags().StringSliceVar(&o.allowedLicenses, "allowed-licenses", []string{}, allowedLicensesFlag)
+	cmd.Flags().StringSliceVar(&o.deniedLicenses, "denied-licenses", []string{}, deniedLicensesFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "sbom"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestSbomOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	sbomResults, err := sbom.ProcessSbomFile(o.sbomFilePath)
+	if err != nil {
+		return err
+	}
+
+	digestOK := sbomResults.CheckDigest(o.payload.ArtifactFingerprint)
+	licensesOK := sbomResults.CheckLicenses(sbom.LicensePolicy{
+		Allowed: o.allowedLicenses,
+		Denied:  o.deniedLicenses,
+	})
+	o.payload.Compliant = digestOK && licensesOK
+	o.payload.UserData = mergeUserData(o.payload.UserData, "sbom_results", sbomResults)
+	o.attachments = append(o.attachments, o.sbomFilePath)
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("sbom attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if err == nil && !o.payload.Compliant && o.assert {
+		reasons := []string{}
+		if sbomResults.DigestCheck == sbom.DigestNotFound {
+			reasons = append(reasons, fmt.Sprintf("the SBOM subject has no sha256 digest to check against the artifact fingerprint %s", o.payload.ArtifactFingerprint))
+		} else if !digestOK {
+			reasons = append(reasons, fmt.Sprintf("the SBOM subject digest does not match the artifact fingerprint %s", o.payload.ArtifactFingerprint))
+		}
+		if !licensesOK {
+			reasons = append(reasons, fmt.Sprintf("%d component(s) with a non-compliant license", len(sbomResults.LicenseViolations)))
+		}
+		return fmt.Errorf("%s", strings.Join(reasons, " and "))
+	}
+	return wrapAttestationError(err)
+}
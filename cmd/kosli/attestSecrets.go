@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+type attestSecretsOptions struct {
+	*CommonAttestationOptions
+	reportFilePath    string
+	format            string
+	allowlistFilePath string
+	uploadReport      bool
+	assert            bool
+	payload           GenericAttestationPayload
+}
+
+const attestSecretsShortDesc = `Report a secrets scan attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestSecretsLongDesc = attestSecretsShortDesc + `
+The ^--report^ file is parsed according to ^--format^, which is one of:
+- ^gitleaks^: the JSON report of ^gitleaks detect --report-format json^ (or ^gitleaks git/dir^).
+- ^trufflehog^: the output of ^trufflehog --json^ (one finding per line).
+
+The findings are counted by rule (or detector) and by file. Each finding has a fingerprint of the form
+^[commit:]file:rule:line^, the same as gitleaks fingerprints. Findings whose fingerprint is in the
+^--allowlist^ file (one fingerprint per line, e.g. a ^.gitleaksignore^ file) are reported separately
+and do not count. The attestation is compliant when no finding remains.
+
+Secret values never leave the machine: the attestation only contains the rules, files, lines, commits
+and fingerprints of the findings. By default, a redacted copy of the report, where the secret values are
+replaced with ^REDACTED^ everywhere they appear, is uploaded to Kosli's evidence vault.
+You can disable that by setting ^--upload-report=false^. The original report must not be passed to ^--attachments^.
+
+The compliance is decided locally and the attestation is reported as a generic attestation,
+with the findings summary in its user data (under ^secrets_results^).
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestSecretsExample = `
+# report a secrets scan attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+gitleaks git --report-format json --report-path gitleaks.json
+kosli attest secrets yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--report gitleaks.json \
+	--format gitleaks \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a secrets scan attestation about a trail, ignoring allowlisted findings,
+# and fail if any finding remains:
+trufflehog git file://. --json > trufflehog.jsonl
+kosli attest secrets \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--report trufflehog.jsonl \
+	--format trufflehog \
+	--allowlist .secrets-allowlist \
+	--assert \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestSecretsCmd(out io.Writer) *cobra.Command {
+	o := &attestSecretsOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "secrets [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestSecretsShortDesc,
+		Long:    attestSecretsLongDesc,
+		Example: attestSecretsExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = secrets.ValidateFormat(o.format)
+			if err != nil {
+				return fmt.Errorf("%s for --format", err.Error())
+			}
+
+			err = validateReportNotAttached(o.reportFilePath, o.attachments)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.reportFilePath, "report", "", secretsReportFlag)
+	cmd.Flags().StringVar(&o.format, "format", "", secretsFormatFlag)
+	cmd.Flags().StringVar(&o.allowlistFilePath, "allowlist", "", secretsAllowlistFlag)
+	cmd.Flags().BoolVar(&o.uploadReport, "upload-report", true, uploadSecretsReportFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "report", "format"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestSecretsOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	allowlist := []string{}
+	if o.allowlistFilePath != "" {
+		allowlist, err = secrets.LoadAllowlist(o.allowlistFilePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	secretsResults, redactedReport, err := secrets.ProcessReport(o.reportFilePath, o.format, allowlist)
+	if err != nil {
+		return err
+	}
+	o.payload.Compliant = secretsResults.Compliant()
+	o.payload.UserData = mergeUserData(o.payload.UserData, "secrets_results", secretsResults)
+
+	if o.uploadReport {
+		// only the redacted copy of the report is uploaded
+		redactedDir, err := os.MkdirTemp("", "kosli-secrets-report")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(redactedDir)
+		redactedPath := filepath.Join(redactedDir, filepath.Base(o.reportFilePath))
+		err = os.WriteFile(redactedPath, redactedReport, 0600)
+		if err != nil {
+			return err
+		}
+		o.attachments = append(o.attachments, redactedPath)
+		if o.allowlistFilePath != "" {
+			o.attachments = append(o.attachments, o.allowlistFilePath)
+		}
+	}
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("secrets attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if err == nil && !o.payload.Compliant && o.assert {
+		rules := []string{}
+		for rule, count := range secretsResults.ByRule {
+			rules = append(rules, fmt.Sprintf("%s: %d", rule, count))
+		}
+		sort.Strings(rules)
+		return fmt.Errorf("%d secret(s) found (%s)", secretsResults.Remaining, strings.Join(rules, ", "))
+	}
+	return wrapAttestationError(err)
+}
+
+// validateReportNotAttached makes sure the unredacted report is not uploaded
+// with the --attachments, as a file or inside an attached directory
+func validateReportNotAttached(report string, attachments []string) error {
+	reportPath, err := filepath.Abs(report)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		attachmentPath, err := filepath.Abs(attachment)
+		if err != nil {
+			return err
+		}
+		if reportPath == attachmentPath || strings.HasPrefix(reportPath, attachmentPath+string(filepath.Separator)) {
+			return fmt.Errorf("the --report file must not be uploaded unredacted with --attachments %s", attachment)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/signature"
+	"github.com/spf13/cobra"
+)
+
+type attestSignatureOptions struct {
+	*CommonAttestationOptions
+	signatureFilePath string
+	publicKeyPath     string
+	trustBundlePath   string
+	assert            bool
+	payload           GenericAttestationPayload
+}
+
+const attestSignatureShortDesc = `Report a signature attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestSignatureLongDesc = attestSignatureShortDesc + `
+Verifies the ^--signature^ file offline, using a local public key (^--public-key^) or a local
+trust bundle of public keys and certificates (^--trust-bundle^). ECDSA (P-256) and ed25519 keys are supported.
+The certificates of a trust bundle must be valid at the time of the attestation, and either be self-signed or
+chain to a self-signed certificate of the trust bundle.
+
+The ^--signature^ file can be:
+- a detached signature (base64 or binary) over the artifact fingerprint, i.e. over the 32 bytes of the SHA256 digest.
+  ECDSA signatures are verified against the digest directly (as done by e.g. ^cosign sign-blob^ with a pre-computed digest).
+- a DSSE envelope. Its signatures are verified over the DSSE pre-authentication encoding of the payload.
+  When the attestation is for an artifact, the signed payload must also refer to the artifact fingerprint
+  (as an in-toto statement subject, or as the image manifest digest of a cosign simple signing payload),
+  otherwise the attestation is non-compliant.
+
+The key ID (the SHA256 of the DER encoded public key), the algorithm and the verification result are recorded.
+The compliance is decided locally and the attestation is reported as a generic attestation, with
+the verification result in its user data (under ^signature_results^). The signature file is uploaded to Kosli's evidence vault.
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestSignatureExample = `
+# report a signature attestation about a file artifact, verifying a detached signature with a public key:
+kosli attest signature yourFileArtifact \
+	--artifact-type file \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--signature yourArtifact.sig \
+	--public-key cosign.pub \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a signature attestation about a pre-built docker artifact (you provide the fingerprint),
+# verifying a DSSE envelope with a trust bundle, and fail if it is non-compliant:
+kosli attest signature \
+	--fingerprint yourDockerImageFingerprint \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--signature yourArtifact.dsse.json \
+	--trust-bundle trusted-keys.pem \
+	--assert \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestSignatureCmd(out io.Writer) *cobra.Command {
+	o := &attestSignatureOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "signature [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestSignatureShortDesc,
+		Long:    attestSignatureLongDesc,
+		Example: attestSignatureExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"public-key", "trust-bundle"}, true)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.signatureFilePath, "signature", "", signatureFileFlag)
+	cmd.Flags().StringVar(&o.publicKeyPath, "public-key", "", signaturePublicKeyFlag)
+	cmd.Flags().StringVar(&o.trustBundlePath, "trust-bundle", "", signatureTrustBundleFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "signature"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestSignatureOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	keysPath := o.publicKeyPath
+	if keysPath == "" {
+		keysPath = o.trustBundlePath
+	}
+	keys, err := signature.LoadPublicKeys(keysPath, time.Now())
+	if err != nil {
+		return err
+	}
+
+	signatureResults, err := signature.VerifyFile(o.signatureFilePath, o.payload.ArtifactFingerprint, keys)
+	if err != nil {
+		return err
+	}
+	o.payload.Compliant = signatureResults.Compliant()
+	o.payload.UserData = mergeUserData(o.payload.UserData, "signature_results", signatureResults)
+	o.attachments = append(o.attachments, o.signatureFilePath)
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("signature attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if err == nil && !o.payload.Compliant && o.assert {
+		return fmt.Errorf("signature verification failed: %s", signatureResults.Reason)
+	}
+	return wrapAttestationError(err)
+}
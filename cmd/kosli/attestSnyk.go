@@ -5,7 +5,9 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"time"
 
+	"github.com/kosli-dev/cli/internal/baseline"
 	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/kosli-dev/cli/internal/snyk"
 	"github.com/spf13/cobra"
@@ -20,6 +22,7 @@ type attestSnykOptions struct {
 	*CommonAttestationOptions
 	snykSarifFilePath string
 	uploadResultsFile bool
+	baselineFilePath  string
 	payload           SnykAttestationPayload
 }
 
@@ -32,8 +35,7 @@ Snyk output can be for "snyk code test", "snyk container test", or "snyk iac tes
 The ^--scan-results^ .json file is analyzed and a summary of the scan results are reported to Kosli.
 
 By default, the ^--scan-results^ .json file is also uploaded to Kosli's evidence vault.
-You can disable that by setting ^--upload-results=false^
-` + attestationBindingDesc + `
+You can disable that by setting ^--upload-results=false^` + baselineDesc + attestationBindingDesc + `
 
 ` + commitDescription
 
@@ -87,6 +89,16 @@ kosli attest snyk \
 	--api-token yourAPIToken \
 	--org yourOrgName
 
+# report a snyk attestation about a trail with accepted risks:
+kosli attest snyk \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--scan-results yourSnykSARIFScanResults \
+	--baseline yourBaselineFile.yml \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
 # report a snyk attestation about a trail without uploading the snyk results file:
 kosli attest snyk \
 	--name yourAttestationName \
@@ -151,6 +163,7 @@ func newAttestSnykCmd(out io.Writer) *cobra.Command {
 	ci := WhichCI()
 	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
 	cmd.Flags().StringVarP(&o.snykSarifFilePath, "scan-results", "R", "", snykSarifResultsFileFlag)
+	cmd.Flags().StringVar(&o.baselineFilePath, "baseline", "", baselineFileFlag)
 	cmd.Flags().BoolVar(&o.uploadResultsFile, "upload-results", true, uploadSnykResultsFlag)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name", "scan-results"})
@@ -174,6 +187,15 @@ func (o *attestSnykOptions) run(args []string) error {
 		return fmt.Errorf("failed to parse Snyk sarif results file [%s]: %s", o.snykSarifFilePath, err)
 	}
 
+	if o.baselineFilePath != "" {
+		b, err := baseline.Load(o.baselineFilePath)
+		if err != nil {
+			return err
+		}
+		o.payload.SnykResults.ApplyBaseline(b, time.Now())
+		o.attachments = append(o.attachments, o.baselineFilePath)
+	}
+
 	if o.uploadResultsFile {
 		o.attachments = append(o.attachments, o.snykSarifFilePath)
 	}
@@ -101,6 +101,11 @@ func (suite *AttestSnykCommandTestSuite) TestAttestSnykCmd() {
 			cmd:    fmt.Sprintf("attest snyk --name cli.foo --commit HEAD --origin-url https://example.com --scan-results testdata/snyk_sarif.json %s", suite.defaultKosliArguments),
 			golden: "snyk attestation 'foo' is reported to trail: test-123\n",
 		},
+		{
+			name:   "can attest snyk against a trail with a baseline file",
+			cmd:    fmt.Sprintf("attest snyk --name bar --commit HEAD --origin-url https://example.com --scan-results testdata/snyk_sarif.json --baseline ../../internal/sarif/testdata/baseline.yml %s", suite.defaultKosliArguments),
+			golden: "snyk attestation 'bar' is reported to trail: test-123\n",
+		},
 		{
 			name: "can attest snyk against with external-url and external-fingerprint a trail",
 			cmd: fmt.Sprintf(`attest snyk --name bar --commit HEAD --origin-url https://example.com
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/kosli-dev/cli/internal/sonar"
@@ -14,17 +15,24 @@ import (
 type SonarAttestationPayload struct {
 	*CommonAttestationPayload
 	SonarResults *sonar.SonarResults `json:"sonar_results"`
+	Compliant    *bool               `json:"is_compliant,omitempty"`
 }
 
 type attestSonarOptions struct {
 	*CommonAttestationOptions
-	apiToken   string
-	workingDir string
-	ceTaskURL  string
-	projectKey string
-	serverURL  string
-	revision   string
-	payload    SonarAttestationPayload
+	apiToken         string
+	workingDir       string
+	ceTaskURL        string
+	projectKey       string
+	serverURL        string
+	revision         string
+	branch           string
+	pullRequest      string
+	wait             bool
+	timeout          time.Duration
+	metricThresholds []string
+	thresholds       []sonar.MetricThreshold
+	payload          SonarAttestationPayload
 }
 
 const attestSonarShortDesc = `Report a SonarQube attestation to an artifact or a trail in a Kosli flow.  `
@@ -40,9 +48,20 @@ you can provide the correct path using the --sonar-working-dir flag. This metada
 2. Providing the Sonar project key and the revision of the scan (plus the SonarQube server URL if relevant). If running the Kosli CLI in some CI/CD pipeline, the revision
 is defaulted to the commit SHA. If you are running the command locally, or have overriden the revision in SonarQube via parameters to the Sonar scanner, you can
 provide the correct revision using the --sonar-revision flag. Kosli then finds the scan results for the specified project key and revision.
-
-Note that if your project is very large and you are using SonarQube Cloud's automatic analysis, it is possible for the attest sonar command to run before the SonarQube Cloud scan is completed.
-In this case, we recommend using Kosli's Sonar webhook integration ( https://docs.kosli.com/integrations/sonar/ ) rather than the CLI to attest the scan results.
+The scan results of a branch other than the main branch, or of a pull request, are found using ^--sonar-branch^ or ^--sonar-pull-request^.
+With Sonar's metadata, the branch or pull request is the one of the scan.
+
+By default, the compliance of the attestation only depends on the project's quality gate. You can also require the scan results to meet
+your own thresholds on SonarQube metrics with ^--sonar-metric-threshold^, e.g. ^new_coverage>=80^ or ^new_vulnerabilities==0^.
+The thresholds are evaluated against the measures of the attested analysis, and the attestation is only compliant
+when the quality gate has not failed and all thresholds are met. The value of each metric and whether its threshold is met are reported
+in the attestation user data (under ^sonar_metric_thresholds^).
+
+Note that SonarQube processes the scan results in a background task after the Sonar scanner is done, so it is possible for the attest sonar
+command to run before the analysis is completed, e.g. if your project is very large or you are using SonarQube Cloud's automatic analysis.
+In this case, you can use ^--wait^ to wait for the analysis to complete, for at most ^--timeout^. SonarQube is polled with increasing intervals
+until the analysis task is complete, and the command fails if the task fails or is canceled. Alternatively, you can use Kosli's Sonar webhook
+integration ( https://docs.kosli.com/integrations/sonar/ ) rather than the CLI to attest the scan results.
 ` + attestationBindingDesc
 
 const attestSonarExample = `
@@ -66,6 +85,18 @@ kosli attest sonar \
 	--api-token yourAPIToken \
 	--org yourOrgName \
 
+# report a SonarQube Server attestation about a trail using Sonar's metadata, waiting up to 10 minutes for the analysis to complete:
+kosli attest sonar \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--sonar-api-token yourSonarAPIToken \
+	--sonar-working-dir yourSonarWorkingDirPath \
+	--wait \
+	--timeout 10m \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+
 # report a SonarQube Cloud attestation for a specific branch about a trail using key/revision:
 kosli attest sonar \
 	--name yourAttestationName \
@@ -74,7 +105,7 @@ kosli attest sonar \
 	--sonar-api-token yourSonarAPIToken \
 	--sonar-project-key yourSonarProjectKey \
 	--sonar-revision yourSonarRevision \
-	--branch-name yourBranchName \
+	--sonar-branch yourSonarBranchName \
 	--api-token yourAPIToken \
 	--org yourOrgName \
 
@@ -87,7 +118,21 @@ kosli attest sonar \
 	--sonarqube-url yourSonarQubeURL \
 	--sonar-project-key yourSonarProjectKey \
 	--sonar-revision yourSonarRevision \
-	--pull-request-id yourPullRequestID \
+	--sonar-pull-request yourSonarPullRequestKey \
+	--api-token yourAPIToken \
+	--org yourOrgName \
+
+# report a SonarQube Cloud attestation for a pull-request about a trail using key/revision, with thresholds on the coverage and vulnerabilities of new code:
+kosli attest sonar \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--sonar-api-token yourSonarAPIToken \
+	--sonar-project-key yourSonarProjectKey \
+	--sonar-revision yourSonarRevision \
+	--sonar-pull-request yourSonarPullRequestKey \
+	--sonar-metric-threshold "new_coverage>=80" \
+	--sonar-metric-threshold "new_vulnerabilities==0" \
 	--api-token yourAPIToken \
 	--org yourOrgName \
 
@@ -136,6 +181,20 @@ func newAttestSonarCmd(out io.Writer) *cobra.Command {
 				return err
 			}
 
+			err = MuXRequiredFlags(cmd, []string{"sonar-branch", "sonar-pull-request"}, false)
+			if err != nil {
+				return err
+			}
+
+			o.thresholds, err = sonar.ParseMetricThresholds(o.metricThresholds)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			if o.wait && o.timeout <= 0 {
+				return fmt.Errorf("--timeout must be a positive duration, got %s", o.timeout)
+			}
+
 			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
 			if err != nil {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
@@ -156,6 +215,11 @@ func newAttestSonarCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&o.projectKey, "sonar-project-key", "", sonarProjectKeyFlag)
 	cmd.Flags().StringVar(&o.serverURL, "sonar-server-url", "https://sonarcloud.io", sonarServerURLFlag)
 	cmd.Flags().StringVar(&o.revision, "sonar-revision", o.commitSHA, sonarRevisionFlag)
+	cmd.Flags().StringVar(&o.branch, "sonar-branch", "", sonarBranchFlag)
+	cmd.Flags().StringVar(&o.pullRequest, "sonar-pull-request", "", sonarPullRequestFlag)
+	cmd.Flags().StringArrayVar(&o.metricThresholds, "sonar-metric-threshold", []string{}, sonarMetricThresholdFlag)
+	cmd.Flags().BoolVar(&o.wait, "wait", false, sonarWaitFlag)
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 5*time.Minute, sonarTimeoutFlag)
 
 	err := RequireFlags(cmd, []string{"flow", "trail", "name", "sonar-api-token"})
 	if err != nil {
@@ -174,12 +238,36 @@ func (o *attestSonarOptions) run(args []string) error {
 	}
 
 	sc := sonar.NewSonarConfig(o.apiToken, o.workingDir, o.ceTaskURL, o.projectKey, o.serverURL, o.revision)
+	sc.Wait = o.wait
+	sc.Timeout = o.timeout
+	sc.Branch = o.branch
+	sc.PullRequest = o.pullRequest
 
 	o.payload.SonarResults, err = sc.GetSonarResults()
 	if err != nil {
 		return err
 	}
 
+	if len(o.thresholds) > 0 {
+		thresholdResults, err := sc.EvaluateMetricThresholds(o.payload.SonarResults, o.thresholds)
+		if err != nil {
+			return err
+		}
+		compliant := o.payload.SonarResults.QualityGate.Status != "ERROR"
+		for _, result := range thresholdResults {
+			if !result.Passed {
+				compliant = false
+				if result.Value == "" {
+					logger.Info("metric threshold %s is not met, %s has no value", result.Threshold, result.Metric)
+				} else {
+					logger.Info("metric threshold %s is not met, the value of %s is %s", result.Threshold, result.Metric, result.Value)
+				}
+			}
+		}
+		o.payload.UserData = mergeUserData(o.payload.UserData, "sonar_metric_thresholds", thresholdResults)
+		o.payload.Compliant = &compliant
+	}
+
 	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
 	if err != nil {
 		return err
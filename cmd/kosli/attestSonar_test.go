@@ -97,6 +97,18 @@ func (suite *AttestSonarCommandTestSuite) TestAttestSonarCmd() {
 			cmd:       fmt.Sprintf("attest sonar --name foo-s --fingerprint xxxx --commit HEAD --origin-url http://www.example.com --sonar-working-dir testdata/sonar/sonarcloud/.scannerwork %s", suite.defaultKosliArguments),
 			golden:    "Error: xxxx is not a valid SHA256 fingerprint. It should match the pattern ^([a-f0-9]{64})$\nUsage: kosli attest sonar [IMAGE-NAME | FILE-PATH | DIR-PATH] [flags]\n",
 		},
+		{
+			wantError: true,
+			name:      "fails when both --sonar-branch and --sonar-pull-request",
+			cmd:       fmt.Sprintf("attest sonar --name bar --sonar-project-key foo --sonar-branch main --sonar-pull-request 42 %s", suite.defaultKosliArguments),
+			golden:    "Error: only one of --sonar-branch, --sonar-pull-request is allowed\n",
+		},
+		{
+			wantError: true,
+			name:      "fails when --sonar-metric-threshold is not valid",
+			cmd:       fmt.Sprintf("attest sonar --name bar --sonar-working-dir testdata/sonar/sonarcloud/.scannerwork --sonar-metric-threshold new_coverage=80 %s", suite.defaultKosliArguments),
+			golden:    "Error: invalid metric threshold 'new_coverage=80'. It must be of the form METRIC OPERATOR VALUE, e.g. new_coverage>=80, where OPERATOR is one of: >=, <=, ==, !=, >, <\nUsage: kosli attest sonar [IMAGE-NAME | FILE-PATH | DIR-PATH] [flags]\n",
+		},
 		{
 			wantError: true,
 			name:      "attesting against an artifact that does not exist fails",
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/testresults"
+	"github.com/spf13/cobra"
+)
+
+type attestTestResultsOptions struct {
+	*CommonAttestationOptions
+	resultsPaths  []string
+	format        string
+	maxSkipped    int
+	thresholds    testresults.Thresholds
+	uploadResults bool
+	assert        bool
+	payload       GenericAttestationPayload
+}
+
+const attestTestResultsShortDesc = `Report a test results attestation to an artifact or a trail in a Kosli flow.  `
+
+const attestTestResultsLongDesc = attestTestResultsShortDesc + `
+The test results are read from the ^--results-dir^ files or directories (the current directory by default),
+according to ^--format^, which is one of:
+- ^junit^: JUnit XML (^.xml^ files).
+- ^trx^: Visual Studio/.NET test results (^.trx^ files). Tests are grouped in suites by class.
+- ^tap^: Test Anything Protocol streams (^.tap^ files). Each file is a suite.
+- ^go-json^: ^go test -json^ output (^.json^ or ^.jsonl^ files). Each package is a suite, and a package
+  failing without a failed test (e.g. a build failure) counts as an errored test.
+- ^cucumber^: Cucumber JSON reports (^.json^ files). Each feature is a suite and each scenario a test.
+
+The results are summarized with the same suites (total, failures, errors, skipped and duration) as JUnit attestations.
+A test reported more than once (e.g. retried) is counted once: if any attempt passed, it counts as passed
+and is flagged as flaky.
+
+The attestation is compliant when there are at most ^--max-failures^ failed or errored tests (0 by default)
+and, when set, at most ^--max-skipped^ skipped tests.
+The compliance is decided locally and the attestation is reported as a generic attestation,
+with the summary in its user data (under ^test_results^).
+By default, the parsed results files are also uploaded to Kosli's evidence vault.
+You can disable that by setting ^--upload-results=false^
+` + attestationBindingDesc + `
+
+` + commitDescription
+
+const attestTestResultsExample = `
+# report a test results attestation about a pre-built docker artifact (kosli calculates the fingerprint):
+kosli attest test-results yourDockerImageName \
+	--artifact-type docker \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--format trx \
+	--results-dir TestResults \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a test results attestation about a trail from the output of go test -json:
+go test -json ./... > test-results.json
+kosli attest test-results \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--format go-json \
+	--results-dir test-results.json \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# report a test results attestation about a trail from several directories,
+# allowing 2 failures and no skipped tests, and fail if it is non-compliant:
+kosli attest test-results \
+	--name yourAttestationName \
+	--flow yourFlowName \
+	--trail yourTrailName \
+	--format cucumber \
+	--results-dir reports/chrome \
+	--results-dir reports/firefox \
+	--max-failures 2 \
+	--max-skipped 0 \
+	--assert \
+	--api-token yourAPIToken \
+	--org yourOrgName
+`
+
+func newAttestTestResultsCmd(out io.Writer) *cobra.Command {
+	o := &attestTestResultsOptions{
+		CommonAttestationOptions: &CommonAttestationOptions{
+			fingerprintOptions: &fingerprintOptions{},
+		},
+		payload: GenericAttestationPayload{
+			CommonAttestationPayload: &CommonAttestationPayload{},
+		},
+	}
+	cmd := &cobra.Command{
+		// Args:    cobra.MaximumNArgs(1), // See CustomMaximumNArgs() below
+		Use:     "test-results [IMAGE-NAME | FILE-PATH | DIR-PATH]",
+		Short:   attestTestResultsShortDesc,
+		Long:    attestTestResultsLongDesc,
+		Example: attestTestResultsExample,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+
+			err := CustomMaximumNArgs(1, args)
+			if err != nil {
+				return err
+			}
+
+			err = RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			err = MuXRequiredFlags(cmd, []string{"fingerprint", "artifact-type"}, false)
+			if err != nil {
+				return err
+			}
+
+			err = ValidateSliceValues(o.redactedCommitInfo, allowedCommitRedactionValues)
+			if err != nil {
+				return fmt.Errorf("%s for --redact-commit-info", err.Error())
+			}
+
+			err = testresults.ValidateFormat(o.format)
+			if err != nil {
+				return fmt.Errorf("%s for --format", err.Error())
+			}
+
+			// skipped tests are only checked when --max-skipped is set
+			if cmd.Flags().Changed("max-skipped") {
+				o.thresholds.MaxSkipped = &o.maxSkipped
+			}
+
+			err = ValidateAttestationArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint)
+			if err != nil {
+				return ErrorBeforePrintingUsage(cmd, err.Error())
+			}
+
+			return ValidateRegistryFlags(cmd, o.fingerprintOptions)
+
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(args)
+		},
+	}
+
+	ci := WhichCI()
+	addAttestationFlags(cmd, o.CommonAttestationOptions, o.payload.CommonAttestationPayload, ci)
+	cmd.Flags().StringVar(&o.format, "format", "", testResultsFormatFlag)
+	cmd.Flags().StringSliceVarP(&o.resultsPaths, "results-dir", "R", []string{"."}, testResultsPathsFlag)
+	cmd.Flags().IntVar(&o.thresholds.MaxFailures, "max-failures", 0, maxFailuresFlag)
+	cmd.Flags().IntVar(&o.maxSkipped, "max-skipped", 0, maxSkippedFlag)
+	cmd.Flags().BoolVar(&o.uploadResults, "upload-results", true, uploadTestResultsFlag)
+	cmd.Flags().BoolVar(&o.assert, "assert", false, attestationAssertFlag)
+
+	err := RequireFlags(cmd, []string{"flow", "trail", "name", "format"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+func (o *attestTestResultsOptions) run(args []string) error {
+	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/generic", global.Host, global.Org, o.flowName, o.trailName)
+
+	err := o.CommonAttestationOptions.run(args, o.payload.CommonAttestationPayload)
+	if err != nil {
+		return err
+	}
+
+	results, err := testresults.ProcessResults(o.resultsPaths, o.format)
+	if err != nil {
+		return err
+	}
+	for _, flaky := range results.Flaky {
+		logger.Warn("flaky test: %s %s passed after %d attempts", flaky.Suite, flaky.Name, flaky.Attempts)
+	}
+	o.payload.Compliant = results.Evaluate(o.thresholds)
+	o.payload.UserData = mergeUserData(o.payload.UserData, "test_results", results)
+	if o.uploadResults {
+		o.attachments = append(o.attachments, results.Files...)
+	}
+
+	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
+	if err != nil {
+		return err
+	}
+	// if we created a tar package, remove it after uploading it
+	if cleanupNeeded {
+		defer os.Remove(evidencePath)
+	}
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    url,
+		Form:   form,
+		DryRun: global.DryRun,
+		Token:  global.ApiToken,
+	}
+	_, err = kosliClient.Do(reqParams)
+	if err == nil && !global.DryRun {
+		logger.Info("test-results attestation '%s' is reported to trail: %s", o.payload.AttestationName, o.trailName)
+	}
+
+	if err == nil && !o.payload.Compliant && o.assert {
+		return fmt.Errorf("%s", strings.Join(results.Violations, "\n"))
+	}
+	return wrapAttestationError(err)
+}
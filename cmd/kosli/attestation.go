@@ -61,6 +61,7 @@ func (o *CommonAttestationOptions) run(args []string, payload *CommonAttestation
 	}
 
 	if o.fingerprintOptions.artifactType != "" {
+		o.fingerprintOptions.repoRoot = o.srcRepoRoot
 		payload.ArtifactFingerprint, err = GetSha256Digest(args[0], o.fingerprintOptions, logger)
 		if err != nil {
 			return fmt.Errorf("failed to calculate artifact fingerprint: %s", err)
@@ -68,7 +69,7 @@ func (o *CommonAttestationOptions) run(args []string, payload *CommonAttestation
 	}
 
 	if o.commitSHA != "" {
-		gv, err := gitview.New(o.srcRepoRoot)
+		gv, err := newGitView(o.srcRepoRoot)
 		if err != nil {
 			return fmt.Errorf("failed to get commit info. %s", err)
 		}
@@ -123,6 +124,20 @@ func processExternalURLs(externalURLs, externalFingerprints map[string]string) (
 	return processedExternalURLs, nil
 }
 
+// mergeUserData adds the results of locally evaluated attestation types to the
+// user data. User data that is not a JSON object is kept under the "user_data" key.
+func mergeUserData(userData interface{}, key string, value interface{}) map[string]interface{} {
+	merged, ok := userData.(map[string]interface{})
+	if !ok {
+		merged = map[string]interface{}{}
+		if userData != nil {
+			merged["user_data"] = userData
+		}
+	}
+	merged[key] = value
+	return merged
+}
+
 func prepareAttestationForm(payload interface{}, evidencePaths []string) ([]requests.FormItem, bool, string, error) {
 	form, cleanupNeeded, evidencePath, err := newAttestationForm(payload, evidencePaths)
 	if err != nil {
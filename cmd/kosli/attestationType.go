@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/attestationtype"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/spf13/cobra"
+)
+
+const attestationTypeDesc = `All Kosli attestation-type commands.`
+
+func newAttestationTypeCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attestation-type",
+		Short: attestationTypeDesc,
+		Long:  attestationTypeDesc,
+	}
+
+	// Add subcommands
+	cmd.AddCommand(
+		newAttestationTypeTestCmd(out),
+	)
+	return cmd
+}
+
+type attestationTypeVersion struct {
+	Version    int                 `json:"version"`
+	TypeSchema interface{}         `json:"type_schema"`
+	Evaluator  *JQEvaluatorPayload `json:"evaluator"`
+}
+
+type attestationTypeResponse struct {
+	Name     string                   `json:"name"`
+	Versions []attestationTypeVersion `json:"versions"`
+}
+
+// fetchAttestationTypeDefinition gets the schema and jq rules of a custom attestation type from Kosli.
+// typeExpression is either a type name or name@vN. Without a version, the latest version is used,
+// and a version which does not exist is an error.
+func fetchAttestationTypeDefinition(typeExpression string) (*attestationtype.Definition, error) {
+	typeName := typeExpression
+	var version string
+	var err error
+	if strings.Contains(typeExpression, "@") {
+		typeName, version, err = handleCustomAttestationTypeExpression(typeExpression)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	url := fmt.Sprintf("%s/api/v2/custom-attestation-types/%s/%s", global.Host, global.Org, typeName)
+	if version != "" {
+		url = fmt.Sprintf("%s?version=%s", url, version)
+	}
+	reqParams := &requests.RequestParams{
+		Method: http.MethodGet,
+		URL:    url,
+		Token:  global.ApiToken,
+	}
+	response, err := kosliClient.Do(reqParams)
+	if err != nil {
+		return nil, err
+	}
+
+	var attestationType attestationTypeResponse
+	err = json.Unmarshal([]byte(response.Body), &attestationType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse attestation type '%s': %v", typeExpression, err)
+	}
+
+	definition := &attestationtype.Definition{Name: typeName}
+	selected, err := selectAttestationTypeVersion(attestationType.Versions, version)
+	if err != nil {
+		return nil, fmt.Errorf("attestation type '%s': %v", typeName, err)
+	}
+	if selected == nil {
+		return definition, nil
+	}
+
+	definition.Version = selected.Version
+	definition.Schema, err = attestationtype.ParseSchema(selected.TypeSchema)
+	if err != nil {
+		logger.Warn("cannot validate against the schema of attestation type '%s': %s", typeExpression, err)
+	}
+	if selected.Evaluator != nil && selected.Evaluator.ContentType == "jq" {
+		definition.Rules = selected.Evaluator.Rules
+	}
+	return definition, nil
+}
+
+// attestationTypeVersionNotFoundError is returned when the requested version of an attestation type does not exist
+type attestationTypeVersionNotFoundError struct {
+	version string
+}
+
+func (e *attestationTypeVersionNotFoundError) Error() string {
+	return fmt.Sprintf("version v%s does not exist", e.version)
+}
+
+// selectAttestationTypeVersion returns the requested version of an attestation type, or its latest
+// version when no version is requested. It is nil when the type has no versions.
+func selectAttestationTypeVersion(versions []attestationTypeVersion, version string) (*attestationTypeVersion, error) {
+	var selected *attestationTypeVersion
+	for i, v := range versions {
+		if version != "" {
+			if fmt.Sprint(v.Version) == version {
+				return &versions[i], nil
+			}
+			continue
+		}
+		if selected == nil || v.Version > selected.Version {
+			selected = &versions[i]
+		}
+	}
+	if version != "" {
+		return nil, &attestationTypeVersionNotFoundError{version: version}
+	}
+	return selected, nil
+}
+
+// printAttestationTypeResult prints the schema violations and the outcome of each jq rule
+func printAttestationTypeResult(out io.Writer, result *attestationtype.Result) {
+	for _, schemaError := range result.SchemaErrors {
+		fmt.Fprintf(out, "schema violation %s\n", schemaError)
+	}
+	for _, rule := range result.Rules {
+		fmt.Fprintln(out, formatRuleResult(rule))
+	}
+}
+
+func formatRuleResult(rule attestationtype.RuleResult) string {
+	if rule.Passed {
+		return fmt.Sprintf("rule '%s': passed", rule.Rule)
+	}
+	return fmt.Sprintf("rule '%s': failed (%s)", rule.Rule, rule.Output)
+}
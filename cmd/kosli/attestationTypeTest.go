@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kosli-dev/cli/internal/attestationtype"
+	"github.com/spf13/cobra"
+)
+
+const attestationTypeTestShortDesc = `Test attestation data against a custom attestation type.`
+
+const attestationTypeTestLongDesc = attestationTypeTestShortDesc + `
+The data in ^--data^ is validated against the JSON schema of the type and each of its jq evaluation rules
+is evaluated locally. The outcome of the schema validation and of each rule is printed, and the command
+fails if the data does not satisfy the type.
+
+Use ^--schema^ and ^--jq^ to author a type offline before creating it with ^kosli create attestation-type^.
+If neither ^--schema^ nor ^--jq^ is given, the schema and rules of TYPE-NAME are fetched from Kosli.
+TYPE-NAME can then be given as ^customTypeName@vN^ to test against the Nth version of the type.
+`
+
+const attestationTypeTestExample = `
+# test data against a custom attestation type defined in Kosli:
+kosli attestation-type test customTypeName \
+	--data yourCustomData.json \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# test data against version 2 of a custom attestation type defined in Kosli:
+kosli attestation-type test customTypeName@v2 \
+	--data yourCustomData.json \
+	--api-token yourAPIToken \
+	--org yourOrgName
+
+# test data against a local schema and jq rules:
+kosli attestation-type test customTypeName \
+	--data yourCustomData.json \
+	--schema person-schema.json \
+	--jq ".age >= 18" \
+	--jq ".age < 65"
+`
+
+type attestationTypeTestOptions struct {
+	dataFile       string
+	schemaFilePath string
+	jqRules        []string
+}
+
+func newAttestationTypeTestCmd(out io.Writer) *cobra.Command {
+	o := new(attestationTypeTestOptions)
+	cmd := &cobra.Command{
+		Use:     "test TYPE-NAME",
+		Short:   attestationTypeTestShortDesc,
+		Long:    attestationTypeTestLongDesc,
+		Example: attestationTypeTestExample,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !o.isLocal() {
+				err := RequireGlobalFlags(global, []string{"Org", "ApiToken"})
+				if err != nil {
+					return ErrorBeforePrintingUsage(cmd, err.Error())
+				}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.run(out, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.dataFile, "data", "", attestationTypeTestDataFlag)
+	cmd.Flags().StringVarP(&o.schemaFilePath, "schema", "s", "", attestationTypeTestSchemaFlag)
+	cmd.Flags().StringArrayVar(&o.jqRules, "jq", []string{}, attestationTypeTestJqFlag)
+
+	err := RequireFlags(cmd, []string{"data"})
+	if err != nil {
+		logger.Error("failed to configure required flags: %v", err)
+	}
+
+	return cmd
+}
+
+// isLocal is true when the type is defined by flags rather than fetched from Kosli
+func (o *attestationTypeTestOptions) isLocal() bool {
+	return o.schemaFilePath != "" || len(o.jqRules) > 0
+}
+
+func (o *attestationTypeTestOptions) run(out io.Writer, args []string) error {
+	data, err := LoadJsonData(o.dataFile)
+	if err != nil {
+		return fmt.Errorf("failed to load attestation data. %s", err)
+	}
+
+	var definition *attestationtype.Definition
+	if o.isLocal() {
+		definition = &attestationtype.Definition{Name: args[0], Rules: o.jqRules}
+		if o.schemaFilePath != "" {
+			definition.Schema, err = LoadJsonData(o.schemaFilePath)
+			if err != nil {
+				return fmt.Errorf("failed to load schema. %s", err)
+			}
+		}
+	} else {
+		definition, err = fetchAttestationTypeDefinition(args[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := attestationtype.Evaluate(definition, data)
+	if err != nil {
+		return err
+	}
+
+	if definition.Schema == nil {
+		fmt.Fprintln(out, "schema: none")
+	} else if result.SchemaPassed() {
+		fmt.Fprintln(out, "schema: passed")
+	} else {
+		fmt.Fprintln(out, "schema: failed")
+	}
+	printAttestationTypeResult(out, result)
+
+	if !result.Passed() {
+		return fmt.Errorf("attestation data in %s does not satisfy attestation type '%s'", o.dataFile, args[0])
+	}
+	return nil
+}
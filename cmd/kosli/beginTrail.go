@@ -111,7 +111,7 @@ func (o *beginTrailOptions) run(args []string) error {
 	}
 
 	if o.commitSHA != "" {
-		gv, err := gitview.New(o.srcRepoRoot)
+		gv, err := newGitView(o.srcRepoRoot)
 		if err != nil {
 			return err
 		}
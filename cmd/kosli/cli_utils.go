@@ -27,6 +27,7 @@ import (
 const (
 	bitbucket   = "Bitbucket"
 	github      = "Github"
+	gitea       = "Gitea"
 	teamcity    = "Teamcity"
 	gitlab      = "Gitlab"
 	azureDevops = "Azure Devops"
@@ -37,7 +38,7 @@ const (
 )
 
 // supportedCIs the set of CI tools that are supported for defaulting
-var supportedCIs = []string{bitbucket, github, teamcity, gitlab, azureDevops, circleci, codeBuild}
+var supportedCIs = []string{bitbucket, github, gitea, teamcity, gitlab, azureDevops, circleci, codeBuild}
 
 // ciTemplates a map of kosli flags and corresponding default templates in supported CI tools
 var ciTemplates = map[string]map[string]string{
@@ -48,6 +49,15 @@ var ciTemplates = map[string]map[string]string{
 		"commit-url": "${GITHUB_SERVER_URL}/${GITHUB_REPOSITORY}/commit/${GITHUB_SHA}",
 		"build-url":  "${GITHUB_SERVER_URL}/${GITHUB_REPOSITORY}/actions/runs/${GITHUB_RUN_ID}",
 	},
+	// Gitea and Forgejo Actions provide the GitHub Actions variables
+	gitea: {
+		"git-commit": "${GITHUB_SHA}",
+		"repository": "${GITHUB_REPOSITORY}",
+		"org":        "${GITHUB_REPOSITORY_OWNER}",
+		"base-url":   "${GITHUB_SERVER_URL}",
+		"commit-url": "${GITHUB_SERVER_URL}/${GITHUB_REPOSITORY}/commit/${GITHUB_SHA}",
+		"build-url":  "${GITHUB_SERVER_URL}/${GITHUB_REPOSITORY}/actions/runs/${GITHUB_RUN_NUMBER}",
+	},
 	bitbucket: {
 		"git-commit": "${BITBUCKET_COMMIT}",
 		"repository": "${BITBUCKET_REPO_SLUG}",
@@ -117,6 +127,11 @@ func GetCIDefaultsTemplates(ciTools, keys []string) string {
 func WhichCI() string {
 	if _, ok := os.LookupEnv("BITBUCKET_BUILD_NUMBER"); ok {
 		return bitbucket
+	} else if _, ok := os.LookupEnv("GITEA_ACTIONS"); ok {
+		// checked before Github since Gitea Actions also sets GITHUB_RUN_NUMBER
+		return gitea
+	} else if _, ok := os.LookupEnv("FORGEJO_ACTIONS"); ok {
+		return gitea
 	} else if _, ok := os.LookupEnv("GITHUB_RUN_NUMBER"); ok {
 		return github
 	} else if _, ok := os.LookupEnv("TEAMCITY_VERSION"); ok {
@@ -310,7 +325,7 @@ func GetFlagFromVarName(varName string) string {
 }
 
 // GetSha256Digest calculates the sha256 digest of an artifact.
-// Supported artifact types are: dir, file, docker
+// Supported artifact types are: dir, file, docker, oci, git-tree
 func GetSha256Digest(artifactName string, o *fingerprintOptions, logger *log.Logger) (string, error) {
 	var err error
 	var fingerprint string
@@ -318,7 +333,7 @@ func GetSha256Digest(artifactName string, o *fingerprintOptions, logger *log.Log
 	case "file":
 		fingerprint, err = digest.FileSha256(artifactName)
 	case "dir":
-		fingerprint, err = digest.DirSha256(artifactName, o.excludePaths, logger)
+		fingerprint, err = digest.DirSha256(artifactName, o.excludePaths, o.dirOptions, logger)
 	case "oci":
 		fingerprint, err = digest.OciSha256(artifactName, o.registryUsername, o.registryPassword)
 	case "docker":
@@ -327,6 +342,8 @@ func GetSha256Digest(artifactName string, o *fingerprintOptions, logger *log.Log
 		} else {
 			fingerprint, err = digest.DockerImageSha256(artifactName)
 		}
+	case "git-tree":
+		fingerprint, err = gitTreeSha256(artifactName, o.repoRoot)
 	default:
 		return "", fmt.Errorf("%s is not a supported artifact type", o.artifactType)
 	}
@@ -335,6 +352,36 @@ func GetSha256Digest(artifactName string, o *fingerprintOptions, logger *log.Log
 	return fingerprint, err
 }
 
+// newGitView opens the git repository at repoRoot, with the --git-remote as the remote of its repository URL.
+// With --git-auto-deepen, missing history is fetched from the remote when the repository is a shallow clone.
+func newGitView(repoRoot string) (*gitview.GitView, error) {
+	gv, err := gitview.NewWithRemote(repoRoot, global.GitRemote)
+	if err != nil {
+		return nil, err
+	}
+	if global.GitAutoDeepen {
+		gv.EnableAutoDeepen(global.GitAutoDeepenLimit, logger)
+	}
+	return gv, nil
+}
+
+// gitTreeSha256 calculates the sha256 digest of a git tree. The artifactName is
+// a commit SHA or ref optionally followed by a colon and a subpath, e.g. HEAD:services/api
+func gitTreeSha256(artifactName, repoRoot string) (string, error) {
+	if repoRoot == "" {
+		repoRoot = "."
+	}
+	revision, subPath, _ := strings.Cut(artifactName, ":")
+	if revision == "" {
+		return "", fmt.Errorf("a git commit or ref is required for artifact type git-tree, e.g. HEAD or HEAD:path/to/dir")
+	}
+	gv, err := newGitView(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return gv.TreeSha256(revision, subPath)
+}
+
 // LoadJsonData loads json data from a file
 func LoadJsonData(filepath string) (interface{}, error) {
 	var err error
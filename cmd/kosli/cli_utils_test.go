@@ -33,6 +33,16 @@ func (suite *CliUtilsTestSuite) TestWhichCI() {
 			envVars: map[string]string{"GITHUB_RUN_NUMBER": "50"},
 			want:    github,
 		},
+		{
+			name:    "Gitea actions is detected before Github actions.",
+			envVars: map[string]string{"GITEA_ACTIONS": "true", "GITHUB_RUN_NUMBER": "50"},
+			want:    gitea,
+		},
+		{
+			name:    "Forgejo actions is detected as Gitea.",
+			envVars: map[string]string{"FORGEJO_ACTIONS": "true", "GITHUB_RUN_NUMBER": "50"},
+			want:    gitea,
+		},
 		{
 			name:    "Bitbucket actions is detected.",
 			envVars: map[string]string{"BITBUCKET_BUILD_NUMBER": "50"},
@@ -101,6 +111,16 @@ func (suite *CliUtilsTestSuite) TestDefaultValue() {
 			},
 			want: "cyber-dojo/dashboard",
 		},
+		{
+			name: "Lookup default base URL for Gitea.",
+			args: args{
+				ci:               gitea,
+				flag:             "base-url",
+				envVars:          map[string]string{"GITHUB_SERVER_URL": "https://codeberg.org"},
+				unsetTestsEnvVar: true,
+			},
+			want: "https://codeberg.org",
+		},
 		{
 			name: "Lookup an existing default for Bitbucket.",
 			args: args{
@@ -393,6 +413,27 @@ func (suite *CliUtilsTestSuite) TestGetSha256Digest() {
 			},
 			expectError: true,
 		},
+		{
+			name: "git-tree without a git ref returns an error.",
+			args: args{
+				fingerprintOptions: &fingerprintOptions{
+					artifactType: "git-tree",
+				},
+				artifactName: ":config",
+			},
+			expectError: true,
+		},
+		{
+			name: "git-tree outside a git repo returns an error.",
+			args: args{
+				fingerprintOptions: &fingerprintOptions{
+					artifactType: "git-tree",
+					repoRoot:     "testdata/folder1",
+				},
+				artifactName: "HEAD",
+			},
+			expectError: true,
+		},
 	} {
 		suite.Suite.Run(t.name, func() {
 			fingerprint, err := GetSha256Digest(t.args.artifactName, t.args.fingerprintOptions,
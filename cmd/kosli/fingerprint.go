@@ -3,6 +3,7 @@ package main
 import (
 	"io"
 
+	"github.com/kosli-dev/cli/internal/digest"
 	"github.com/spf13/cobra"
 )
 
@@ -21,12 +22,14 @@ plus the ability to use recursive globs "**"
 const fingerprintLongDesc = fingerprintShortDesc + `
 Requires ^--artifact-type^ flag to be set.
 Artifact type can be one of: "file" for files, "dir" for directories, "oci" for container
-images in registries or "docker" for local docker images.
+images in registries, "docker" for local docker images or "git-tree" for the tree of a git commit.
 
 Fingerprinting container images can be done using the local docker daemon or the fingerprint can be fetched
 from a remote registry.
 
-` + fingerprintDirSynopsis
+` + fingerprintDirSynopsis + `
+
+` + fingerprintGitTreeSynopsis
 
 const fingerprintExamples = `
 # fingerprint a file
@@ -41,10 +44,19 @@ kosli fingerprint --artifact-type dir --exclude logs --exclude *.exe mydir
 # fingerprint a dir while excluding all ^.pyc^ files
 kosli fingerprint --artifact-type dir  --exclude **/*.pyc mydir
 
+# fingerprint a dir while recording symlink targets and executable bits
+kosli fingerprint --artifact-type dir --symlinks record-target --include-file-mode mydir
+
 # fingerprint a dir while excluding paths in .kosli_ignore file
 echo bar/file.txt > mydir/.kosli_ignore
 kosli fingerprint --artifact-type dir mydir
 
+# fingerprint the git tree of the HEAD commit in the current directory's repo
+kosli fingerprint --artifact-type git-tree HEAD
+
+# fingerprint the subdirectory ^config/prod^ of the git tree of a given commit
+kosli fingerprint --artifact-type git-tree yourCommitSha:config/prod --repo-root path/to/repo
+
 # fingerprint a locally available docker image (requires docker daemon running)
 kosli fingerprint --artifact-type docker nginx:latest
 
@@ -61,12 +73,14 @@ type fingerprintOptions struct {
 	registryUsername string
 	registryPassword string
 	excludePaths     []string
+	dirOptions       digest.DirFingerprintOptions
+	repoRoot         string
 }
 
 func newFingerprintCmd(out io.Writer) *cobra.Command {
 	o := new(fingerprintOptions)
 	cmd := &cobra.Command{
-		Use:     "fingerprint {IMAGE-NAME | FILE-PATH | DIR-PATH}",
+		Use:     "fingerprint {IMAGE-NAME | FILE-PATH | DIR-PATH | GIT-REF[:PATH]}",
 		Short:   fingerprintShortDesc,
 		Long:    fingerprintLongDesc,
 		Example: fingerprintExamples,
@@ -81,6 +95,7 @@ func newFingerprintCmd(out io.Writer) *cobra.Command {
 
 	addFingerprintFlags(cmd, o)
 	cmd.Flags().StringSliceVarP(&o.excludePaths, "e", "e", []string{}, excludePathsFlag)
+	cmd.Flags().StringVar(&o.repoRoot, "repo-root", ".", gitTreeRepoRootFlag)
 	err := RequireFlags(cmd, []string{"artifact-type"})
 	if err != nil {
 		logger.Error("failed to configure required flags: %v", err)
@@ -2,10 +2,13 @@ package main
 
 import (
 	"log"
+	"strconv"
 
 	"github.com/kosli-dev/cli/internal/aws"
 	azUtils "github.com/kosli-dev/cli/internal/azure"
 	bbUtils "github.com/kosli-dev/cli/internal/bitbucket"
+	"github.com/kosli-dev/cli/internal/digest"
+	giteaUtils "github.com/kosli-dev/cli/internal/gitea"
 	ghUtils "github.com/kosli-dev/cli/internal/github"
 	gitlabUtils "github.com/kosli-dev/cli/internal/gitlab"
 	"github.com/spf13/cobra"
@@ -24,6 +27,7 @@ func addFingerprintFlags(cmd *cobra.Command, o *fingerprintOptions) {
 	cmd.Flags().StringVar(&o.registryUsername, "registry-username", "", registryUsernameFlag)
 	cmd.Flags().StringVar(&o.registryPassword, "registry-password", "", registryPasswordFlag)
 	cmd.Flags().StringSliceVarP(&o.excludePaths, "exclude", "x", []string{}, excludePathsFlag)
+	addDirFingerprintFlags(cmd, &o.dirOptions)
 
 	err := DeprecateFlags(cmd, map[string]string{
 		"registry-provider": "no longer used",
@@ -34,6 +38,38 @@ func addFingerprintFlags(cmd *cobra.Command, o *fingerprintOptions) {
 	}
 }
 
+func addDirFingerprintFlags(cmd *cobra.Command, o *digest.DirFingerprintOptions) {
+	cmd.Flags().StringVar(&o.Symlinks, "symlinks", "", symlinksFlag)
+	cmd.Flags().VarPF(optionalBoolValue{&o.IncludeFileMode}, "include-file-mode", "", includeFileModeFlag).NoOptDefVal = "true"
+	cmd.Flags().VarPF(optionalBoolValue{&o.SkipEmptyDirs}, "skip-empty-dirs", "", skipEmptyDirsFlag).NoOptDefVal = "true"
+}
+
+// optionalBoolValue is the value of a boolean flag which is left nil when the flag is not set,
+// so that an explicit false can be told apart from the default
+type optionalBoolValue struct {
+	value **bool
+}
+
+func (b optionalBoolValue) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*b.value = &v
+	return nil
+}
+
+func (b optionalBoolValue) String() string {
+	if b.value == nil || *b.value == nil {
+		return "false"
+	}
+	return strconv.FormatBool(**b.value)
+}
+
+func (b optionalBoolValue) Type() string {
+	return "bool"
+}
+
 func addAWSAuthFlags(cmd *cobra.Command, o *aws.AWSStaticCreds) {
 	cmd.Flags().StringVar(&o.AccessKeyID, "aws-key-id", "", awsKeyIdFlag)
 	cmd.Flags().StringVar(&o.SecretAccessKey, "aws-secret-key", "", awsSecretKeyFlag)
@@ -49,6 +85,7 @@ func addBitbucketFlags(cmd *cobra.Command, bbConfig *bbUtils.Config, ci string)
 	cmd.Flags().StringVar(&bbConfig.Password, "bitbucket-password", "", bbPasswordFlag)
 	cmd.Flags().StringVar(&bbConfig.AccessToken, "bitbucket-access-token", "", bbAccessTokenFlag)
 	cmd.Flags().StringVar(&bbConfig.Workspace, "bitbucket-workspace", DefaultValue(ci, "workspace"), bbWorkspaceFlag)
+	cmd.Flags().StringVar(&bbConfig.BaseURL, "bitbucket-base-url", "", bbBaseURLFlag)
 	cmd.Flags().StringVar(&bbConfig.Repository, "repository", DefaultValue(ci, "repository"), repositoryFlag)
 }
 
@@ -73,6 +110,13 @@ func addGitlabFlags(cmd *cobra.Command, gitlabConfig *gitlabUtils.GitlabConfig,
 	cmd.Flags().StringVar(&gitlabConfig.Repository, "repository", DefaultValue(ci, "repository"), repositoryFlag)
 }
 
+func addGiteaFlags(cmd *cobra.Command, giteaConfig *giteaUtils.Config, ci string) {
+	cmd.Flags().StringVar(&giteaConfig.Token, "gitea-token", "", giteaTokenFlag)
+	cmd.Flags().StringVar(&giteaConfig.Org, "gitea-org", DefaultValue(ci, "org"), giteaOrgFlag)
+	cmd.Flags().StringVar(&giteaConfig.BaseURL, "gitea-base-url", DefaultValue(ci, "base-url"), giteaBaseURLFlag)
+	cmd.Flags().StringVar(&giteaConfig.Repository, "repository", DefaultValue(ci, "repository"), repositoryFlag)
+}
+
 func addArtifactPRFlags(cmd *cobra.Command, o *pullRequestArtifactOptions, ci string) {
 	addArtifactEvidenceFlags(cmd, &o.payload.TypedEvidencePayload, ci)
 	cmd.Flags().StringVarP(&o.userDataFilePath, "user-data", "u", "", evidenceUserDataFlag)
@@ -81,6 +125,11 @@ func addArtifactPRFlags(cmd *cobra.Command, o *pullRequestArtifactOptions, ci st
 	cmd.Flags().BoolVar(&o.assert, "assert", false, assertPREvidenceFlag)
 }
 
+func addIndependentApprovalFlags(cmd *cobra.Command, o *attestPROptions) {
+	cmd.Flags().BoolVar(&o.requireIndependentApproval, "require-independent-approval", false, requireIndependentApprovalFlag)
+	cmd.Flags().IntVar(&o.independentApprovers, "independent-approvers", 1, independentApproversFlag)
+}
+
 func addArtifactEvidenceFlags(cmd *cobra.Command, payload *TypedEvidencePayload, ci string) {
 	addEvidenceFlags(cmd, payload, ci)
 	cmd.Flags().StringVarP(&payload.ArtifactFingerprint, "fingerprint", "F", "", fingerprintFlag)
@@ -134,3 +183,9 @@ func addAttestationFlags(cmd *cobra.Command, o *CommonAttestationOptions, payloa
 	addFingerprintFlags(cmd, o.fingerprintOptions)
 	addDryRunFlag(cmd)
 }
+
+func addCommitRangeFlags(cmd *cobra.Command, o *commitRangeOptions) {
+	cmd.Flags().StringVar(&o.sinceCommit, "since-commit", "", issueSinceCommitFlag)
+	cmd.Flags().StringVar(&o.commitRange, "commit-range", "", issueCommitRangeFlag)
+	cmd.Flags().StringSliceVar(&o.paths, "paths", []string{}, issuePathsFlag)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/gitview"
+	"github.com/spf13/cobra"
+)
+
+// commitRangeOptions select a range of commits whose messages are searched for issue references
+type commitRangeOptions struct {
+	sinceCommit string
+	commitRange string
+	// paths scope the range to the commits changing files matching them
+	paths []string
+}
+
+// CommitRangeReferences are the issue references of each commit in a range of commits
+type CommitRangeReferences struct {
+	Commits []*gitview.CommitReferences `json:"commits"`
+	// UnreferencedCommits are the commits, other than merge commits, without issue references
+	UnreferencedCommits []string `json:"unreferenced_commits"`
+}
+
+// issueReferences are the issue references found for a commit, or for a range of commits
+type issueReferences struct {
+	References []string
+	// CommitRange is nil when no range of commits is searched
+	CommitRange *CommitRangeReferences
+}
+
+// validate checks that at most one of --since-commit and --commit-range is set, the format of the range and the paths
+func (o *commitRangeOptions) validate(cmd *cobra.Command) error {
+	err := MuXRequiredFlags(cmd, []string{"since-commit", "commit-range"}, false)
+	if err != nil {
+		return err
+	}
+	if o.commitRange != "" {
+		oldest, newest, found := strings.Cut(o.commitRange, "..")
+		if !found || oldest == "" || newest == "" || strings.HasPrefix(newest, ".") {
+			return fmt.Errorf("invalid --commit-range %s. It must be of the form OLDEST..NEWEST", o.commitRange)
+		}
+	}
+	return gitview.ValidatePaths(o.paths)
+}
+
+// bounds returns the oldest (excluded) and newest commits of the range.
+// With --since-commit, the newest commit is the given commit.
+func (o *commitRangeOptions) bounds(commit string) (string, string, bool) {
+	if o.commitRange != "" {
+		oldest, newest, _ := strings.Cut(o.commitRange, "..")
+		return oldest, newest, true
+	}
+	if o.sinceCommit != "" {
+		return o.sinceCommit, commit, true
+	}
+	return "", commit, false
+}
+
+// findIssueReferences finds the issue references matching pattern in the message of the commit, or of each
+// commit in the range, the branch name (unless ignoreBranchMatch) and the secondary source
+func findIssueReferences(gv *gitview.GitView, pattern, commit, secondarySource string, ignoreBranchMatch bool,
+	rangeOptions commitRangeOptions, trackerName string) (*issueReferences, error) {
+	oldest, newest, isRange := rangeOptions.bounds(commit)
+
+	references, commitInfo, err := gv.MatchPatternInCommitMessageORBranchName(pattern, newest, secondarySource, ignoreBranchMatch)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("Checked for %s issue references in Git commit %s on branch %s commit message:\n%s", trackerName, commitInfo.Sha1, commitInfo.Branch, commitInfo.Message)
+	result := &issueReferences{References: references}
+	if !isRange {
+		logger.Debug("the following %s references are found in commit message or branch name: %v", trackerName, references)
+		return result, nil
+	}
+
+	commits, err := gv.MatchPatternInCommitRange(pattern, oldest, newest, rangeOptions.paths, logger)
+	if err != nil {
+		return nil, err
+	}
+	result.CommitRange = &CommitRangeReferences{Commits: commits, UnreferencedCommits: []string{}}
+	allReferences := make(map[string]struct{})
+	for _, reference := range references {
+		allReferences[reference] = struct{}{}
+	}
+	for _, c := range commits {
+		logger.Debug("the following %s references are found in the message of commit %s: %v", trackerName, c.Sha1, c.References)
+		for _, reference := range c.References {
+			allReferences[reference] = struct{}{}
+		}
+		// merge commits only bring in other commits of the range
+		if len(c.References) == 0 && !c.IsMerge {
+			result.CommitRange.UnreferencedCommits = append(result.CommitRange.UnreferencedCommits, c.Sha1)
+		}
+	}
+	result.References = make([]string, 0, len(allReferences))
+	for reference := range allReferences {
+		result.References = append(result.References, reference)
+	}
+	sort.Strings(result.References)
+
+	logger.Debug("the following %s references are found in %d commit(s) between %s and %s, or branch name: %v",
+		trackerName, len(commits), oldest, newest, result.References)
+	for _, sha1 := range result.CommitRange.UnreferencedCommits {
+		logger.Info("commit %s has no %s issue reference", sha1, trackerName)
+	}
+	return result, nil
+}
+
+// excludePullRequests removes the references that turned out to be pull requests rather than issues.
+// The commits in the range that only reference pull requests become unreferenced.
+func (r *issueReferences) excludePullRequests(pullRequests []string, trackerName string) {
+	if len(pullRequests) == 0 {
+		return
+	}
+	isExcluded := make(map[string]bool, len(pullRequests))
+	for _, reference := range pullRequests {
+		isExcluded[reference] = true
+	}
+	references := []string{}
+	for _, reference := range r.References {
+		if !isExcluded[reference] {
+			references = append(references, reference)
+		}
+	}
+	r.References = references
+	if r.CommitRange == nil {
+		return
+	}
+
+	unreferencedCommits := []string{}
+	for _, c := range r.CommitRange.Commits {
+		if c.IsMerge {
+			continue
+		}
+		referenced := false
+		for _, reference := range c.References {
+			referenced = referenced || !isExcluded[reference]
+		}
+		if referenced {
+			continue
+		}
+		if len(c.References) > 0 {
+			logger.Info("commit %s has no %s issue reference, only references to pull requests: %v", c.Sha1, trackerName, c.References)
+		}
+		unreferencedCommits = append(unreferencedCommits, c.Sha1)
+	}
+	r.CommitRange.UnreferencedCommits = unreferencedCommits
+}
+
+// unreferencedCommitsError is the assert error when commits in the range have no issue reference
+func (r *issueReferences) unreferencedCommitsError(trackerName string) error {
+	if r.CommitRange == nil || len(r.CommitRange.UnreferencedCommits) == 0 {
+		return nil
+	}
+	return fmt.Errorf("found %d commit(s) without %s references in their message:\n\t%s", len(r.CommitRange.UnreferencedCommits),
+		trackerName, strings.Join(r.CommitRange.UnreferencedCommits, "\n\t"))
+}
@@ -9,6 +9,7 @@ import (
 
 	azUtils "github.com/kosli-dev/cli/internal/azure"
 	bbUtils "github.com/kosli-dev/cli/internal/bitbucket"
+	giteaUtils "github.com/kosli-dev/cli/internal/gitea"
 	ghUtils "github.com/kosli-dev/cli/internal/github"
 	gitlabUtils "github.com/kosli-dev/cli/internal/gitlab"
 	"github.com/kosli-dev/cli/internal/requests"
@@ -98,19 +99,31 @@ type PRAttestationPayload struct {
 	*CommonAttestationPayload
 	GitProvider  string              `json:"git_provider"`
 	PullRequests []*types.PREvidence `json:"pull_requests"`
+	// Compliant is only reported when independent approval is required
+	Compliant *bool `json:"is_compliant,omitempty"`
 }
 
 type attestPROptions struct {
 	*CommonAttestationOptions
-	retriever interface{}
-	assert    bool
-	payload   PRAttestationPayload
+	retriever                  interface{}
+	assert                     bool
+	requireIndependentApproval bool
+	independentApprovers       int
+	payload                    PRAttestationPayload
 }
 
 func (o *attestPROptions) getRetriever() types.PRRetriever {
 	return o.retriever.(types.PRRetriever)
 }
 
+// validateIndependentApprovalFlags checks the independent approval flags before the git provider is queried
+func (o *attestPROptions) validateIndependentApprovalFlags() error {
+	if o.requireIndependentApproval && o.independentApprovers < 1 {
+		return fmt.Errorf("--independent-approvers must be at least 1")
+	}
+	return nil
+}
+
 func (o *attestPROptions) run(args []string) error {
 	url := fmt.Sprintf("%s/api/v2/attestations/%s/%s/trail/%s/pull_request", global.Host, global.Org, o.flowName, o.trailName)
 
@@ -119,6 +132,9 @@ func (o *attestPROptions) run(args []string) error {
 		return err
 	}
 
+	if retriever, ok := o.retriever.(types.ReviewHistoryRetriever); ok && o.requireIndependentApproval {
+		retriever.EnableReviewHistory()
+	}
 	pullRequestsEvidence, err := o.getRetriever().PREvidenceForCommit(o.payload.Commit.Sha1)
 	if err != nil {
 		return err
@@ -129,6 +145,11 @@ func (o *attestPROptions) run(args []string) error {
 	label := ""
 	o.payload.GitProvider, label = getGitProviderAndLabel(o.retriever)
 
+	if o.requireIndependentApproval {
+		compliant := o.evaluateIndependentApproval(label)
+		o.payload.Compliant = &compliant
+	}
+
 	form, cleanupNeeded, evidencePath, err := prepareAttestationForm(o.payload, o.attachments)
 	if err != nil {
 		return err
@@ -155,9 +176,24 @@ func (o *attestPROptions) run(args []string) error {
 	if len(pullRequestsEvidence) == 0 && o.assert && !global.DryRun {
 		return fmt.Errorf("assert failed: no %s found for the given commit: %s", label, o.payload.Commit.Sha1)
 	}
+	if err == nil && o.payload.Compliant != nil && !*o.payload.Compliant && o.assert && !global.DryRun {
+		return fmt.Errorf("assert failed: independent approval is not met for the given commit: %s", o.payload.Commit.Sha1)
+	}
 	return wrapAttestationError(err)
 }
 
+// evaluateIndependentApproval records the independent approval reasoning of each pull request
+// and returns whether all of them meet it. A commit without pull requests does not meet it.
+func (o *attestPROptions) evaluateIndependentApproval(label string) bool {
+	compliant := len(o.payload.PullRequests) > 0
+	for _, pr := range o.payload.PullRequests {
+		pr.IndependentApproval = pr.EvaluateIndependentApproval(o.independentApprovers)
+		compliant = compliant && pr.IndependentApproval.Compliant
+		logger.Info("independent approval of %s %s: %s", label, pr.URL, pr.IndependentApproval.Reason)
+	}
+	return compliant
+}
+
 type pullRequestCommitOptions struct {
 	pullRequestOptions
 }
@@ -228,6 +264,8 @@ func getGitProviderAndLabel(retriever interface{}) (string, string) {
 		provider = "azure"
 	case reflect.TypeOf(&bbUtils.Config{}):
 		provider = "bitbucket"
+	case reflect.TypeOf(&giteaUtils.Config{}):
+		provider = "gitea"
 	}
 	return provider, label
 }
@@ -63,6 +63,7 @@ type reportApprovalOptions struct {
 	oldestSrcCommit    string
 	newestSrcCommit    string
 	srcRepoRoot        string
+	paths              []string
 	userDataFile       string
 	payload            ApprovalPayload
 	approver           string
@@ -97,6 +98,11 @@ func newReportApprovalCmd(out io.Writer) *cobra.Command {
 				return err
 			}
 
+			err = gitview.ValidatePaths(o.paths)
+			if err != nil {
+				return err
+			}
+
 			err = ValidateArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint, false)
 			if err != nil {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
@@ -116,6 +122,7 @@ func newReportApprovalCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&o.oldestSrcCommit, "oldest-commit", "", oldestCommitFlag)
 	cmd.Flags().StringVar(&o.newestSrcCommit, "newest-commit", "HEAD", newestCommitFlag)
 	cmd.Flags().StringVar(&o.srcRepoRoot, "repo-root", ".", repoRootFlag)
+	cmd.Flags().StringSliceVar(&o.paths, "paths", []string{}, commitPathsFlag)
 	cmd.Flags().StringVar(&o.approver, "approver", "", approverFlag)
 	addFingerprintFlags(cmd, o.fingerprintOptions)
 	addDryRunFlag(cmd)
@@ -141,7 +148,7 @@ func (o *reportApprovalOptions) run(args []string, request bool) error {
 	if err != nil {
 		return err
 	}
-	gitView, err := gitview.New(o.srcRepoRoot)
+	gitView, err := newGitView(o.srcRepoRoot)
 	if err != nil {
 		return err
 	}
@@ -162,18 +169,7 @@ func (o *reportApprovalOptions) run(args []string, request bool) error {
 		}
 	} else {
 		// Request last approved git commit from kosli server
-		url := fmt.Sprintf("%s/api/v2/approvals/%s/%s/artifact-commit/%s", global.Host, global.Org,
-			o.flowName, o.payload.Environment)
-
-		getLastApprovedGitCommitParams := &requests.RequestParams{
-			Method: http.MethodGet,
-			URL:    url,
-			DryRun: false,
-			Token:  global.ApiToken,
-		}
-
-		lastApprovedGitCommitResponse, err := kosliClient.Do(getLastApprovedGitCommitParams)
-
+		lastApprovedCommit, err := lastApprovedArtifactCommit(o.flowName, o.payload.Environment)
 		if err != nil {
 			if !global.DryRun {
 				// error and not dry run -> print error message and return err
@@ -182,27 +178,17 @@ func (o *reportApprovalOptions) run(args []string, request bool) error {
 				// error and dry run -> set src_commit_list to o.newestCommit do not send oldestCommit
 				o.payload.CommitList = []string{o.newestSrcCommit}
 			}
-		} else {
-			var responseData map[string]interface{}
-			err = json.Unmarshal([]byte(lastApprovedGitCommitResponse.Body), &responseData)
+		} else if lastApprovedCommit != "" {
+			// no error we get back a git commit -> call o.payloadCommitList()
+			o.oldestSrcCommit = lastApprovedCommit
+			o.payload.OldestCommit = o.oldestSrcCommit
+			o.payload.CommitList, err = o.payloadCommitList()
 			if err != nil {
-				fmt.Println("unmarshal failed")
 				return err
 			}
-
-			if responseData["commit_sha"] != nil {
-				// no error we get back a git commit -> call o.payloadCommitList()
-				o.oldestSrcCommit = responseData["commit_sha"].(string)
-				o.payload.OldestCommit = o.oldestSrcCommit
-				o.payload.CommitList, err = o.payloadCommitList()
-				if err != nil {
-					return err
-				}
-			} else {
-				// no error we get back None -> set src_commit_list to o.newestCommit do not send oldestCommit
-				o.payload.CommitList = []string{o.newestSrcCommit}
-			}
-
+		} else {
+			// no error we get back None -> set src_commit_list to o.newestCommit do not send oldestCommit
+			o.payload.CommitList = []string{o.newestSrcCommit}
 		}
 	}
 
@@ -267,14 +253,40 @@ func (o *reportApprovalOptions) payloadCommitList() ([]string, error) {
 }
 
 func (o *reportApprovalOptions) commitsHistory() ([]*gitview.CommitInfo, error) {
-	gitView, err := gitview.New(o.srcRepoRoot)
+	gitView, err := newGitView(o.srcRepoRoot)
 	if err != nil {
 		return nil, err
 	}
 
-	commits, err := gitView.CommitsBetween(o.oldestSrcCommit, o.newestSrcCommit, logger)
+	commits, err := gitView.CommitsBetween(o.oldestSrcCommit, o.newestSrcCommit, o.paths, logger)
 	if err != nil {
 		return nil, err
 	}
 	return commits, nil
 }
+
+// lastApprovedArtifactCommit asks Kosli for the git commit of the artifact of the flow last approved
+// for deployment to the environment. It is empty when no artifact of the flow has been approved.
+func lastApprovedArtifactCommit(flowName, environment string) (string, error) {
+	url := fmt.Sprintf("%s/api/v2/approvals/%s/%s/artifact-commit/%s", global.Host, global.Org,
+		flowName, environment)
+
+	reqParams := &requests.RequestParams{
+		Method: http.MethodGet,
+		URL:    url,
+		DryRun: false,
+		Token:  global.ApiToken,
+	}
+	response, err := kosliClient.Do(reqParams)
+	if err != nil {
+		return "", err
+	}
+
+	var responseData map[string]interface{}
+	err = json.Unmarshal([]byte(response.Body), &responseData)
+	if err != nil {
+		return "", err
+	}
+	commit, _ := responseData["commit_sha"].(string)
+	return commit, nil
+}
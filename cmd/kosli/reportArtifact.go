@@ -125,7 +125,7 @@ func (o *reportArtifactOptions) run(args []string) error {
 		}
 	}
 
-	gitView, err := gitview.New(o.srcRepoRoot)
+	gitView, err := newGitView(o.srcRepoRoot)
 	if err != nil {
 		return err
 	}
@@ -138,7 +138,7 @@ func (o *reportArtifactOptions) run(args []string) error {
 
 	previousCommit, err := o.latestCommit(currentBranch(gitView))
 	if err == nil {
-		o.payload.CommitsList, err = gitView.ChangeLog(o.payload.GitCommit, previousCommit, logger)
+		o.payload.CommitsList, err = gitView.ChangeLog(o.payload.GitCommit, previousCommit, nil, logger)
 		if err != nil && !global.DryRun {
 			return err
 		}
@@ -146,7 +146,7 @@ func (o *reportArtifactOptions) run(args []string) error {
 		return err
 	}
 
-	o.payload.RepoUrl, err = gitView.RepoURL()
+	o.payload.RepoUrl, err = gitView.RepoURL(o.payload.GitCommit)
 	if err != nil {
 		logger.Warn("Repo URL will not be reported, %s", err.Error())
 	}
@@ -7,7 +7,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/kosli-dev/cli/internal/gitview"
 	"github.com/kosli-dev/cli/internal/jira"
 	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/spf13/cobra"
@@ -157,7 +156,7 @@ func (o *reportEvidenceCommitJiraOptions) run(args []string) error {
 		return err
 	}
 
-	gv, err := gitview.New(o.srcRepoRoot)
+	gv, err := newGitView(o.srcRepoRoot)
 	if err != nil {
 		return err
 	}
@@ -3,6 +3,7 @@ package main
 import (
 	"io"
 
+	"github.com/kosli-dev/cli/internal/gitview"
 	"github.com/spf13/cobra"
 )
 
@@ -68,6 +69,11 @@ func newRequestApprovalCmd(out io.Writer) *cobra.Command {
 				return err
 			}
 
+			err = gitview.ValidatePaths(o.paths)
+			if err != nil {
+				return err
+			}
+
 			err = ValidateArtifactArg(args, o.fingerprintOptions.artifactType, o.payload.ArtifactFingerprint, false)
 			if err != nil {
 				return ErrorBeforePrintingUsage(cmd, err.Error())
@@ -87,6 +93,7 @@ func newRequestApprovalCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&o.oldestSrcCommit, "oldest-commit", "", oldestCommitFlag)
 	cmd.Flags().StringVar(&o.newestSrcCommit, "newest-commit", "HEAD", newestCommitFlag)
 	cmd.Flags().StringVar(&o.srcRepoRoot, "repo-root", ".", repoRootFlag)
+	cmd.Flags().StringSliceVar(&o.paths, "paths", []string{}, commitPathsFlag)
 	addFingerprintFlags(cmd, o.fingerprintOptions)
 	addDryRunFlag(cmd)
 
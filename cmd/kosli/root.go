@@ -26,6 +26,8 @@ Setting the API token to DRY_RUN sets the --dry-run flag.
 
 const (
 	defaultMaxAPIRetries = 3
+	// The default maximum number of commits of git history fetched by --git-auto-deepen.
+	defaultGitAutoDeepenLimit = 10000
 	// The name of our config file, without the file extension because viper supports many different config file languages.
 	defaultConfigFilename = ".kosli.yml"
 
@@ -45,10 +47,16 @@ The artifact fingerprint can be provided directly with the ^--fingerprint^ flag,
 calculated based on ^--artifact-type^ flag.
 
 Artifact type can be one of: "file" for files, "dir" for directories, "oci" for container
-images in registries or "docker" for local docker images.
+images in registries, "docker" for local docker images or "git-tree" for the tree of a git commit.
 
 `
 
+	fingerprintGitTreeSynopsis = `When fingerprinting a 'git-tree' artifact, the artifact name is a git commit SHA or ref, optionally followed 
+by a colon and a path inside the repository (e.g. ^HEAD:config/prod^) to only fingerprint that subdirectory.
+The fingerprint is calculated from the committed git objects, so it does not depend on the checked out files,
+line-ending conversion or the ^.git^ directory. A clean checkout of the same tree fingerprinted as a 'dir' artifact 
+has the same fingerprint, unless the tree contains symlinks or submodules.`
+
 	attestationBindingDesc = `
 
 The attestation can be bound to a trail using the trail name.
@@ -56,6 +64,25 @@ The attestation can be bound to a trail using the trail name.
 If the attestation is for an artifact, the attestation can be bound to the artifact using one of two ways:
 - using the artifact's SHA256 fingerprint which is calculated (based on the ^--artifact-type^ flag and the artifact name/path argument) or can be provided directly (with the ^--fingerprint^ flag).
 - using the artifact's name in the flow yaml template and the git commit from which the artifact is/will be created. Useful when reporting an attestation before creating/reporting the artifact.`
+	baselineDesc = `
+
+Accepted risks can be recorded in a YAML baseline file passed with ^--baseline^. Each entry accepts findings
+with a vulnerability or rule ID, optionally only in a given package (as named in the finding message) and/or
+location (a path, a directory or a glob pattern). For example:
+
+` + "```yaml\n" + `version: 1
+accepted:
+  - id: CVE-2023-0001
+    package: libssl3
+    location: Dockerfile
+    reason: Not reachable, TLS is terminated by the load balancer
+    approver: jane@example.com
+    expires-on: 2025-12-31` + "\n```" + `
+
+Accepted findings are moved into a separate ^accepted^ section of the reported results, together with their
+acceptance. An acceptance is valid until the end of its ^expires-on^ date. Findings matched by an expired acceptance
+are kept in the results and listed under ^expired_acceptances^, so they make the attestation non-compliant again.
+The baseline file is uploaded as an attachment.`
 	awsAuthDesc = `
 
 To authenticate to AWS, you can either:  
@@ -77,7 +104,13 @@ The service principal needs to have the following permissions:
 	`
 	kosliIgnoreDesc = `To specify paths in a directory artifact that should always be excluded from the SHA256 calculation, you can add a ^.kosli_ignore^ file to the root of the artifact.
 Each line should specify a relative path or path glob to be ignored. You can include comments in this file, using ^#^.
-The ^.kosli_ignore^ will be treated as part of the artifact like any other file,unless it is explicitly ignored itself.`
+The ^.kosli_ignore^ will be treated as part of the artifact like any other file,unless it is explicitly ignored itself.
+
+By default, symlinks in a directory artifact are followed, file modes are not part of the fingerprint and empty directories 
+contribute their names. This can be changed with ^--symlinks^, ^--include-file-mode^ and ^--skip-empty-dirs^, or by declaring the options 
+in a header comment at the top of the ^.kosli_ignore^ file, so that they are applied the same way wherever the artifact is fingerprinted:
+^# kosli-fingerprint: symlinks=record-target include-file-mode=true skip-empty-dirs=true^
+Options set with flags take precedence over the ones declared in the ^.kosli_ignore^ file.`
 
 	// flags
 	apiTokenFlag                         = "The Kosli API token."
@@ -89,8 +122,11 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	dryRunFlag                           = "[optional] Run in dry-run mode. When enabled, no data is sent to Kosli and the CLI exits with 0 exit code regardless of any errors."
 	maxAPIRetryFlag                      = "[defaulted] How many times should API calls be retried when the API host is not reachable."
 	configFileFlag                       = "[optional] The Kosli config file path."
+	gitRemoteFlag                        = "[optional] The name of the git remote whose URL is reported as the repository URL and used in commit URLs. By default, it is the first of the 'upstream', 'origin' and other remotes which contains the commit, or the first of them when none contains it."
+	gitAutoDeepenFlag                    = "[optional] Fetch more git history from the remote, in increasing steps, when the commits are missing from a shallow clone (e.g. in CI). The credentials of HTTP remotes are taken from the KOSLI_GIT_USERNAME and KOSLI_GIT_PASSWORD environment variables, or over HTTPS from the GITHUB_TOKEN (for a remote on the GITHUB_SERVER_URL host) or CI_JOB_TOKEN (for a remote on the CI_SERVER_HOST), and SSH remotes use the SSH agent."
+	gitAutoDeepenLimitFlag               = "[defaulted] The maximum number of commits of git history fetched by --git-auto-deepen. 0 fetches the full history if needed."
 	debugFlag                            = "[optional] Print debug logs to stdout. A boolean flag https://docs.kosli.com/faq/#boolean-flags (default false)"
-	artifactTypeFlag                     = "The type of the artifact to calculate its SHA256 fingerprint. One of: [oci, docker, file, dir]. Only required if you want Kosli to calculate the fingerprint for you (i.e. when you don't specify '--fingerprint' on commands that allow it)."
+	artifactTypeFlag                     = "The type of the artifact to calculate its SHA256 fingerprint. One of: [oci, docker, file, dir, git-tree]. Only required if you want Kosli to calculate the fingerprint for you (i.e. when you don't specify '--fingerprint' on commands that allow it)."
 	flowNameFlag                         = "The Kosli flow name."
 	trailNameFlag                        = "The Kosli trail name."
 	trailNameFlagOptional                = "[optional] The Kosli trail name."
@@ -107,6 +143,7 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	oldestCommitFlag                     = "[conditional] The source commit sha for the oldest change in the deployment. Can be any commit-ish. Only required if you don't specify '--environment'."
 	newestCommitFlag                     = "[defaulted] The source commit sha for the newest change in the deployment. Can be any commit-ish."
 	repoRootFlag                         = "[defaulted] The directory where the source git repository is available."
+	gitTreeRepoRootFlag                  = "[defaulted] The directory where the git repository is available. Only used for --artifact-type git-tree."
 	approvalDescriptionFlag              = "[optional] The approval description."
 	deploymentDescriptionFlag            = "[optional] The deployment description."
 	evidenceDescriptionFlag              = "[optional] The evidence description."
@@ -116,6 +153,14 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	jiraPATFlag                          = "Jira personal access token (for self-hosted Jira)"
 	jiraIssueFieldFlag                   = "[optional] The comma separated list of fields to include from the Jira issue. Default no fields are included. '*all' will give all fields."
 	jiraSecondarySourceFlag              = "[optional] An optional string to search for Jira ticket reference, e.g. '--jira-secondary-source ${{ github.head_ref }}'"
+	jiraRequireStatusFlag                = "[optional] The comma-separated list of statuses a referenced Jira issue must be in to be compliant, e.g. 'Approved for release'."
+	jiraJqFlag                           = "[optional] A jq rule over the fields of a referenced Jira issue which must evaluate to true for the issue to be compliant. The flag can be repeated in order to add additional rules."
+	issueSinceCommitFlag                 = "[optional] The commit after which the messages of all the commits up to --commit are searched for issue references. Commits without issue references are reported. Cannot be used together with --commit-range."
+	issueCommitRangeFlag                 = "[optional] The range of commits, of the form OLDEST..NEWEST, whose messages are searched for issue references. The OLDEST commit is excluded. Commits without issue references are reported. Cannot be used together with --since-commit."
+	issueTrackerFlag                     = "The issue tracker to look up the issue references in. Valid values are 'jira', 'github', 'gitlab', 'linear' and 'azure-boards'."
+	issueSecondarySourceFlag             = "[optional] An optional string to search for issue references, e.g. '--secondary-source ${{ github.head_ref }}'"
+	linearAPIKeyFlag                     = "Linear API key (or an OAuth access token prefixed with 'Bearer ')."
+	linearBaseURLFlag                    = "[optional] Linear API base URL (defaults to https://api.linear.app)."
 	ignoreBranchMatchFlag                = "Ignore branch name when searching for Jira ticket reference."
 	envDescriptionFlag                   = "[optional] The environment description."
 	flowDescriptionFlag                  = "[optional] The Kosli flow description."
@@ -138,11 +183,14 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	bbUsernameFlag                       = "Bitbucket username. Only needed if you use --bitbucket-password"
 	bbPasswordFlag                       = "Bitbucket App password. See https://developer.atlassian.com/cloud/bitbucket/rest/intro/#authentication for more details."
 	bbAccessTokenFlag                    = "Bitbucket repo/project/workspace access token. See https://developer.atlassian.com/cloud/bitbucket/rest/intro/#access-tokens for more details."
-	bbWorkspaceFlag                      = "Bitbucket workspace ID."
+	bbWorkspaceFlag                      = "Bitbucket workspace ID. For Bitbucket Data Center, the project key."
+	bbBaseURLFlag                        = "[optional] Bitbucket Data Center or Server base URL, e.g. https://bitbucket.example.com (only needed for self-hosted Bitbucket installations). Use --bitbucket-access-token with an HTTP access token to authenticate."
 	commitPREvidenceFlag                 = "Git commit for which to find pull request evidence. (defaulted in some CIs: https://docs.kosli.com/ci-defaults )."
 	commitEvidenceFlag                   = "Git commit for which to verify a given evidence. (defaulted in some CIs: https://docs.kosli.com/ci-defaults )."
 	repositoryFlag                       = "Git repository. (defaulted in some CIs: https://docs.kosli.com/ci-defaults )."
 	assertPREvidenceFlag                 = "[optional] Exit with non-zero code if no pull requests found for the given commit."
+	requireIndependentApprovalFlag       = "[optional] Mark the attestation as non-compliant unless every pull request for the commit has enough independent approvals: approvals given after the last commit by reviewers who are neither the pull request author nor a commit author. An approval only counts as after the last commit when the git provider records the commit it was given on, or when the last commit was pushed; commit dates are not trusted. It is not met when a commit author cannot be matched to a git provider account. With --assert, exit with non-zero code when it is not met."
+	independentApproversFlag             = "[defaulted] The number of independent approvals each pull request needs when --require-independent-approval is set."
 	assertJiraEvidenceFlag               = "[optional] Exit with non-zero code if no jira issue reference found, or jira issue does not exist, for the given commit or branch."
 	assertStatusFlag                     = "[optional] Exit with non-zero code if Kosli server is not responding."
 	azureTokenFlag                       = "Azure Personal Access token."
@@ -160,6 +208,9 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	gitlabTokenFlag                      = "Gitlab token."
 	gitlabOrgFlag                        = "Gitlab organization. (defaulted if you are running in Gitlab Pipelines: https://docs.kosli.com/ci-defaults )."
 	gitlabBaseURLFlag                    = "[optional] Gitlab base URL (only needed for on-prem Gitlab installations)."
+	giteaTokenFlag                       = "Gitea or Forgejo access token."
+	giteaOrgFlag                         = "Gitea or Forgejo organization or user owning the repository. (defaulted if you are running in Gitea or Forgejo Actions: https://docs.kosli.com/ci-defaults )."
+	giteaBaseURLFlag                     = "Gitea or Forgejo base URL, e.g. https://codeberg.org (defaulted if you are running in Gitea or Forgejo Actions: https://docs.kosli.com/ci-defaults )."
 	registryProviderFlag                 = "[deprecated] The docker registry provider or url. Only required if you want to read docker image SHA256 digest from a remote docker registry."
 	registryUsernameFlag                 = "[conditional] The container registry username. Only required if you want to read container image SHA256 digest from a remote container registry."
 	registryPasswordFlag                 = "[conditional] The container registry password or access token. Only required if you want to read container image SHA256 digest from a remote container registry."
@@ -191,6 +242,9 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	excludeBucketPathsFlag               = "[optional] The comma separated list of file and/or directory paths in the S3 bucket to exclude when fingerprinting. Cannot be used together with --include."
 	pathsFlag                            = "The comma separated list of absolute or relative paths of artifact directories or files. Can take glob patterns, but be aware that each matching path will be reported as an artifact."
 	excludePathsFlag                     = "[optional] The comma separated list of directories and files to exclude from fingerprinting. Can take glob patterns. Only applicable for --artifact-type dir."
+	symlinksFlag                         = "[optional] How symlinks in a directory artifact are fingerprinted. One of: [follow, record-target, skip]. Defaults to follow. Only applicable for directory artifacts."
+	includeFileModeFlag                  = "[optional] Include the executable bit of files in the fingerprint. Only applicable for directory artifacts."
+	skipEmptyDirsFlag                    = "[optional] Leave empty directories out of the fingerprint. Only applicable for directory artifacts."
 	serverExcludePathsFlag               = "[optional] The comma separated list of directories and files to exclude from fingerprinting. Can take glob patterns."
 	shortFlag                            = "[optional] Print only the Kosli CLI version number."
 	reverseFlag                          = "[defaulted] Reverse the order of output list."
@@ -214,6 +268,47 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	uploadJunitResultsFlag               = "[defaulted] Whether to upload the provided Junit results directory as an attachment to Kosli or not."
 	uploadSnykResultsFlag                = "[defaulted] Whether to upload the provided Snyk results file as an attachment to Kosli or not."
 	attestationAssertFlag                = "[optional] Exit with non-zero code if the attestation is non-compliant"
+	sarifResultsFileFlag                 = "The path to a SARIF scan results file. Can be repeated or comma-separated to combine results from several files. By default, the SARIF files will be uploaded to Kosli's evidence vault."
+	uploadSarifResultsFlag               = "[defaulted] Whether to upload the provided SARIF results files as attachments to Kosli or not."
+	sarifFailOnFlag                      = "[defaulted] The lowest severity of a finding that makes the attestation non-compliant. One of [critical, high, medium, low, info, none]. 'none' never fails."
+	baselineFileFlag                     = "[optional] The path to a YAML baseline file of accepted risks. Findings it accepts are reported in a separate 'accepted' section. The baseline file is uploaded as an attachment."
+	sbomFileFlag                         = "The path to a CycloneDX (JSON) or SPDX (JSON or tag-value) SBOM file. The SBOM file is uploaded as an attachment."
+	allowedLicensesFlag                  = "[optional] The comma-separated list of allowed SPDX license IDs. When set, components with any other or an unknown license make the attestation non-compliant."
+	deniedLicensesFlag                   = "[optional] The comma-separated list of denied SPDX license IDs. Components that can only be used under a denied license make the attestation non-compliant."
+	provenanceFileFlag                   = "The path to an in-toto provenance statement, a DSSE envelope or a sigstore bundle containing one. JSON Lines files (e.g. .intoto.jsonl) are supported. The provenance file is uploaded as an attachment."
+	expectedBuilderFlag                  = "[optional] The expected builder ID of the provenance. Without an '@<ref>' suffix, any ref of the builder is accepted."
+	expectedSourceRepoFlag               = "[optional] The expected source repository of the provenance, e.g. https://github.com/YourOrg/YourRepo"
+	signatureFileFlag                    = "The path to a detached signature (base64 or binary) over the artifact fingerprint, or to a DSSE envelope. The signature file is uploaded as an attachment."
+	signaturePublicKeyFlag               = "[conditional] The path to a PEM encoded ECDSA (P-256) or ed25519 public key to verify the signature with. Only one of --public-key or --trust-bundle is required."
+	signatureTrustBundleFlag             = "[conditional] The path to a PEM file of public keys and/or certificates to verify the signature with. Only one of --public-key or --trust-bundle is required."
+	commitSignaturesGPGKeyRingFlag       = "[conditional] The path to an armored or binary keyring of the trusted GPG public keys, e.g. exported with 'gpg --export --armor'. At least one of --gpg-keyring and --allowed-signers is required."
+	commitSignaturesAllowedSignersFlag   = "[conditional] The path to an SSH allowed signers file of the trusted SSH public keys, in the format of git's gpg.ssh.allowedSignersFile. At least one of --gpg-keyring and --allowed-signers is required."
+	commitSignaturesSinceCommitFlag      = "[optional] The commit after which the signatures of all the commits up to --commit are verified. Cannot be used together with --commit-range."
+	commitSignaturesCommitRangeFlag      = "[optional] The range of commits, of the form OLDEST..NEWEST, whose signatures are verified. The OLDEST commit is excluded. Cannot be used together with --since-commit."
+	changelogEnvironmentFlag             = "[conditional] The name of the Kosli environment whose running artifact of the flow is where the changelog starts. The changelog includes the commits after the commit of that artifact in the latest snapshot of the environment, up to --commit. Only one of --environment and --oldest-commit is required."
+	changelogOldestCommitFlag            = "[conditional] The commit after which the changelog starts, up to --commit. Only one of --environment and --oldest-commit is required."
+	commitPathsFlag                      = "[optional] The comma-separated paths, relative to the repository root, to which the commits are scoped, e.g. services/a/**,libs/common/**. Only the commits changing files matching one of the paths are included, following renames. A path matches the files in its directories and can use the * and ? wildcards, and ** for any number of directories."
+	issuePathsFlag                       = "[optional] With --since-commit or --commit-range, the comma-separated paths, relative to the repository root, to which the range of commits is scoped, e.g. services/a/**,libs/common/**. Only the commits changing files matching one of the paths are searched, following renames."
+	changelogIssuePatternFlag            = "[defaulted] The regular expression matching the issue references in the commit messages. Defaults to Jira issue keys."
+	coverageReportFlag                   = "The path to the coverage report file."
+	coverageFormatFlag                   = "The format of the coverage report. One of [cobertura, lcov, jacoco, go]."
+	coverageMinLineFlag                  = "[optional] The minimum line coverage percentage (0-100) for the attestation to be compliant."
+	coverageMinBranchFlag                = "[optional] The minimum branch coverage percentage (0-100) for the attestation to be compliant."
+	coverageBaselineReportFlag           = "[optional] The path to a baseline coverage report of the same format (e.g. from the main branch) to compare the coverage against."
+	coverageMaxDropFlag                  = "[defaulted] The maximum allowed drop of the overall line and branch coverage compared to the --baseline-report, in percentage points."
+	uploadCoverageReportFlag             = "[defaulted] Whether to upload the provided coverage report (and baseline report) as attachments to Kosli or not."
+	testResultsFormatFlag                = "The format of the test results. One of [junit, trx, tap, go-json, cucumber]."
+	testResultsPathsFlag                 = "[defaulted] The paths of test results files or directories. Directories are searched recursively for files of the --format (.xml, .trx, .tap, .json/.jsonl or .json). Can be repeated."
+	maxFailuresFlag                      = "[defaulted] The maximum number of failed (or errored) tests for the attestation to be compliant."
+	maxSkippedFlag                       = "[optional] The maximum number of skipped tests for the attestation to be compliant. Skipped tests are not checked when not set."
+	uploadTestResultsFlag                = "[defaulted] Whether to upload the parsed test results files as attachments to Kosli or not."
+	secretsReportFlag                    = "The path to the secrets scanner JSON report."
+	secretsFormatFlag                    = "The format of the secrets report. One of [gitleaks, trufflehog]."
+	secretsAllowlistFlag                 = "[optional] The path to a file of allowlisted finding fingerprints, one per line (as in a .gitleaksignore file). The allowlist is uploaded as an attachment."
+	uploadSecretsReportFlag              = "[defaulted] Whether to upload the redacted secrets report as an attachment to Kosli or not."
+	attestationTypeTestDataFlag          = "The filepath of a json file containing the attestation data to test."
+	attestationTypeTestSchemaFlag        = "[optional] Path to a JSON Schema file to test the data against. If neither --schema nor --jq is given, the schema and rules of the attestation type are fetched from Kosli."
+	attestationTypeTestJqFlag            = "[optional] A jq evaluation rule to test the data against. The flag can be repeated in order to add additional rules."
 	beginTrailCommitFlag                 = "[defaulted] The git commit from which the trail is begun. (defaulted in some CIs: https://docs.kosli.com/ci-defaults, otherwise defaults to HEAD )."
 	attachmentsFlag                      = "[optional] The comma-separated list of paths of attachments for the reported attestation. Attachments can be files or directories. All attachments are compressed and uploaded to Kosli's evidence vault."
 	externalFingerprintFlag              = "[optional] A SHA256 fingerprint of an external attachment represented by --external-url. The format is label=fingerprint (labels cannot contain '.' or '='). This flag can be set multiple times. There must be an external url with a matching label for each external fingerprint."
@@ -241,6 +336,11 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 	sonarProjectKeyFlag                  = "[conditional] The project key of the SonarCloud/SonarQube project. Only required if you want to use the project key/revision to get the scan results rather than using Sonar's metadata file."
 	sonarServerURLFlag                   = "[conditional] The URL of your SonarQube server. Only required if you are using SonarQube and not using SonarQube's metadata file to get scan results."
 	sonarRevisionFlag                    = "[conditional] The revision of the SonarCloud/SonarQube project. Only required if you want to use the project key/revision to get the scan results rather than using Sonar's metadata file and you have overridden the default revision, or you aren't using a CI. Defaults to the value of the git commit flag."
+	sonarBranchFlag                      = "[optional] The branch of the SonarCloud/SonarQube project to get the scan results of, when using the project key/revision. Defaults to the main branch. Cannot be used together with --sonar-pull-request."
+	sonarPullRequestFlag                 = "[optional] The key of the pull request of the SonarCloud/SonarQube project to get the scan results of, when using the project key/revision. Cannot be used together with --sonar-branch."
+	sonarMetricThresholdFlag             = "[optional] A threshold on a SonarQube metric which the scan results must meet to be compliant, in the form METRIC OPERATOR VALUE (e.g. 'new_coverage>=80' or 'new_vulnerabilities==0'). OPERATOR is one of >=, <=, ==, !=, > or <. Can be repeated."
+	sonarWaitFlag                        = "[optional] Wait for SonarQube to complete the analysis of the scan, instead of failing when it is not complete yet."
+	sonarTimeoutFlag                     = "[defaulted] The maximum time to wait for SonarQube to complete the analysis when using --wait, e.g. '30s' or '10m'."
 	logicalEnvFlag                       = "[required] The logical environment."
 	physicalEnvFlag                      = "[required] The physical environment."
 	attestationTypeDescriptionFlag       = "[optional] The attestation type description."
@@ -253,14 +353,17 @@ The ^.kosli_ignore^ will be treated as part of the artifact like any other file,
 var global *GlobalOpts
 
 type GlobalOpts struct {
-	ApiToken      string
-	Org           string
-	Host          string
-	HttpProxy     string
-	DryRun        bool
-	MaxAPIRetries int
-	ConfigFile    string
-	Debug         bool
+	ApiToken           string
+	Org                string
+	Host               string
+	HttpProxy          string
+	DryRun             bool
+	MaxAPIRetries      int
+	ConfigFile         string
+	Debug              bool
+	GitRemote          string
+	GitAutoDeepen      bool
+	GitAutoDeepenLimit int
 }
 
 // ConfigGetter defines an interface for getting the default config file path
@@ -343,6 +446,9 @@ func newRootCmd(out, errOut io.Writer, args []string) (*cobra.Command, error) {
 	cmd.PersistentFlags().IntVarP(&global.MaxAPIRetries, "max-api-retries", "r", defaultMaxAPIRetries, maxAPIRetryFlag)
 	cmd.PersistentFlags().StringVarP(&global.ConfigFile, "config-file", "c", getConfigFileFlagDefault(), configFileFlag)
 	cmd.PersistentFlags().BoolVar(&global.Debug, "debug", false, debugFlag)
+	cmd.PersistentFlags().StringVar(&global.GitRemote, "git-remote", "", gitRemoteFlag)
+	cmd.PersistentFlags().BoolVar(&global.GitAutoDeepen, "git-auto-deepen", false, gitAutoDeepenFlag)
+	cmd.PersistentFlags().IntVar(&global.GitAutoDeepenLimit, "git-auto-deepen-limit", defaultGitAutoDeepenLimit, gitAutoDeepenLimitFlag)
 
 	// Add subcommands
 	cmd.AddCommand(
@@ -376,6 +482,7 @@ func newRootCmd(out, errOut io.Writer, args []string) (*cobra.Command, error) {
 		newEnableCmd(out),
 		newTagCmd(out),
 		newConfigCmd(out),
+		newAttestationTypeCmd(out),
 		newAttachPolicyCmd(out),
 		newDetachPolicyCmd(out),
 	)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/rjeczalik/notify"
 
+	"github.com/kosli-dev/cli/internal/digest"
 	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/kosli-dev/cli/internal/server"
 	"github.com/spf13/cobra"
@@ -46,6 +47,7 @@ type snapshotPathOptions struct {
 	path         string
 	artifactName string
 	exclude      []string
+	dirOptions   digest.DirFingerprintOptions
 	watch        bool
 }
 
@@ -73,6 +75,7 @@ func newSnapshotPathCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringVar(&o.path, "path", "", snapshotPathPathFlag)
 	cmd.Flags().StringVar(&o.artifactName, "name", "", snapshotPathArtifactNameFlag)
 	cmd.Flags().StringSliceVarP(&o.exclude, "exclude", "x", []string{}, snapshotPathExcludeFlag)
+	addDirFingerprintFlags(cmd, &o.dirOptions)
 	cmd.Flags().BoolVar(&o.watch, "watch", false, pathsWatchFlag)
 	addDryRunFlag(cmd)
 
@@ -90,8 +93,9 @@ func (o *snapshotPathOptions) run(args []string) error {
 		Version: 1,
 		Artifacts: map[string]server.ArtifactPathSpec{
 			o.artifactName: {
-				Path:    o.path,
-				Exclude: o.exclude,
+				Path:                  o.path,
+				Exclude:               o.exclude,
+				DirFingerprintOptions: o.dirOptions,
 			},
 		},
 	}
@@ -19,6 +19,9 @@ const pathSpecFileDesc = `Paths files can be in YAML, JSON or TOML formats.
 They specify a list of artifacts to fingerprint. For each artifact, the file specifies a base path to look for the artifact in 
 and (optionally) a list of paths to exclude. Excluded paths are relative to the artifact path(s) and can be literal paths or
 glob patterns.  
+For directory artifacts, the file can also specify how symlinks are fingerprinted (^symlinks: follow|record-target|skip^), 
+whether the files executable bit is included (^include-file-mode: true^) and whether empty directories are skipped 
+(^skip-empty-dirs: true^).  
 The supported glob pattern syntax is what is documented here: https://pkg.go.dev/path/filepath#Match , 
 plus the ability to use recursive globs "**"
 
@@ -31,7 +34,11 @@ This is an example YAML paths spec file:
 artifacts:
   artifact_name_a:
     path: dir1
-    exclude: [subdir1, **/log]` +
+    exclude: [subdir1, **/log]
+  artifact_name_b:
+    path: dir2
+    symlinks: record-target
+    include-file-mode: true` +
 	"\n```"
 
 const snapshotPathsLongDesc = snapshotPathsShortDesc + `
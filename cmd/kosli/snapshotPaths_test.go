@@ -48,6 +48,12 @@ func (suite *SnapshotPathsTestSuite) TestSnapshotPathsCmd() {
 			cmd:       fmt.Sprintf(`snapshot paths --paths-file testdata/paths-files/invalid-values-pathsfile.yml %s %s`, suite.envName, suite.defaultKosliArguments),
 			golden:    "Error: path spec file [testdata/paths-files/invalid-values-pathsfile.yml] is invalid: Key: 'PathsSpec.Version' Error:Field validation for 'Version' failed on the 'oneof' tag\n",
 		},
+		{
+			wantError: true,
+			name:      "fails when paths spec file has an invalid symlinks mode",
+			cmd:       fmt.Sprintf(`snapshot paths --paths-file testdata/paths-files/invalid-symlinks-pathsfile.yml %s %s`, suite.envName, suite.defaultKosliArguments),
+			golden:    "Error: path spec file [testdata/paths-files/invalid-symlinks-pathsfile.yml] is invalid: Key: 'PathsSpec.Artifacts[differ].DirFingerprintOptions.Symlinks' Error:Field validation for 'Symlinks' failed on the 'oneof' tag\n",
+		},
 		{
 			name:   "can report artifact data with YAML path spec file",
 			cmd:    fmt.Sprintf(`snapshot paths --paths-file testdata/paths-files/valid-pathsfile.yml %s %s`, suite.envName, suite.defaultKosliArguments),
@@ -16,7 +16,11 @@ const snapshotS3LongDesc = snapshotS3ShortDesc + awsAuthDesc + `
 You can report the entire bucket content, or filter some of the content using ^--include^ and ^--exclude^.
 In all cases, the content is reported as one artifact. If you wish to report separate files/dirs within the same bucket as separate artifacts, you need to run the command twice.
 
-` + kosliIgnoreDesc
+` + kosliIgnoreDesc + `
+
+For S3 buckets, the fingerprint options are only taken from the header of a ^.kosli_ignore^ object at the root of the bucket 
+(this command has no flags for them). Objects are downloaded as regular files, so a bucket has no symlinks, executable bits or empty directories.
+`
 
 const snapshotS3Example = `
 # report the contents of an entire AWS S3 bucket (AWS auth provided in env variables):
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/kosli-dev/cli/internal/digest"
 	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/kosli-dev/cli/internal/server"
 	"github.com/spf13/cobra"
@@ -44,6 +45,7 @@ kosli snapshot server yourEnvironmentName \
 type snapshotServerOptions struct {
 	paths        []string
 	excludePaths []string
+	dirOptions   digest.DirFingerprintOptions
 }
 
 func newSnapshotServerCmd(out io.Writer) *cobra.Command {
@@ -76,6 +78,7 @@ func newSnapshotServerCmd(out io.Writer) *cobra.Command {
 	cmd.Flags().StringSliceVarP(&o.paths, "paths", "p", []string{}, pathsFlag)
 	cmd.Flags().StringSliceVarP(&o.excludePaths, "exclude", "x", []string{}, serverExcludePathsFlag)
 	cmd.Flags().StringSliceVarP(&o.excludePaths, "e", "e", []string{}, serverExcludePathsFlag)
+	addDirFingerprintFlags(cmd, &o.dirOptions)
 	addDryRunFlag(cmd)
 
 	err := DeprecateFlags(cmd, map[string]string{
@@ -98,7 +101,7 @@ func (o *snapshotServerOptions) run(args []string) error {
 
 	url := fmt.Sprintf("%s/api/v2/environments/%s/%s/report/server", global.Host, global.Org, envName)
 
-	artifacts, err := server.CreateServerArtifactsData(o.paths, o.excludePaths, logger)
+	artifacts, err := server.CreateServerArtifactsData(o.paths, o.excludePaths, o.dirOptions, logger)
 	if err != nil {
 		return err
 	}
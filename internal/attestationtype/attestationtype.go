@@ -0,0 +1,274 @@
+package attestationtype
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/itchyny/gojq"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// schemaURL is the in-memory location the schema is registered under when compiling it
+const schemaURL = "attestation-type.schema.json"
+
+var printer = message.NewPrinter(language.English)
+
+// Definition is the part of a custom attestation type used to evaluate attestation data
+type Definition struct {
+	Name    string
+	Version int
+	// Schema is a decoded JSON schema, nil when the type has no schema
+	Schema interface{}
+	// Rules are jq expressions which must all evaluate to true
+	Rules []string
+}
+
+// RuleResult is the outcome of evaluating one jq rule
+type RuleResult struct {
+	Rule   string `json:"rule"`
+	Passed bool   `json:"passed"`
+	// Output is the first value the rule produced, or the error it failed with
+	Output string `json:"output,omitempty"`
+}
+
+// Result is the outcome of evaluating attestation data against a Definition
+type Result struct {
+	SchemaErrors []string     `json:"schema_errors,omitempty"`
+	Rules        []RuleResult `json:"rules,omitempty"`
+}
+
+// SchemaPassed is true when the data is valid against the schema, or there is no schema
+func (r *Result) SchemaPassed() bool {
+	return len(r.SchemaErrors) == 0
+}
+
+// RulesPassed is true when every jq rule evaluated to true
+func (r *Result) RulesPassed() bool {
+	for _, rule := range r.Rules {
+		if !rule.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Passed is true when both the schema and all the rules passed
+func (r *Result) Passed() bool {
+	return r.SchemaPassed() && r.RulesPassed()
+}
+
+// Evaluate validates data against the schema of def and evaluates its jq rules.
+// data must be decoded with encoding/json (numbers as float64).
+// An error is returned when the schema or a rule cannot be compiled.
+func Evaluate(def *Definition, data interface{}) (*Result, error) {
+	result := &Result{}
+	if def.Schema != nil {
+		schemaErrors, err := ValidateSchema(def.Schema, data)
+		if err != nil {
+			return nil, err
+		}
+		result.SchemaErrors = schemaErrors
+	}
+	rules, err := EvaluateRules(def.Rules, data)
+	if err != nil {
+		return nil, err
+	}
+	result.Rules = rules
+	return result, nil
+}
+
+// ValidateSchema returns one message per violation of schema by data
+func ValidateSchema(schema interface{}, data interface{}) ([]string, error) {
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	// round-trip the data so that numbers are json.Number, as the validator expects
+	instance, err := roundTrip(data)
+	if err != nil {
+		return nil, err
+	}
+	err = compiled.Validate(instance)
+	if err == nil {
+		return nil, nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return nil, err
+	}
+	messages := []string{}
+	collectViolations(validationErr, &messages)
+	sort.Strings(messages)
+	return messages, nil
+}
+
+func compileSchema(schema interface{}) (*jsonschema.Schema, error) {
+	doc, err := roundTrip(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(schemaURL, doc); err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %v", err)
+	}
+	return compiled, nil
+}
+
+func roundTrip(value interface{}) (interface{}, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return jsonschema.UnmarshalJSON(bytes.NewReader(content))
+}
+
+// collectViolations flattens the tree of validation errors into its leaves
+func collectViolations(err *jsonschema.ValidationError, messages *[]string) {
+	if len(err.Causes) == 0 {
+		location := "/" + strings.Join(err.InstanceLocation, "/")
+		*messages = append(*messages, fmt.Sprintf("at '%s': %s", location, err.ErrorKind.LocalizedString(printer)))
+		return
+	}
+	for _, cause := range err.Causes {
+		collectViolations(cause, messages)
+	}
+}
+
+// EvaluateRules runs each jq rule against data. A rule passes when its first output is true.
+func EvaluateRules(rules []string, data interface{}) ([]RuleResult, error) {
+	results := []RuleResult{}
+	for _, rule := range rules {
+		query, err := gojq.Parse(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jq rule '%s': %v", rule, err)
+		}
+		code, err := gojq.Compile(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jq rule '%s': %v", rule, err)
+		}
+		results = append(results, runRule(rule, code, data))
+	}
+	return results, nil
+}
+
+func runRule(rule string, code *gojq.Code, data interface{}) RuleResult {
+	result := RuleResult{Rule: rule}
+	value, ok := code.Run(data).Next()
+	if !ok {
+		result.Output = "no output"
+		return result
+	}
+	if err, isErr := value.(error); isErr {
+		result.Output = err.Error()
+		return result
+	}
+	result.Passed = value == true
+	if !result.Passed {
+		output, err := gojq.Marshal(value)
+		if err == nil {
+			result.Output = string(output)
+		}
+	}
+	return result
+}
+
+// ParseSchema decodes a type schema as returned by Kosli. The schema can be
+// a decoded JSON object, a JSON string, or a string holding a Python dict literal.
+// An empty string or nil means the type has no schema.
+func ParseSchema(raw interface{}) (interface{}, error) {
+	switch schema := raw.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}, bool:
+		return schema, nil
+	case string:
+		if strings.TrimSpace(schema) == "" {
+			return nil, nil
+		}
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(schema), &decoded); err == nil {
+			return decoded, nil
+		}
+		if err := json.Unmarshal([]byte(pythonLiteralToJSON(schema)), &decoded); err != nil {
+			return nil, fmt.Errorf("schema is neither JSON nor a Python literal: %v", err)
+		}
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("unsupported schema type %T", raw)
+	}
+}
+
+// pythonLiteralToJSON converts the repr of a Python dict (single quoted strings,
+// True/False/None) to JSON
+func pythonLiteralToJSON(literal string) string {
+	var sb strings.Builder
+	runes := []rune(literal)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\'' || r == '"':
+			end := readPythonString(runes, i, &sb)
+			i = end
+		case isIdentStart(r):
+			j := i
+			for j < len(runes) && (isIdentStart(runes[j]) || (runes[j] >= '0' && runes[j] <= '9')) {
+				j++
+			}
+			word := string(runes[i:j])
+			switch word {
+			case "True":
+				word = "true"
+			case "False":
+				word = "false"
+			case "None":
+				word = "null"
+			}
+			sb.WriteString(word)
+			i = j - 1
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// readPythonString writes the Python string starting at runes[start] as a JSON
+// string and returns the index of its closing quote
+func readPythonString(runes []rune, start int, sb *strings.Builder) int {
+	quote := runes[start]
+	var value strings.Builder
+	i := start + 1
+	for ; i < len(runes) && runes[i] != quote; i++ {
+		if runes[i] == '\\' && i+1 < len(runes) {
+			i++
+			switch runes[i] {
+			case 'n':
+				value.WriteRune('\n')
+			case 't':
+				value.WriteRune('\t')
+			case 'r':
+				value.WriteRune('\r')
+			default:
+				value.WriteRune(runes[i])
+			}
+			continue
+		}
+		value.WriteRune(runes[i])
+	}
+	encoded, _ := json.Marshal(value.String())
+	sb.Write(encoded)
+	return i
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
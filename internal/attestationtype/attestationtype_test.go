@@ -0,0 +1,185 @@
+package attestationtype
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const personSchema = `{
+	"type": "object",
+	"properties": {
+		"name": {"type": "string"},
+		"age": {"type": "integer", "minimum": 0}
+	},
+	"required": ["name", "age"]
+}`
+
+func decode(t *testing.T, content string) interface{} {
+	var value interface{}
+	require.NoError(t, json.Unmarshal([]byte(content), &value))
+	return value
+}
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{
+			name: "nil means no schema",
+			raw:  nil,
+			want: nil,
+		},
+		{
+			name: "empty string means no schema",
+			raw:  "  ",
+			want: nil,
+		},
+		{
+			name: "decoded object is returned as is",
+			raw:  map[string]interface{}{"type": "object"},
+			want: map[string]interface{}{"type": "object"},
+		},
+		{
+			name: "JSON string is decoded",
+			raw:  `{"type": "object", "additionalProperties": false}`,
+			want: map[string]interface{}{"type": "object", "additionalProperties": false},
+		},
+		{
+			name: "Python literal is decoded",
+			raw:  `{'type': 'object', 'additionalProperties': True, 'properties': {'name': {'type': ['string', None], 'description': "it's a name"}}}`,
+			want: map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": true,
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        []interface{}{"string", nil},
+						"description": "it's a name",
+					},
+				},
+			},
+		},
+		{
+			name: "Python keywords inside strings are kept",
+			raw:  `{'description': 'True or False', 'pattern': '^a\'b$'}`,
+			want: map[string]interface{}{"description": "True or False", "pattern": "^a'b$"},
+		},
+		{
+			name:    "invalid schema string fails",
+			raw:     `{'type': `,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported value fails",
+			raw:     42,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSchema(tt.raw)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	schema := decode(t, personSchema)
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "valid data has no violations",
+			data: `{"name": "Alice", "age": 30}`,
+			want: nil,
+		},
+		{
+			name: "each violation is reported with its location",
+			data: `{"name": 7, "age": -1}`,
+			want: []string{
+				"at '/age': minimum: got -1, want 0",
+				"at '/name': got number, want string",
+			},
+		},
+		{
+			name: "missing properties are reported at the root",
+			data: `{"name": "Bob"}`,
+			want: []string{"at '/': missing property 'age'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateSchema(schema, decode(t, tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ValidateSchema(map[string]interface{}{"type": 12}, decode(t, `{}`))
+	require.ErrorContains(t, err, "invalid schema")
+}
+
+func TestEvaluateRules(t *testing.T) {
+	data := decode(t, `{"name": "Alice", "age": 30, "tests": {"failed": 2}}`)
+
+	results, err := EvaluateRules([]string{
+		".age >= 18",
+		".tests.failed == 0",
+		".name",
+		".missing.field | length > 0",
+		"error(\"boom\")",
+		"empty",
+	}, data)
+	require.NoError(t, err)
+	assert.Equal(t, []RuleResult{
+		{Rule: ".age >= 18", Passed: true},
+		{Rule: ".tests.failed == 0", Output: "false"},
+		{Rule: ".name", Output: `"Alice"`},
+		{Rule: ".missing.field | length > 0", Output: "false"},
+		{Rule: "error(\"boom\")", Output: "error: boom"},
+		{Rule: "empty", Output: "no output"},
+	}, results)
+
+	_, err = EvaluateRules([]string{".age >="}, data)
+	require.ErrorContains(t, err, "invalid jq rule '.age >='")
+}
+
+func TestEvaluate(t *testing.T) {
+	def := &Definition{
+		Name:   "person",
+		Schema: decode(t, personSchema),
+		Rules:  []string{".age >= 18"},
+	}
+
+	result, err := Evaluate(def, decode(t, `{"name": "Alice", "age": 30}`))
+	require.NoError(t, err)
+	assert.True(t, result.Passed())
+
+	result, err = Evaluate(def, decode(t, `{"name": "Alice", "age": 12}`))
+	require.NoError(t, err)
+	assert.True(t, result.SchemaPassed())
+	assert.False(t, result.RulesPassed())
+	assert.False(t, result.Passed())
+
+	result, err = Evaluate(def, decode(t, `{"age": 30}`))
+	require.NoError(t, err)
+	assert.False(t, result.SchemaPassed())
+	assert.True(t, result.RulesPassed())
+
+	result, err = Evaluate(&Definition{Name: "empty"}, decode(t, `{}`))
+	require.NoError(t, err)
+	assert.True(t, result.Passed())
+	assert.Empty(t, result.Rules)
+}
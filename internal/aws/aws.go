@@ -388,7 +388,8 @@ func (staticCreds *AWSStaticCreds) GetS3Data(bucket string, includePaths, exclud
 		}
 		artifactName = filepath.Base(artifactPath)
 	} else {
-		sha256, err = digest.DirSha256(tempDirName, []string{}, logger)
+		// no options are set, so the ones declared in the header of a .kosli_ignore object at the root of the bucket apply
+		sha256, err = digest.DirSha256(tempDirName, []string{}, digest.DirFingerprintOptions{}, logger)
 		if err != nil {
 			return s3Data, err
 		}
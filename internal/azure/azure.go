@@ -2,10 +2,12 @@ package azure
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
 
+	"github.com/kosli-dev/cli/internal/requests"
 	"github.com/kosli-dev/cli/internal/types"
 	"github.com/microsoft/azure-devops-go-api/azuredevops"
 	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
@@ -16,6 +18,8 @@ type AzureConfig struct {
 	OrgURL     string
 	Repository string
 	Project    string
+	// KosliClient is only used to look up Azure Boards work items
+	KosliClient *requests.Client
 }
 
 type AzureFlagsTempValueHolder struct {
@@ -76,17 +80,219 @@ func (c *AzureConfig) newPRAzureEvidence(pr git.GitPullRequest) (*types.PREviden
 	if err != nil {
 		return nil, err
 	}
-	evidence := &types.PREvidence{
-		URL:         url,
-		MergeCommit: *(pr.LastMergeCommit.CommitId),
-		State:       string(*pr.Status),
+	ctx := context.Background()
+	client, err := NewAzureClientFromToken(ctx, c.Token, c.OrgURL)
+	if err != nil {
+		return nil, err
+	}
+	reviewers, err := client.GetPullRequestReviewers(ctx, git.GetPullRequestReviewersArgs{
+		RepositoryId:  &c.Repository,
+		PullRequestId: pr.PullRequestId,
+		Project:       &c.Project,
+	})
+	if err != nil {
+		return nil, err
+	}
+	threads, err := client.GetThreads(ctx, git.GetThreadsArgs{
+		RepositoryId:  &c.Repository,
+		PullRequestId: pr.PullRequestId,
+		Project:       &c.Project,
+	})
+	if err != nil {
+		return nil, err
+	}
+	commits, err := c.listCommits(ctx, client, *pr.PullRequestId)
+	if err != nil {
+		return nil, err
 	}
-	approvers, err := c.GetPullRequestApprovers(*pr.PullRequestId)
+	iterations, err := client.GetPullRequestIterations(ctx, git.GetPullRequestIterationsArgs{
+		RepositoryId:  &c.Repository,
+		PullRequestId: pr.PullRequestId,
+		Project:       &c.Project,
+	})
 	if err != nil {
-		return evidence, err
+		return nil, err
+	}
+	return buildPREvidence(pr, url, *reviewers, *threads, commits, *iterations), nil
+}
+
+// buildPREvidence builds the evidence of a pull request from its reviewers, comment threads, commits
+// and iterations. Users are identified by their unique name, usually their email. A commit author email
+// is only matched with a user when it is the unique name of the pull request creator, a reviewer or a voter,
+// since anyone can set any email on a commit.
+// Each push creates an iteration, which records when a commit became the head of the pull request.
+func buildPREvidence(pr git.GitPullRequest, url string, reviewers []git.IdentityRefWithVote,
+	threads []git.GitPullRequestCommentThread, commits []git.GitCommitRef, iterations []git.GitPullRequestIteration) *types.PREvidence {
+	evidence := &types.PREvidence{
+		URL:       url,
+		State:     string(*pr.Status),
+		Approvers: []string{},
+		Reviews:   voteReviews(threads),
+	}
+	if pr.LastMergeCommit != nil {
+		evidence.MergeCommit = stringValue(pr.LastMergeCommit.CommitId)
+	}
+	if pr.CreatedBy != nil {
+		evidence.Author = stringValue(pr.CreatedBy.UniqueName)
+	}
+
+	for _, r := range reviewers {
+		vote := intValue(r.Vote)
+		if vote == 10 {
+			evidence.Approvers = append(evidence.Approvers, stringValue(r.DisplayName))
+		}
+		// votes without a recorded vote update still count, without a timestamp
+		if reviewStateFromVote(vote) == types.ReviewApproved && !hasApprovalFrom(evidence.Reviews, stringValue(r.UniqueName)) {
+			evidence.Reviews = append(evidence.Reviews, types.PRReview{Reviewer: stringValue(r.UniqueName), State: types.ReviewApproved})
+		}
+	}
+
+	pushedAt := map[string]int64{}
+	for _, iteration := range iterations {
+		if iteration.SourceRefCommit == nil || iteration.CreatedDate == nil {
+			continue
+		}
+		sha1 := stringValue(iteration.SourceRefCommit.CommitId)
+		if iteration.CreatedDate.Time.Unix() > pushedAt[sha1] {
+			pushedAt[sha1] = iteration.CreatedDate.Time.Unix()
+		}
+	}
+	identities := []string{evidence.Author}
+	for _, r := range reviewers {
+		identities = append(identities, stringValue(r.UniqueName))
+	}
+	for _, review := range evidence.Reviews {
+		identities = append(identities, review.Reviewer)
+	}
+	prCommits := []types.PRCommit{}
+	for _, commit := range commits {
+		prCommit := types.PRCommit{Sha1: stringValue(commit.CommitId), PushedAt: pushedAt[stringValue(commit.CommitId)]}
+		if commit.Author != nil {
+			prCommit.Author = matchIdentity(identities, stringValue(commit.Author.Email))
+			if prCommit.Author == "" {
+				prCommit.Author = stringValue(commit.Author.Email)
+				if prCommit.Author == "" {
+					prCommit.Author = stringValue(commit.Author.Name)
+				}
+				prCommit.AuthorUnresolved = true
+			}
+		}
+		if commit.Committer != nil && commit.Committer.Date != nil {
+			prCommit.Timestamp = commit.Committer.Date.Time.Unix()
+		}
+		prCommits = append(prCommits, prCommit)
+	}
+	headSha1 := ""
+	if pr.LastMergeSourceCommit != nil {
+		headSha1 = stringValue(pr.LastMergeSourceCommit.CommitId)
+	}
+	evidence.Summarize(prCommits, headSha1)
+	return evidence
+}
+
+// voteReviews returns a review for each vote recorded in the system threads of a pull request
+func voteReviews(threads []git.GitPullRequestCommentThread) []types.PRReview {
+	reviews := []types.PRReview{}
+	for _, thread := range threads {
+		properties, ok := thread.Properties.(map[string]interface{})
+		if !ok || threadProperty(properties, "CodeReviewThreadType") != "VoteUpdate" {
+			continue
+		}
+		vote, err := strconv.Atoi(threadProperty(properties, "CodeReviewVoteResult"))
+		if err != nil {
+			continue
+		}
+		review := types.PRReview{State: reviewStateFromVote(vote)}
+		if thread.Identities != nil {
+			if identity, ok := (*thread.Identities)[threadProperty(properties, "CodeReviewVotedByIdentity")]; ok {
+				review.Reviewer = stringValue(identity.UniqueName)
+			}
+		}
+		if review.Reviewer == "" && thread.Comments != nil && len(*thread.Comments) > 0 && (*thread.Comments)[0].Author != nil {
+			review.Reviewer = stringValue((*thread.Comments)[0].Author.UniqueName)
+		}
+		if thread.PublishedDate != nil {
+			review.Timestamp = thread.PublishedDate.Time.Unix()
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews
+}
+
+// threadProperty returns the value of a thread property, which Azure DevOps stores as {"$type": ..., "$value": ...}
+func threadProperty(properties map[string]interface{}, name string) string {
+	property, ok := properties[name].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(property["$value"])
+}
+
+// reviewStateFromVote maps an Azure DevOps vote to a review state:
+// 10 approved, 5 approved with suggestions, 0 no vote, -5 waiting for author, -10 rejected
+func reviewStateFromVote(vote int) string {
+	switch {
+	case vote >= 5:
+		return types.ReviewApproved
+	case vote < 0:
+		return types.ReviewChangesRequested
+	default:
+		return types.ReviewUnapproved
+	}
+}
+
+func hasApprovalFrom(reviews []types.PRReview, reviewer string) bool {
+	for _, review := range reviews {
+		if review.State == types.ReviewApproved && types.EqualIdentity(review.Reviewer, reviewer) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchIdentity returns the unique name among identities that email belongs to, or "" when there is none
+func matchIdentity(identities []string, email string) string {
+	for _, identity := range identities {
+		if types.EqualIdentity(identity, email) {
+			return identity
+		}
+	}
+	return ""
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func intValue(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+// listCommits returns all the commits of a pull request
+func (c *AzureConfig) listCommits(ctx context.Context, client git.Client, number int) ([]git.GitCommitRef, error) {
+	allCommits := []git.GitCommitRef{}
+	args := git.GetPullRequestCommitsArgs{
+		RepositoryId:  &c.Repository,
+		PullRequestId: &number,
+		Project:       &c.Project,
+	}
+	for {
+		response, err := client.GetPullRequestCommits(ctx, args)
+		if err != nil {
+			return allCommits, err
+		}
+		allCommits = append(allCommits, response.Value...)
+		if response.ContinuationToken == "" {
+			return allCommits, nil
+		}
+		args.ContinuationToken = &response.ContinuationToken
 	}
-	evidence.Approvers = approvers
-	return evidence, nil
 }
 
 // PullRequestsForCommit returns a list of pull requests for a specific commit
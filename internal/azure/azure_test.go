@@ -2,10 +2,14 @@ package azure
 
 import (
 	"context"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/kosli-dev/cli/internal/testHelpers"
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/microsoft/azure-devops-go-api/azuredevops/git"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -212,6 +216,145 @@ func (suite *AzureTestSuite) TestExtractRepoName() {
 	}
 }
 
+func (suite *AzureTestSuite) TestBuildPREvidence() {
+	for _, t := range []struct {
+		name    string
+		fixture string
+		want    *types.PREvidence
+	}{
+		{
+			name:    "votes are taken from vote update threads and classified against the last commit",
+			fixture: "approved-before-last-commit",
+			want: &types.PREvidence{
+				MergeCommit:   "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6",
+				URL:           "https://dev.azure.com/acme/shop/_git/shop/pullrequest/31",
+				State:         "completed",
+				Approvers:     []string{"Carol White"},
+				Author:        "alice@acme.com",
+				CommitAuthors: []string{"bob@acme.com", "alice@acme.com"},
+				Reviews: []types.PRReview{
+					{Reviewer: "carol@acme.com", State: "APPROVED", Timestamp: 1709294400, AfterLastCommit: boolPtr(false)},
+					// approved with suggestions, the voter is the author of the system comment
+					{Reviewer: "dave@acme.com", State: "APPROVED", Timestamp: 1709391600, AfterLastCommit: boolPtr(true)},
+					{Reviewer: "erin@acme.com", State: "CHANGES_REQUESTED", Timestamp: 1709391600, AfterLastCommit: boolPtr(true)},
+				},
+				LastCommit: &types.PRCommit{
+					Sha1:      "b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1",
+					Author:    "bob@acme.com",
+					Timestamp: 1709370000,
+					PushedAt:  1709370300,
+				},
+				ApprovedAfterLastCommit: true,
+				SelfApproved:            false,
+			},
+		},
+	} {
+		suite.Suite.Run(t.name, func() {
+			var pr git.GitPullRequest
+			var reviewers []git.IdentityRefWithVote
+			var threads []git.GitPullRequestCommentThread
+			var commits []git.GitCommitRef
+			var iterations []git.GitPullRequestIteration
+			loadFixture(suite.Suite.T(), t.fixture, "pull_request.json", &pr)
+			loadFixture(suite.Suite.T(), t.fixture, "reviewers.json", &reviewers)
+			loadFixture(suite.Suite.T(), t.fixture, "threads.json", &threads)
+			loadFixture(suite.Suite.T(), t.fixture, "commits.json", &commits)
+			loadFixture(suite.Suite.T(), t.fixture, "iterations.json", &iterations)
+
+			evidence := buildPREvidence(pr, t.want.URL, reviewers, threads, commits, iterations)
+			require.Equal(suite.Suite.T(), t.want, evidence)
+		})
+	}
+}
+
+func (suite *AzureTestSuite) TestIndependentApproval() {
+	for _, t := range []struct {
+		name          string
+		fixture       string
+		required      int
+		wantCompliant bool
+		wantReason    string
+	}{
+		{
+			name:          "an approval with suggestions after the last commit is independent",
+			fixture:       "approved-before-last-commit",
+			required:      1,
+			wantCompliant: true,
+			wantReason: "1 of 1 required independent approval(s) after the last commit: dave@acme.com; " +
+				"not counted: carol@acme.com (approved before the last commit)",
+		},
+		{
+			name:          "approvals by commit authors are not independent",
+			fixture:       "self-approved",
+			required:      1,
+			wantCompliant: true,
+			wantReason: "1 of 1 required independent approval(s) after the last commit: carol@acme.com; " +
+				"not counted: dave@acme.com (approved before the last commit), bob@acme.com (commit author)",
+		},
+		{
+			name:          "not enough independent approvals is non-compliant",
+			fixture:       "self-approved",
+			required:      2,
+			wantCompliant: false,
+			wantReason: "1 of 2 required independent approval(s) after the last commit: carol@acme.com; " +
+				"not counted: dave@acme.com (approved before the last commit), bob@acme.com (commit author)",
+		},
+	} {
+		suite.Suite.Run(t.name, func() {
+			var pr git.GitPullRequest
+			var reviewers []git.IdentityRefWithVote
+			var threads []git.GitPullRequestCommentThread
+			var commits []git.GitCommitRef
+			var iterations []git.GitPullRequestIteration
+			loadFixture(suite.Suite.T(), t.fixture, "pull_request.json", &pr)
+			loadFixture(suite.Suite.T(), t.fixture, "reviewers.json", &reviewers)
+			loadFixture(suite.Suite.T(), t.fixture, "threads.json", &threads)
+			loadFixture(suite.Suite.T(), t.fixture, "commits.json", &commits)
+			loadFixture(suite.Suite.T(), t.fixture, "iterations.json", &iterations)
+
+			result := buildPREvidence(pr, "", reviewers, threads, commits, iterations).EvaluateIndependentApproval(t.required)
+			require.Equal(suite.Suite.T(), t.wantCompliant, result.Compliant)
+			require.Equal(suite.Suite.T(), t.wantReason, result.Reason)
+		})
+	}
+}
+
+func (suite *AzureTestSuite) TestCommitAuthorsAreMatchedWithPullRequestIdentities() {
+	var pr git.GitPullRequest
+	var reviewers []git.IdentityRefWithVote
+	var threads []git.GitPullRequestCommentThread
+	var commits []git.GitCommitRef
+	var iterations []git.GitPullRequestIteration
+	loadFixture(suite.Suite.T(), "self-approved", "pull_request.json", &pr)
+	loadFixture(suite.Suite.T(), "self-approved", "reviewers.json", &reviewers)
+	loadFixture(suite.Suite.T(), "self-approved", "threads.json", &threads)
+	loadFixture(suite.Suite.T(), "self-approved", "commits.json", &commits)
+	loadFixture(suite.Suite.T(), "self-approved", "iterations.json", &iterations)
+	// the approver bob@acme.com authored the last commit under another email
+	otherEmail := "bob.jones@example.com"
+	commits[0].Author.Email = &otherEmail
+
+	evidence := buildPREvidence(pr, "", reviewers, threads, commits, iterations)
+	require.Equal(suite.Suite.T(), []string{"alice@acme.com"}, evidence.CommitAuthors)
+	require.Equal(suite.Suite.T(), []string{"bob.jones@example.com"}, evidence.UnresolvedCommitAuthors)
+	require.True(suite.Suite.T(), evidence.LastCommit.AuthorUnresolved)
+
+	result := evidence.EvaluateIndependentApproval(1)
+	require.False(suite.Suite.T(), result.Compliant)
+	require.Contains(suite.Suite.T(), result.Reason, "bob.jones@example.com")
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+// loadFixture decodes a recorded Azure DevOps API response from testdata
+func loadFixture(t *testing.T, fixture, file string, v interface{}) {
+	content, err := os.ReadFile(filepath.Join("testdata", fixture, file))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, v))
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestAzureTestSuite(t *testing.T) {
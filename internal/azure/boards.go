@@ -0,0 +1,84 @@
+package azure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// WorkItemReferencePattern matches Azure Boards work item references, e.g. "AB#12"
+const WorkItemReferencePattern = `AB#[0-9]+`
+
+// ReferencePattern returns the pattern of Azure Boards work item references
+func (c *AzureConfig) ReferencePattern() string {
+	return WorkItemReferencePattern
+}
+
+// GetIssueInfo looks up an Azure Boards work item from a reference like "AB#12"
+func (c *AzureConfig) GetIssueInfo(reference string) (*types.IssueInfo, error) {
+	id := strings.TrimPrefix(reference, "AB#")
+	result := &types.IssueInfo{IssueID: reference}
+
+	workItemURL, err := url.JoinPath(c.OrgURL, c.Project, "_apis/wit/workitems", id)
+	if err != nil {
+		return nil, err
+	}
+	reqParams := &requests.RequestParams{
+		Method:            http.MethodGet,
+		URL:               workItemURL + "?api-version=7.0",
+		Password:          c.Token,
+		AdditionalHeaders: map[string]string{"Accept": "application/json"},
+	}
+	resp, err := c.KosliClient.Do(reqParams)
+	if err != nil {
+		if requests.IsNotFound(err) {
+			return result, nil
+		}
+		// Azure DevOps answers invalid credentials with 203 and a sign-in page
+		var httpErr *requests.HTTPError
+		if errors.As(err, &httpErr) {
+			return nil, fmt.Errorf("failed to get work item %s from Azure Boards, got HTTP status %d", reference, httpErr.StatusCode)
+		}
+		return nil, fmt.Errorf("failed to get work item %s from Azure Boards: %v", reference, err)
+	}
+
+	var workItem struct {
+		Fields struct {
+			State        string `json:"System.State"`
+			Title        string `json:"System.Title"`
+			WorkItemType string `json:"System.WorkItemType"`
+			AssignedTo   *struct {
+				DisplayName string `json:"displayName"`
+			} `json:"System.AssignedTo"`
+		} `json:"fields"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"_links"`
+	}
+	err = json.Unmarshal([]byte(resp.Body), &workItem)
+	if err != nil {
+		return nil, err
+	}
+
+	result.IssueExists = true
+	result.IssueURL = workItem.Links.HTML.Href
+	result.Status = workItem.Fields.State
+	assignedTo := ""
+	if workItem.Fields.AssignedTo != nil {
+		assignedTo = workItem.Fields.AssignedTo.DisplayName
+	}
+	result.IssueFields = map[string]interface{}{
+		"title":          workItem.Fields.Title,
+		"work_item_type": workItem.Fields.WorkItemType,
+		"assigned_to":    assignedTo,
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/maxcnunes/httpfake"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetIssueInfo(t *testing.T) {
+	fakeAzure := httpfake.New(httpfake.WithTesting(t))
+	defer fakeAzure.Close()
+	fakeAzure.NewHandler().
+		Get("/acme/shop/_apis/wit/workitems/12").
+		AssertQueryValue("api-version", "7.0").
+		Reply(200).
+		BodyString(`{"id": 12, "fields": {"System.State": "Resolved", "System.Title": "Basket total is wrong",
+			"System.WorkItemType": "Bug", "System.AssignedTo": {"displayName": "Bob Jones"}},
+			"_links": {"html": {"href": "https://dev.azure.com/acme/shop/_workitems/edit/12"}}}`)
+	fakeAzure.NewHandler().
+		Get("/acme/shop/_apis/wit/workitems/13").
+		Reply(404).
+		BodyString(`{"message": "TF401232: Work item 13 does not exist, or you do not have permissions to read it."}`)
+	fakeAzure.NewHandler().
+		Get("/acme/shop/_apis/wit/workitems/14").
+		Reply(203).
+		BodyString(`<html>sign in</html>`)
+
+	config := NewAzureConfig("some-token", fakeAzure.ResolveURL("/acme"), "shop", "shop")
+	kosliClient, err := requests.NewKosliClient("", 0, false, logger.NewStandardLogger())
+	require.NoError(t, err)
+	config.KosliClient = kosliClient
+	require.Equal(t, WorkItemReferencePattern, config.ReferencePattern())
+
+	info, err := config.GetIssueInfo("AB#12")
+	require.NoError(t, err)
+	require.True(t, info.IssueExists)
+	require.Equal(t, "AB#12", info.IssueID)
+	require.Equal(t, "https://dev.azure.com/acme/shop/_workitems/edit/12", info.IssueURL)
+	require.Equal(t, "Resolved", info.Status)
+	require.Equal(t, map[string]interface{}{
+		"title":          "Basket total is wrong",
+		"work_item_type": "Bug",
+		"assigned_to":    "Bob Jones",
+	}, info.IssueFields)
+
+	info, err = config.GetIssueInfo("AB#13")
+	require.NoError(t, err)
+	require.False(t, info.IssueExists)
+
+	_, err = config.GetIssueInfo("AB#14")
+	require.Error(t, err)
+}
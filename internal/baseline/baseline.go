@@ -0,0 +1,126 @@
+package baseline
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+const dateLayout = "2006-01-02"
+
+// Entry is an accepted risk for a vulnerability or rule ID, optionally
+// narrowed down to a package and/or a location
+type Entry struct {
+	ID        string `mapstructure:"id" json:"id" validate:"required"`
+	Package   string `mapstructure:"package" json:"package,omitempty"`
+	Location  string `mapstructure:"location" json:"location,omitempty"`
+	Reason    string `mapstructure:"reason" json:"reason" validate:"required"`
+	Approver  string `mapstructure:"approver" json:"approver" validate:"required"`
+	ExpiresOn string `mapstructure:"expires-on" json:"expires_on" validate:"required,datetime=2006-01-02"`
+}
+
+// Baseline represents an accepted-risk file
+type Baseline struct {
+	Version  int     `mapstructure:"version" validate:"required,oneof=1"`
+	Accepted []Entry `mapstructure:"accepted" validate:"required,dive"`
+}
+
+// Load reads and validates a baseline YAML file
+func Load(baselineFile string) (*Baseline, error) {
+	var b *Baseline
+	v := viper.New()
+	v.SetConfigFile(baselineFile)
+	v.SetConfigType("yaml")
+
+	if err := v.ReadInConfig(); err != nil {
+		return b, fmt.Errorf("failed to parse baseline file [%s] : %v", baselineFile, err)
+	}
+
+	// YAML decodes unquoted dates as timestamps
+	if err := v.UnmarshalExact(&b, viper.DecodeHook(timeToDateHook)); err != nil {
+		return b, fmt.Errorf("failed to unmarshal baseline file [%s] : %v", baselineFile, err)
+	}
+
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.Struct(b); err != nil {
+		return b, fmt.Errorf("baseline file [%s] is invalid: %v", baselineFile, err)
+	}
+	return b, nil
+}
+
+func timeToDateHook(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+	if t, ok := data.(time.Time); ok && to.Kind() == reflect.String {
+		return t.Format(dateLayout), nil
+	}
+	return data, nil
+}
+
+// Expired returns true if the entry's expiry date has passed.
+// An entry is valid until the end of its expires-on day (UTC).
+func (e *Entry) Expired(now time.Time) bool {
+	expiresOn, err := time.Parse(dateLayout, e.ExpiresOn)
+	if err != nil {
+		return true
+	}
+	return !now.UTC().Before(expiresOn.AddDate(0, 0, 1))
+}
+
+// Match returns the entry accepting a finding with the given ID, message and
+// location URIs, or nil if there is none. A valid entry is preferred over an
+// expired one, and the returned bool reports if the returned entry is expired.
+func (b *Baseline) Match(id, message string, uris []string, now time.Time) (*Entry, bool) {
+	var expired *Entry
+	for i := range b.Accepted {
+		entry := &b.Accepted[i]
+		if !entry.matches(id, message, uris) {
+			continue
+		}
+		if !entry.Expired(now) {
+			return entry, false
+		}
+		if expired == nil {
+			expired = entry
+		}
+	}
+	return expired, expired != nil
+}
+
+func (e *Entry) matches(id, message string, uris []string) bool {
+	if e.ID != id {
+		return false
+	}
+	// scanners name the affected package in the message, e.g. "introduces a vulnerable got package"
+	if e.Package != "" {
+		pattern := `(^|[^A-Za-z0-9_.\-/])` + regexp.QuoteMeta(e.Package) + `([^A-Za-z0-9_\-/]|$)`
+		if !regexp.MustCompile(pattern).MatchString(message) {
+			return false
+		}
+	}
+	if e.Location != "" {
+		for _, uri := range uris {
+			if matchLocation(e.Location, uri) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// matchLocation matches a location URI against a literal path, a directory
+// prefix or a glob pattern as supported by path.Match
+func matchLocation(location, uri string) bool {
+	location = strings.TrimPrefix(location, "./")
+	uri = strings.TrimPrefix(strings.TrimPrefix(uri, "file://"), "./")
+	if location == uri || strings.HasPrefix(uri, strings.TrimSuffix(location, "/")+"/") {
+		return true
+	}
+	matched, err := path.Match(location, uri)
+	return err == nil && matched
+}
@@ -0,0 +1,134 @@
+package baseline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		wantEntries int
+		wantErr     string
+	}{
+		{
+			name:        "a valid baseline file is loaded, with quoted and unquoted dates",
+			file:        "testdata/baseline.yml",
+			wantEntries: 3,
+		},
+		{
+			name:    "a missing baseline file causes an error",
+			file:    "testdata/non-existing.yml",
+			wantErr: "failed to parse baseline file [testdata/non-existing.yml]",
+		},
+		{
+			name:    "missing fields and invalid dates cause an error",
+			file:    "testdata/invalid-baseline.yml",
+			wantErr: "Key: 'Baseline.Accepted[0].Approver' Error:Field validation for 'Approver' failed on the 'required' tag\nKey: 'Baseline.Accepted[0].ExpiresOn' Error:Field validation for 'ExpiresOn' failed on the 'datetime' tag",
+		},
+		{
+			name:    "unknown keys cause an error",
+			file:    "testdata/unknown-key-baseline.yml",
+			wantErr: "has invalid keys: expires",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Load(tt.file)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, b.Accepted, tt.wantEntries)
+			assert.Equal(t, "2099-12-31", b.Accepted[0].ExpiresOn)
+			assert.Equal(t, "2026-01-31", b.Accepted[1].ExpiresOn)
+		})
+	}
+}
+
+func TestExpired(t *testing.T) {
+	e := &Entry{ExpiresOn: "2026-01-31"}
+	assert.False(t, e.Expired(time.Date(2026, 1, 31, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, e.Expired(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestMatch(t *testing.T) {
+	b, err := Load("testdata/baseline.yml")
+	require.NoError(t, err)
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		id          string
+		message     string
+		uris        []string
+		wantID      string
+		wantExpired bool
+	}{
+		{
+			name:    "matches on ID and package name in the message",
+			id:      "CVE-2023-0001",
+			message: "Package: libssl3\nInstalled Version: 3.0.0",
+			wantID:  "CVE-2023-0001",
+		},
+		{
+			name:    "does not match when the package is only a prefix of another package",
+			id:      "CVE-2023-0001",
+			message: "Package: libssl3-dev",
+		},
+		{
+			name:    "does not match a different ID",
+			id:      "CVE-2023-0002",
+			message: "Package: libssl3",
+		},
+		{
+			name:        "matches a directory location and reports the entry as expired",
+			id:          "go/sql-injection",
+			uris:        []string{"internal/db/query.go"},
+			wantID:      "go/sql-injection",
+			wantExpired: true,
+		},
+		{
+			name: "does not match a location outside the directory",
+			id:   "go/sql-injection",
+			uris: []string{"internal/dbx/query.go"},
+		},
+		{
+			name:    "matches both package and location",
+			id:      "SNYK-JS-GOT-2932019",
+			message: "This file introduces a vulnerable got package with a medium severity vulnerability.",
+			uris:    []string{"package.json"},
+			wantID:  "SNYK-JS-GOT-2932019",
+		},
+		{
+			name:    "does not match when only the package matches",
+			id:      "SNYK-JS-GOT-2932019",
+			message: "This file introduces a vulnerable got package with a medium severity vulnerability.",
+			uris:    []string{"web/package.json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, expired := b.Match(tt.id, tt.message, tt.uris, now)
+			if tt.wantID == "" {
+				assert.Nil(t, entry)
+				return
+			}
+			require.NotNil(t, entry)
+			assert.Equal(t, tt.wantID, entry.ID)
+			assert.Equal(t, tt.wantExpired, expired)
+		})
+	}
+}
+
+func TestMatchLocation(t *testing.T) {
+	assert.True(t, matchLocation("cmd/*.go", "cmd/run.go"))
+	assert.False(t, matchLocation("cmd/*.go", "cmd/sub/run.go"))
+	assert.True(t, matchLocation("./package.json", "file://package.json"))
+}
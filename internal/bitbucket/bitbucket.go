@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/kosli-dev/cli/internal/logger"
 	"github.com/kosli-dev/cli/internal/requests"
@@ -11,6 +13,8 @@ import (
 )
 
 type Config struct {
+	// BaseURL is the URL of a Bitbucket Data Center or Server instance, empty for Bitbucket Cloud
+	BaseURL     string
 	Username    string
 	Password    string
 	AccessToken string
@@ -19,9 +23,19 @@ type Config struct {
 	Logger      *logger.Logger
 	KosliClient *requests.Client
 	Assert      bool
+	// reviewHistory is true to get the commits and activity of pull requests
+	reviewHistory bool
+}
+
+// EnableReviewHistory makes the pull request evidence include the commits, and the approval and push times
+func (c *Config) EnableReviewHistory() {
+	c.reviewHistory = true
 }
 
 func (c *Config) PREvidenceForCommit(commit string) ([]*types.PREvidence, error) {
+	if c.isDataCenter() {
+		return c.getPullRequestsFromDataCenterApi(commit)
+	}
 	return c.getPullRequestsFromBitbucketApi(commit)
 }
 
@@ -86,81 +100,246 @@ func (c *Config) parseBitbucketResponse(commit string, response *requests.HTTPRe
 
 func (c *Config) getPullRequestDetailsFromBitbucket(prApiUrl, prHtmlLink, commit string) (*types.PREvidence, error) {
 	c.Logger.Debug("getting pull request details for " + prApiUrl)
-	evidence := &types.PREvidence{}
 
+	response, err := c.get(prApiUrl)
+	if err != nil {
+		return nil, err
+	}
+	if response.Resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to get PR details, got HTTP status %d. Please review repository permissions", response.Resp.StatusCode)
+	}
+	var pr pullRequest
+	err = json.Unmarshal([]byte(response.Body), &pr)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pr.Participants) == 0 {
+		c.Logger.Debug("no approvers found")
+	}
+	if !c.reviewHistory {
+		return buildPREvidence(pr, nil, nil, prHtmlLink, commit), nil
+	}
+	commits, err := c.getPullRequestCommits(prApiUrl + "/commits")
+	if err != nil {
+		return nil, err
+	}
+	activity, err := c.getPullRequestActivity(prApiUrl + "/activity")
+	if err != nil {
+		return nil, err
+	}
+	return buildPREvidence(pr, commits, activity, prHtmlLink, commit), nil
+}
+
+// getPullRequestActivity returns the activity of a pull request, following pagination
+func (c *Config) getPullRequestActivity(url string) ([]activity, error) {
+	activities := []activity{}
+	for url != "" {
+		c.Logger.Debug("getting pull request activity from " + url)
+		response, err := c.get(url)
+		if err != nil {
+			return activities, err
+		}
+		if response.Resp.StatusCode != 200 {
+			return activities, fmt.Errorf("failed to get PR activity, got HTTP status %d", response.Resp.StatusCode)
+		}
+		var page activityPage
+		err = json.Unmarshal([]byte(response.Body), &page)
+		if err != nil {
+			return activities, err
+		}
+		activities = append(activities, page.Values...)
+		url = page.Next
+	}
+	return activities, nil
+}
+
+// getPullRequestCommits returns all the commits of a pull request, following pagination
+func (c *Config) getPullRequestCommits(url string) ([]commitData, error) {
+	commits := []commitData{}
+	for url != "" {
+		c.Logger.Debug("getting pull request commits from " + url)
+		response, err := c.get(url)
+		if err != nil {
+			return commits, err
+		}
+		if response.Resp.StatusCode != 200 {
+			return commits, fmt.Errorf("failed to get PR commits, got HTTP status %d", response.Resp.StatusCode)
+		}
+		var page commitsPage
+		err = json.Unmarshal([]byte(response.Body), &page)
+		if err != nil {
+			return commits, err
+		}
+		commits = append(commits, page.Values...)
+		url = page.Next
+	}
+	return commits, nil
+}
+
+func (c *Config) get(url string) (*requests.HTTPResponse, error) {
 	reqParams := &requests.RequestParams{
 		Method:   http.MethodGet,
-		URL:      prApiUrl,
+		URL:      url,
 		Username: c.Username,
 		Password: c.Password,
+		Token:    c.AccessToken,
 	}
-	response, err := c.KosliClient.Do(reqParams)
-	if err != nil {
-		return evidence, err
+	return c.KosliClient.Do(reqParams)
+}
+
+// buildPREvidence builds the evidence of a pull request from its details, commits and activity.
+// Users are identified by their display name and account id, as display names are not unique.
+// Each push is recorded as an update in the activity, with the head commit of the pull request,
+// and each approval or changes request as an approval or changes_requested entry.
+func buildPREvidence(pr pullRequest, commits []commitData, activities []activity, prHtmlLink, commit string) *types.PREvidence {
+	evidence := &types.PREvidence{
+		URL:         prHtmlLink,
+		MergeCommit: commit,
+		State:       pr.State,
+		Author:      pr.Author.identity(),
+		Approvers:   []string{},
+		Reviews:     []types.PRReview{},
 	}
-	if response.Resp.StatusCode == 200 {
-		var responseData map[string]interface{}
-		err := json.Unmarshal([]byte(response.Body), &responseData)
-		if err != nil {
-			return evidence, err
+	// the participated_on time of a participant changes with any participation, e.g. a comment,
+	// so reviews are only timed by their approval and changes request activity
+	reviewedAt := map[string]int64{}
+	recordReview := func(state string, review *reviewActivity) {
+		if review == nil || review.Date == nil {
+			return
+		}
+		key := state + " " + review.User.identity()
+		if review.Date.Unix() > reviewedAt[key] {
+			reviewedAt[key] = review.Date.Unix()
+		}
+	}
+	for _, a := range activities {
+		recordReview(types.ReviewApproved, a.Approval)
+		recordReview(types.ReviewChangesRequested, a.ChangesRequested)
+	}
+	for _, p := range pr.Participants {
+		if p.Approved {
+			evidence.Approvers = append(evidence.Approvers, p.User.DisplayName)
+		}
+		state := ""
+		switch {
+		case p.Approved || p.State == "approved":
+			state = types.ReviewApproved
+		case p.State == "changes_requested":
+			state = types.ReviewChangesRequested
+		default:
+			continue
 		}
+		review := types.PRReview{Reviewer: p.User.identity(), State: state, Timestamp: reviewedAt[state+" "+p.User.identity()]}
+		evidence.Reviews = append(evidence.Reviews, review)
+	}
 
-		evidence.URL = prHtmlLink
-		evidence.MergeCommit = commit
-		evidence.State = responseData["state"].(string)
-		participants := responseData["participants"].([]interface{})
-		approvers := []string{}
-
-		if len(participants) > 0 {
-			for _, participantInterface := range participants {
-				p := participantInterface.(map[string]interface{})
-				if p["approved"].(bool) {
-					user := p["user"].(map[string]interface{})
-					approvers = append(approvers, user["display_name"].(string))
-				}
+	prCommits := []types.PRCommit{}
+	// the source commit hashes of a pull request and of its updates are abbreviated
+	headSha1 := pr.Source.Commit.Hash
+	for _, bbCommit := range commits {
+		prCommit := types.PRCommit{Sha1: bbCommit.Hash, Author: bbCommit.Author.name()}
+		// commits without a linked Bitbucket account only have the git author
+		prCommit.AuthorUnresolved = bbCommit.Author.User == nil || bbCommit.Author.User.AccountID == ""
+		if bbCommit.Date != nil {
+			prCommit.Timestamp = bbCommit.Date.Unix()
+		}
+		for _, a := range activities {
+			update := a.Update
+			if update != nil && update.Date != nil && update.Source.Commit.Hash != "" &&
+				strings.HasPrefix(bbCommit.Hash, update.Source.Commit.Hash) && update.Date.Unix() > prCommit.PushedAt {
+				prCommit.PushedAt = update.Date.Unix()
 			}
-		} else {
-			c.Logger.Debug("no approvers found")
 		}
-		evidence.Approvers = approvers
-		// prID := int(responseData["id"].(float64))
-		// evidence.LastCommit, evidence.LastCommitter, err = getBitbucketPRLastCommit(workspace, repository, username, password, prID)
-		// if err != nil {
-		// 	return evidence, err
-		// }
-		// if utils.Contains(approvers, evidence.LastCommitter) {
-		// 	evidence.SelfApproved = true
-		// }
-	} else {
-		return evidence, fmt.Errorf("failed to get PR details, got HTTP status %d. Please review repository permissions", response.Resp.StatusCode)
-	}
-	return evidence, nil
-}
-
-// func getBitbucketPRLastCommit(workspace, repository, username, password string, prID int) (string, string, error) {
-// 	url := fmt.Sprintf("https://api.bitbucket.org/2.0/repositories/%s/%s/pullrequests/%d/commits", workspace, repository, prID)
-// 	log.Debug("Getting pull requests commits from" + url)
-// 	response, err := requests.SendPayload([]byte{}, url, username, password,
-// 		global.MaxAPIRetries, false, http.MethodGet, log)
-// 	if err != nil {
-// 		return "", "", err
-// 	}
-
-// 	if response.Resp.StatusCode == 200 {
-// 		var responseData map[string]interface{}
-// 		err := json.Unmarshal([]byte(response.Body), &responseData)
-// 		if err != nil {
-// 			return "", "", err
-// 		}
-// 		prCommits := responseData["values"].([]interface{})
-
-// 		// the first commit is the merge commit
-// 		// TODO: is it safe to always to get the second commit?
-// 		lastCommit := prCommits[1].(map[string]interface{})
-// 		lastAuthor := lastCommit["author"].(map[string]interface{})
-// 		return lastCommit["hash"].(string), lastAuthor["user"].(map[string]interface{})["display_name"].(string), nil
-
-// 	} else {
-// 		return "", "", fmt.Errorf("failed to get PR commits, got HTTP status %d", response.Resp.StatusCode)
-// 	}
-// }
+		if headSha1 != "" && strings.HasPrefix(bbCommit.Hash, headSha1) {
+			headSha1 = bbCommit.Hash
+		}
+		prCommits = append(prCommits, prCommit)
+	}
+	evidence.Summarize(prCommits, headSha1)
+	return evidence
+}
+
+type user struct {
+	DisplayName string `json:"display_name"`
+	AccountID   string `json:"account_id"`
+}
+
+// identity returns the display name of a user with the account id that makes it unique
+func (u user) identity() string {
+	if u.AccountID == "" {
+		return u.DisplayName
+	}
+	return fmt.Sprintf("%s [%s]", u.DisplayName, u.AccountID)
+}
+
+type pullRequest struct {
+	ID           int           `json:"id"`
+	State        string        `json:"state"`
+	Author       user          `json:"author"`
+	Participants []participant `json:"participants"`
+	Source       struct {
+		Commit struct {
+			Hash string `json:"hash"`
+		} `json:"commit"`
+	} `json:"source"`
+}
+
+type activityPage struct {
+	Values []activity `json:"values"`
+	Next   string     `json:"next"`
+}
+
+// activity is an entry of the activity of a pull request, only updates, approvals and changes requests are used
+type activity struct {
+	Approval         *reviewActivity `json:"approval"`
+	ChangesRequested *reviewActivity `json:"changes_requested"`
+	Update           *struct {
+		Date   *time.Time `json:"date"`
+		Source struct {
+			Commit struct {
+				Hash string `json:"hash"`
+			} `json:"commit"`
+		} `json:"source"`
+	} `json:"update"`
+}
+
+// reviewActivity is an approval or a changes request in the activity of a pull request
+type reviewActivity struct {
+	Date *time.Time `json:"date"`
+	User user       `json:"user"`
+}
+
+type participant struct {
+	User     user   `json:"user"`
+	Role     string `json:"role"`
+	Approved bool   `json:"approved"`
+	State    string `json:"state"`
+}
+
+type commitsPage struct {
+	Values []commitData `json:"values"`
+	Next   string       `json:"next"`
+}
+
+type commitData struct {
+	Hash   string       `json:"hash"`
+	Date   *time.Time   `json:"date"`
+	Author commitAuthor `json:"author"`
+}
+
+type commitAuthor struct {
+	// Raw is the git author, e.g. "Jane Doe <jane@example.com>"
+	Raw  string `json:"raw"`
+	User *user  `json:"user"`
+}
+
+// name returns the identity of the Bitbucket user who authored a commit,
+// or the git author name when the commit is not linked to a user
+func (a commitAuthor) name() string {
+	if a.User != nil && a.User.AccountID != "" {
+		return a.User.identity()
+	}
+	name, _, _ := strings.Cut(a.Raw, " <")
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,245 @@
+package bitbucket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// pageLimit is the number of items requested per page from the Bitbucket Data Center API
+const pageLimit = 100
+
+// isDataCenter is true when the config targets a Bitbucket Data Center or Server instance
+func (c *Config) isDataCenter() bool {
+	return c.BaseURL != ""
+}
+
+// dataCenterRepoURL returns the Data Center API URL of a path in the repository.
+// In Data Center, the workspace is the project key and the repository is the repository slug.
+func (c *Config) dataCenterRepoURL(format string, a ...interface{}) string {
+	return fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/%s", strings.TrimSuffix(c.BaseURL, "/"),
+		url.PathEscape(c.Workspace), url.PathEscape(c.Repository), fmt.Sprintf(format, a...))
+}
+
+func (c *Config) getPullRequestsFromDataCenterApi(commit string) ([]*types.PREvidence, error) {
+	pullRequestsEvidence := []*types.PREvidence{}
+	prs := []dcPullRequest{}
+	err := c.getDataCenterPages(c.dataCenterRepoURL("commits/%s/pull-requests", commit), func(values json.RawMessage) error {
+		var page []dcPullRequest
+		err := json.Unmarshal(values, &page)
+		prs = append(prs, page...)
+		return err
+	})
+	if err != nil {
+		return pullRequestsEvidence, err
+	}
+
+	for _, pr := range prs {
+		if !c.reviewHistory {
+			pullRequestsEvidence = append(pullRequestsEvidence, buildDataCenterPREvidence(pr, nil, nil, commit))
+			continue
+		}
+		activities := []dcActivity{}
+		err := c.getDataCenterPages(c.dataCenterRepoURL("pull-requests/%d/activities", pr.ID), func(values json.RawMessage) error {
+			var page []dcActivity
+			err := json.Unmarshal(values, &page)
+			activities = append(activities, page...)
+			return err
+		})
+		if err != nil {
+			return pullRequestsEvidence, err
+		}
+		commits := []dcCommit{}
+		err = c.getDataCenterPages(c.dataCenterRepoURL("pull-requests/%d/commits", pr.ID), func(values json.RawMessage) error {
+			var page []dcCommit
+			err := json.Unmarshal(values, &page)
+			commits = append(commits, page...)
+			return err
+		})
+		if err != nil {
+			return pullRequestsEvidence, err
+		}
+		pullRequestsEvidence = append(pullRequestsEvidence, buildDataCenterPREvidence(pr, activities, commits, commit))
+	}
+	return pullRequestsEvidence, nil
+}
+
+// getDataCenterPages calls collect with the values of each page of a paged Data Center API resource
+func (c *Config) getDataCenterPages(url string, collect func(values json.RawMessage) error) error {
+	start := 0
+	for {
+		pageURL := fmt.Sprintf("%s?limit=%d&start=%d", url, pageLimit, start)
+		c.Logger.Debug("getting " + pageURL)
+		response, err := c.get(pageURL)
+		if err != nil {
+			return err
+		}
+		var page dcPage
+		err = json.Unmarshal([]byte(response.Body), &page)
+		if err != nil {
+			return err
+		}
+		err = collect(page.Values)
+		if err != nil {
+			return err
+		}
+		if page.IsLastPage || page.NextPageStart == nil {
+			return nil
+		}
+		start = *page.NextPageStart
+	}
+}
+
+// buildDataCenterPREvidence builds the evidence of a Data Center pull request from its activities and commits.
+// Users are identified by their user name, as display names are not unique.
+// Each push is recorded as a RESCOPED activity with the new head commit of the pull request.
+func buildDataCenterPREvidence(pr dcPullRequest, activities []dcActivity, commits []dcCommit, commit string) *types.PREvidence {
+	evidence := &types.PREvidence{
+		MergeCommit: commit,
+		State:       pr.State,
+		Author:      pr.Author.User.Name,
+		Approvers:   []string{},
+		Reviews:     []types.PRReview{},
+	}
+	if len(pr.Links.Self) > 0 {
+		evidence.URL = pr.Links.Self[0].Href
+	}
+	if pr.Properties.MergeCommit.ID != "" {
+		evidence.MergeCommit = pr.Properties.MergeCommit.ID
+	}
+	approvers := []string{}
+	for _, reviewer := range pr.Reviewers {
+		if reviewer.Approved || reviewer.Status == "APPROVED" {
+			evidence.Approvers = append(evidence.Approvers, reviewer.User.DisplayName)
+			approvers = append(approvers, reviewer.User.Name)
+		}
+	}
+
+	for _, activity := range activities {
+		state := ""
+		switch activity.Action {
+		case "APPROVED":
+			state = types.ReviewApproved
+		case "UNAPPROVED":
+			state = types.ReviewUnapproved
+		case "REVIEWED":
+			// a reviewer marked the pull request as 'needs work'
+			state = types.ReviewChangesRequested
+		default:
+			continue
+		}
+		evidence.Reviews = append(evidence.Reviews, types.PRReview{
+			Reviewer:  activity.User.Name,
+			State:     state,
+			Timestamp: activity.CreatedDate / 1000,
+		})
+	}
+	// approvals whose activity is missing are recorded without a timestamp
+	for _, approver := range approvers {
+		if !hasReviewFrom(evidence.Reviews, approver) {
+			evidence.Reviews = append(evidence.Reviews, types.PRReview{Reviewer: approver, State: types.ReviewApproved})
+		}
+	}
+
+	pushedAt := map[string]int64{}
+	rescoped := false
+	for _, activity := range activities {
+		if activity.Action == "RESCOPED" && activity.FromHash != "" {
+			rescoped = true
+			if activity.CreatedDate/1000 > pushedAt[activity.FromHash] {
+				pushedAt[activity.FromHash] = activity.CreatedDate / 1000
+			}
+		}
+	}
+	// the head commit of a pull request that was never updated was pushed before it was opened
+	for _, activity := range activities {
+		if activity.Action == "OPENED" && !rescoped {
+			pushedAt[pr.FromRef.LatestCommit] = activity.CreatedDate / 1000
+		}
+	}
+
+	prCommits := []types.PRCommit{}
+	for _, dcCommit := range commits {
+		prCommits = append(prCommits, types.PRCommit{
+			Sha1:   dcCommit.ID,
+			Author: dcCommit.Author.Name,
+			// commits without a linked user only have the git author name and email
+			AuthorUnresolved: dcCommit.Author.Slug == "",
+			Timestamp:        dcCommit.CommitterTimestamp / 1000,
+			PushedAt:         pushedAt[dcCommit.ID],
+		})
+	}
+	evidence.Summarize(prCommits, pr.FromRef.LatestCommit)
+	return evidence
+}
+
+func hasReviewFrom(reviews []types.PRReview, reviewer string) bool {
+	for _, review := range reviews {
+		if types.EqualIdentity(review.Reviewer, reviewer) {
+			return true
+		}
+	}
+	return false
+}
+
+type dcPage struct {
+	Values        json.RawMessage `json:"values"`
+	IsLastPage    bool            `json:"isLastPage"`
+	NextPageStart *int            `json:"nextPageStart"`
+}
+
+type dcUser struct {
+	// Name is the user name, or the git author name of a commit without a linked user
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	DisplayName string `json:"displayName"`
+}
+
+type dcPullRequest struct {
+	ID     int    `json:"id"`
+	State  string `json:"state"`
+	Author struct {
+		User dcUser `json:"user"`
+	} `json:"author"`
+	Reviewers []dcReviewer `json:"reviewers"`
+	FromRef   struct {
+		LatestCommit string `json:"latestCommit"`
+	} `json:"fromRef"`
+	Properties struct {
+		MergeCommit struct {
+			ID string `json:"id"`
+		} `json:"mergeCommit"`
+	} `json:"properties"`
+	Links struct {
+		Self []struct {
+			Href string `json:"href"`
+		} `json:"self"`
+	} `json:"links"`
+}
+
+type dcReviewer struct {
+	User     dcUser `json:"user"`
+	Approved bool   `json:"approved"`
+	// Status is one of APPROVED, NEEDS_WORK or UNAPPROVED
+	Status string `json:"status"`
+}
+
+type dcActivity struct {
+	// CreatedDate is in unix milliseconds
+	CreatedDate int64  `json:"createdDate"`
+	User        dcUser `json:"user"`
+	Action      string `json:"action"`
+	// FromHash is the head commit of the pull request after a RESCOPED activity
+	FromHash string `json:"fromHash"`
+}
+
+type dcCommit struct {
+	ID string `json:"id"`
+	// Author is the linked Data Center user, or only the git author name and email
+	Author dcUser `json:"author"`
+	// CommitterTimestamp is in unix milliseconds
+	CommitterTimestamp int64 `json:"committerTimestamp"`
+}
@@ -0,0 +1,121 @@
+package changelog
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/kosli-dev/cli/internal/gitview"
+	"github.com/kosli-dev/cli/internal/logger"
+)
+
+// pullRequestPatterns match the pull request numbers in the messages of the commits made when merging
+// pull requests in GitHub, GitLab, Bitbucket and Azure DevOps
+var pullRequestPatterns = []*regexp.Regexp{
+	// GitHub merge commit
+	regexp.MustCompile(`^Merge pull request #([0-9]+)`),
+	// GitHub squash and merge, the pull request number ends the first line
+	regexp.MustCompile(`^[^\n]*\(#([0-9]+)\)\s*(?:\n|$)`),
+	// GitLab merge commit
+	regexp.MustCompile(`(?m)^See merge request \S*!([0-9]+)`),
+	// Bitbucket merge commit
+	regexp.MustCompile(`^Merged in \S+ \(pull request #([0-9]+)\)`),
+	// Azure DevOps merge commit
+	regexp.MustCompile(`^Merged PR ([0-9]+):`),
+}
+
+// Changelog is the list of commits between two commits, with a summary of their authors,
+// the paths they changed and the pull requests and issues they refer to
+type Changelog struct {
+	// OldestCommit is excluded from the changelog. It is empty when the changelog is only the newest commit.
+	OldestCommit string `json:"oldest_commit,omitempty"`
+	NewestCommit string `json:"newest_commit"`
+	// PathFilters are the paths to which the changelog is scoped, it is empty when it is not scoped
+	PathFilters     []string  `json:"path_filters,omitempty"`
+	Commits         []*Commit `json:"commits"`
+	Authors         []string  `json:"authors"`
+	Paths           []string  `json:"paths"`
+	PullRequests    []string  `json:"pull_requests"`
+	IssueReferences []string  `json:"issue_references"`
+}
+
+// Commit is a commit of a changelog
+type Commit struct {
+	gitview.CommitInfo
+	Paths           []string `json:"paths"`
+	PullRequests    []string `json:"pull_requests"`
+	IssueReferences []string `json:"issue_references"`
+}
+
+// New returns the changelog of the commits between oldest (excluded) and newest (included), newest first.
+// When oldest is empty, the changelog is only the newest commit. When paths are given, only the commits
+// changing files matching the paths are in the changelog. The issue references in the commit messages are
+// the matches of issuePattern.
+func New(gv *gitview.GitView, oldest, newest string, paths []string, issuePattern string, logger *logger.Logger) (*Changelog, error) {
+	issueRegex, err := regexp.Compile(issuePattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []*gitview.CommitInfo
+	if oldest == "" {
+		commits, err = gv.CommitsBetween(newest, newest, paths, logger)
+	} else {
+		commits, err = gv.CommitsBetween(oldest, newest, paths, logger)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changelog := &Changelog{OldestCommit: oldest, NewestCommit: newest, PathFilters: paths, Commits: []*Commit{}}
+	authors, paths, pullRequests, issueReferences := []string{}, []string{}, []string{}, []string{}
+	for _, commitInfo := range commits {
+		commit := &Commit{
+			CommitInfo:      *commitInfo,
+			PullRequests:    PullRequestReferences(commitInfo.Message),
+			IssueReferences: uniqueSorted(issueRegex.FindAllString(commitInfo.Message, -1)),
+		}
+		commit.Paths, err = gv.ChangedPaths(commitInfo.Sha1)
+		if err != nil {
+			return nil, err
+		}
+		changelog.Commits = append(changelog.Commits, commit)
+
+		authors = append(authors, commit.Author)
+		paths = append(paths, commit.Paths...)
+		pullRequests = append(pullRequests, commit.PullRequests...)
+		issueReferences = append(issueReferences, commit.IssueReferences...)
+	}
+	changelog.Authors = uniqueSorted(authors)
+	changelog.Paths = uniqueSorted(paths)
+	changelog.PullRequests = uniqueSorted(pullRequests)
+	changelog.IssueReferences = uniqueSorted(issueReferences)
+
+	logger.Debug("changelog between %s and %s has %d commit(s) by %d author(s), changing %d path(s)",
+		oldest, newest, len(changelog.Commits), len(changelog.Authors), len(changelog.Paths))
+	return changelog, nil
+}
+
+// PullRequestReferences returns the numbers of the pull requests which a commit message shows the commit merged
+func PullRequestReferences(message string) []string {
+	numbers := []string{}
+	for _, pattern := range pullRequestPatterns {
+		for _, match := range pattern.FindAllStringSubmatch(message, -1) {
+			numbers = append(numbers, match[1])
+		}
+	}
+	return uniqueSorted(numbers)
+}
+
+// uniqueSorted returns the sorted values without duplicates
+func uniqueSorted(values []string) []string {
+	unique := make(map[string]struct{})
+	for _, value := range values {
+		unique[value] = struct{}{}
+	}
+	result := make([]string, 0, len(unique))
+	for value := range unique {
+		result = append(result, value)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,109 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/go-git/go-billy/v5/util"
+	git "github.com/go-git/go-git/v5"
+	"github.com/kosli-dev/cli/internal/gitview"
+	"github.com/kosli-dev/cli/internal/jira"
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/kosli-dev/cli/internal/testHelpers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestReferences(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{
+			name:    "GitHub merge commit",
+			message: "Merge pull request #42 from acme/feature\n\nEX-1 add basket",
+			want:    []string{"42"},
+		},
+		{
+			name:    "GitHub squash and merge commit",
+			message: "EX-1 add basket (#42)\n\n* add basket\n* fix typo (#7 is unrelated)",
+			want:    []string{"42"},
+		},
+		{
+			name:    "GitLab merge commit",
+			message: "Merge branch 'feature' into 'main'\n\nAdd basket\n\nSee merge request acme/shop!42",
+			want:    []string{"42"},
+		},
+		{
+			name:    "Bitbucket merge commit",
+			message: "Merged in feature/basket (pull request #42)\n\nAdd basket",
+			want:    []string{"42"},
+		},
+		{
+			name:    "Azure DevOps merge commit",
+			message: "Merged PR 42: Add basket",
+			want:    []string{"42"},
+		},
+		{
+			name:    "a commit which is not a merge has no pull request",
+			message: "fix #42 in the basket",
+			want:    []string{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, PullRequestReferences(tt.message))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	repoDir := t.TempDir()
+	_, workTree, fs, err := testHelpers.InitializeGitRepo(repoDir)
+	require.NoError(t, err)
+
+	shas := []string{}
+	for _, commit := range []struct {
+		path    string
+		message string
+	}{
+		{path: "basket.go", message: "EX-1 add basket"},
+		{path: "README.md", message: "EX-2 EX-1 document basket (#12)"},
+		{path: "basket.go", message: "Merge pull request #13 from acme/fix\n\nfix typo"},
+	} {
+		require.NoError(t, util.WriteFile(fs, commit.path, []byte(commit.message), 0644))
+		_, err = workTree.Add(commit.path)
+		require.NoError(t, err)
+		hash, err := workTree.Commit(commit.message, &git.CommitOptions{})
+		require.NoError(t, err)
+		shas = append(shas, hash.String())
+	}
+
+	gitView, err := gitview.New(repoDir)
+	require.NoError(t, err)
+
+	changelog, err := New(gitView, shas[0], shas[2], nil, jira.IssueKeyPattern, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Equal(t, shas[0], changelog.OldestCommit)
+	require.Equal(t, shas[2], changelog.NewestCommit)
+	require.Len(t, changelog.Commits, 2)
+	require.Equal(t, shas[2], changelog.Commits[0].Sha1)
+	require.Equal(t, []string{"basket.go"}, changelog.Commits[0].Paths)
+	require.Equal(t, []string{"13"}, changelog.Commits[0].PullRequests)
+	require.Equal(t, []string{}, changelog.Commits[0].IssueReferences)
+	require.Equal(t, shas[1], changelog.Commits[1].Sha1)
+	require.Equal(t, []string{"README.md"}, changelog.Commits[1].Paths)
+	require.Equal(t, []string{"EX-1", "EX-2"}, changelog.Commits[1].IssueReferences)
+
+	require.Len(t, changelog.Authors, 1)
+	require.Equal(t, []string{"README.md", "basket.go"}, changelog.Paths)
+	require.Equal(t, []string{"12", "13"}, changelog.PullRequests)
+	require.Equal(t, []string{"EX-1", "EX-2"}, changelog.IssueReferences)
+
+	changelog, err = New(gitView, "", shas[0], nil, jira.IssueKeyPattern, logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Empty(t, changelog.OldestCommit)
+	require.Len(t, changelog.Commits, 1)
+	require.Equal(t, []string{"EX-1"}, changelog.IssueReferences)
+
+	_, err = New(gitView, "", shas[0], nil, "[", logger.NewStandardLogger())
+	require.Error(t, err)
+}
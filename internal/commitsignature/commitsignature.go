@@ -0,0 +1,118 @@
+package commitsignature
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/kosli-dev/cli/internal/gitview"
+)
+
+// Signature formats, as in git's gpg.format setting
+const (
+	FormatGPG  = "openpgp"
+	FormatSSH  = "ssh"
+	FormatX509 = "x509"
+)
+
+// Verification statuses of a commit
+const (
+	// StatusVerified is for a commit with a valid signature from a trusted key
+	StatusVerified = "verified"
+	// StatusUnsigned is for a commit without a signature
+	StatusUnsigned = "unsigned"
+	// StatusUntrusted is for a commit signed with a key which is not in the keyring or allowed signers
+	StatusUntrusted = "untrusted"
+	// StatusInvalid is for a commit whose signature does not verify, e.g. because the commit was modified after signing
+	StatusInvalid = "invalid"
+)
+
+// Verifier verifies commit signatures against a GPG keyring and/or an SSH allowed signers file
+type Verifier struct {
+	KeyRing        openpgp.EntityList
+	AllowedSigners []*AllowedSigner
+}
+
+// CommitSignature is the verification result of the signature of a commit
+type CommitSignature struct {
+	Sha1   string `json:"sha1"`
+	Author string `json:"author"`
+	Status string `json:"status"`
+	// Format is empty when the commit is not signed
+	Format string `json:"format,omitempty"`
+	// Signer is the identity of the trusted key: the user ID of a GPG key, or the principals of an SSH key
+	Signer string `json:"signer,omitempty"`
+	// KeyID is the fingerprint of the signing key, when it can be found from the signature
+	KeyID string `json:"key_id,omitempty"`
+	// Reason explains why the signature is not verified
+	Reason string `json:"reason,omitempty"`
+}
+
+// Results are the verification results of the signatures of a range of commits
+type Results struct {
+	Commits          []*CommitSignature `json:"commits"`
+	UnsignedCommits  []string           `json:"unsigned_commits"`
+	UntrustedCommits []string           `json:"untrusted_commits"`
+}
+
+// Compliant is true when there are commits and all of them have verified signatures
+func (r *Results) Compliant() bool {
+	return len(r.Commits) > 0 && len(r.UnsignedCommits) == 0 && len(r.UntrustedCommits) == 0
+}
+
+// VerifyCommits verifies the signature of each commit. Commits with an invalid signature are
+// reported along with the untrusted ones.
+func (v *Verifier) VerifyCommits(commits []*gitview.SignedCommit) *Results {
+	results := &Results{
+		Commits:          []*CommitSignature{},
+		UnsignedCommits:  []string{},
+		UntrustedCommits: []string{},
+	}
+	for _, commit := range commits {
+		result := v.Verify(commit)
+		results.Commits = append(results.Commits, result)
+		switch result.Status {
+		case StatusUnsigned:
+			results.UnsignedCommits = append(results.UnsignedCommits, commit.Sha1)
+		case StatusUntrusted, StatusInvalid:
+			results.UntrustedCommits = append(results.UntrustedCommits, commit.Sha1)
+		}
+	}
+	return results
+}
+
+// Verify verifies the signature of a commit
+func (v *Verifier) Verify(commit *gitview.SignedCommit) *CommitSignature {
+	result := &CommitSignature{Sha1: commit.Sha1, Author: commit.Author}
+	signature := strings.TrimSpace(commit.Signature)
+	switch {
+	case signature == "":
+		result.Status = StatusUnsigned
+	case strings.HasPrefix(signature, sshSignatureArmorStart):
+		result.Format = FormatSSH
+		v.verifySSH(commit, result)
+	case strings.HasPrefix(signature, "-----BEGIN PGP SIGNATURE-----"):
+		result.Format = FormatGPG
+		v.verifyGPG(commit, result)
+	case strings.HasPrefix(signature, "-----BEGIN SIGNED MESSAGE-----"):
+		result.Format = FormatX509
+		result.Status = StatusUntrusted
+		result.Reason = "x509 signatures are not supported"
+	default:
+		result.Status = StatusInvalid
+		result.Reason = "the signature format is not recognized"
+	}
+	return result
+}
+
+// untrusted marks the result as untrusted for the given reason
+func (r *CommitSignature) untrusted(format string, a ...interface{}) {
+	r.Status = StatusUntrusted
+	r.Reason = fmt.Sprintf(format, a...)
+}
+
+// invalid marks the result as invalid for the given reason
+func (r *CommitSignature) invalid(format string, a ...interface{}) {
+	r.Status = StatusInvalid
+	r.Reason = fmt.Sprintf(format, a...)
+}
@@ -0,0 +1,310 @@
+package commitsignature
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/kosli-dev/cli/internal/gitview"
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/kosli-dev/cli/internal/testHelpers"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// sshCommitSigner signs commits with an SSH key, as git does with gpg.format=ssh
+type sshCommitSigner struct {
+	signer    ssh.Signer
+	namespace string
+}
+
+func (s *sshCommitSigner) Sign(message io.Reader) ([]byte, error) {
+	content, err := io.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha512.Sum512(content)
+	signedData := sshSignedData{Namespace: s.namespace, HashAlgorithm: "sha512", Hash: hash[:]}
+	copy(signedData.Magic[:], sshSignatureMagic)
+	signature, err := s.signer.Sign(rand.Reader, ssh.Marshal(signedData))
+	if err != nil {
+		return nil, err
+	}
+	blob := sshSignature{
+		Version:       1,
+		PublicKey:     s.signer.PublicKey().Marshal(),
+		Namespace:     s.namespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(signature),
+	}
+	copy(blob.Magic[:], sshSignatureMagic)
+	encoded := base64.StdEncoding.EncodeToString(ssh.Marshal(blob))
+	lines := []string{sshSignatureArmorStart}
+	for len(encoded) > 70 {
+		lines = append(lines, encoded[:70])
+		encoded = encoded[70:]
+	}
+	lines = append(lines, encoded, sshSignatureArmorEnd)
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+func newSSHSigner(t *testing.T) ssh.Signer {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	require.NoError(t, err)
+	return signer
+}
+
+func newGPGEntity(t *testing.T, name string) *openpgp.Entity {
+	entity, err := openpgp.NewEntity(name, "", strings.ToLower(name)+"@example.com", nil)
+	require.NoError(t, err)
+	return entity
+}
+
+// writeFile writes content to a file in a temporary directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func writeGPGKeyRing(t *testing.T, entities ...*openpgp.Entity) string {
+	var content strings.Builder
+	writer, err := armor.Encode(&content, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	for _, entity := range entities {
+		require.NoError(t, entity.Serialize(writer))
+	}
+	require.NoError(t, writer.Close())
+	return writeFile(t, "keyring.asc", content.String())
+}
+
+func authorizedKey(signer ssh.Signer) string {
+	return strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+}
+
+func TestVerifyCommits(t *testing.T) {
+	trustedGPG := newGPGEntity(t, "Alice")
+	otherGPG := newGPGEntity(t, "Mallory")
+	trustedSSH := newSSHSigner(t)
+	expiredSSH := newSSHSigner(t)
+	otherSSH := newSSHSigner(t)
+
+	keyRing, err := LoadGPGKeyRing(writeGPGKeyRing(t, trustedGPG))
+	require.NoError(t, err)
+	allowedSigners, err := LoadAllowedSigners(writeFile(t, "allowed_signers", strings.Join([]string{
+		"# the signers of the release",
+		"bob@example.com namespaces=\"git\" " + authorizedKey(trustedSSH) + " bob's laptop",
+		"carol@example.com valid-before=\"20200101Z\" " + authorizedKey(expiredSSH),
+	}, "\n")))
+	require.NoError(t, err)
+	verifier := &Verifier{KeyRing: keyRing, AllowedSigners: allowedSigners}
+
+	repoDir := t.TempDir()
+	_, workTree, fs, err := testHelpers.InitializeGitRepo(repoDir)
+	require.NoError(t, err)
+	author := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+	commits := []struct {
+		name       string
+		options    *git.CommitOptions
+		wantStatus string
+		wantFormat string
+		wantSigner string
+		wantReason string
+	}{
+		{
+			name:       "a commit signed with a GPG key of the keyring is verified",
+			options:    &git.CommitOptions{Author: author, SignKey: trustedGPG},
+			wantStatus: StatusVerified,
+			wantFormat: FormatGPG,
+			wantSigner: "Alice <alice@example.com>",
+		},
+		{
+			name:       "a commit signed with a GPG key which is not in the keyring is untrusted",
+			options:    &git.CommitOptions{Author: author, SignKey: otherGPG},
+			wantStatus: StatusUntrusted,
+			wantFormat: FormatGPG,
+			wantReason: "the signing key is not in the GPG keyring",
+		},
+		{
+			name:       "a commit signed with an allowed SSH key is verified",
+			options:    &git.CommitOptions{Author: author, Signer: &sshCommitSigner{signer: trustedSSH, namespace: "git"}},
+			wantStatus: StatusVerified,
+			wantFormat: FormatSSH,
+			wantSigner: "bob@example.com",
+		},
+		{
+			name:       "a commit signed with an SSH key which is not allowed is untrusted",
+			options:    &git.CommitOptions{Author: author, Signer: &sshCommitSigner{signer: otherSSH, namespace: "git"}},
+			wantStatus: StatusUntrusted,
+			wantFormat: FormatSSH,
+			wantReason: "the signing key is not in the allowed signers",
+		},
+		{
+			name:       "a commit signed with an SSH key after it is valid is untrusted",
+			options:    &git.CommitOptions{Author: author, Signer: &sshCommitSigner{signer: expiredSSH, namespace: "git"}},
+			wantStatus: StatusUntrusted,
+			wantFormat: FormatSSH,
+			wantReason: "the signing key is not allowed to sign git commits at ",
+		},
+		{
+			name:       "a commit signed with an SSH key in another namespace is invalid",
+			options:    &git.CommitOptions{Author: author, Signer: &sshCommitSigner{signer: trustedSSH, namespace: "file"}},
+			wantStatus: StatusInvalid,
+			wantFormat: FormatSSH,
+			wantReason: "the SSH signature is in namespace 'file' instead of 'git'",
+		},
+		{
+			name:       "a commit which is not signed is unsigned",
+			options:    &git.CommitOptions{Author: author},
+			wantStatus: StatusUnsigned,
+		},
+	}
+	for _, commit := range commits {
+		_, err := testHelpers.CommitToRepoWithOptions(workTree, fs, commit.name, commit.options)
+		require.NoError(t, err)
+	}
+
+	gitView, err := gitview.New(repoDir)
+	require.NoError(t, err)
+	signedCommits, err := gitView.SignedCommitsBetween("HEAD~7", "HEAD", logger.NewStandardLogger())
+	require.NoError(t, err)
+	require.Len(t, signedCommits, len(commits))
+
+	results := verifier.VerifyCommits(signedCommits)
+	require.False(t, results.Compliant())
+	require.Len(t, results.Commits, len(commits))
+	// the commits are newest first
+	for i, commit := range commits {
+		t.Run(commit.name, func(t *testing.T) {
+			result := results.Commits[len(commits)-1-i]
+			require.Equal(t, commit.wantStatus, result.Status)
+			require.Equal(t, commit.wantFormat, result.Format)
+			require.Equal(t, commit.wantSigner, result.Signer)
+			require.True(t, strings.HasPrefix(result.Reason, commit.wantReason), result.Reason)
+			if commit.wantFormat != "" {
+				require.NotEmpty(t, result.KeyID)
+			}
+		})
+	}
+	require.Equal(t, []string{signedCommits[0].Sha1}, results.UnsignedCommits)
+	require.Len(t, results.UntrustedCommits, 4)
+
+	t.Run("all commits of a range with verified signatures are compliant", func(t *testing.T) {
+		results := verifier.VerifyCommits([]*gitview.SignedCommit{signedCommits[4], signedCommits[6]})
+		require.True(t, results.Compliant())
+		require.Empty(t, results.UnsignedCommits)
+		require.Empty(t, results.UntrustedCommits)
+	})
+
+	t.Run("a signed commit which was modified is invalid", func(t *testing.T) {
+		for _, signedCommit := range []*gitview.SignedCommit{signedCommits[4], signedCommits[6]} {
+			modified := *signedCommit
+			modified.Payload = append([]byte{}, signedCommit.Payload...)
+			modified.Payload = append(modified.Payload, []byte("modified")...)
+			result := verifier.Verify(&modified)
+			require.Equal(t, StatusInvalid, result.Status)
+		}
+	})
+
+	t.Run("signatures are untrusted without keys of their format", func(t *testing.T) {
+		results := (&Verifier{}).VerifyCommits([]*gitview.SignedCommit{signedCommits[4], signedCommits[6]})
+		require.Equal(t, "no allowed signers are given to verify SSH signatures", results.Commits[0].Reason)
+		require.Equal(t, "no GPG keyring is given to verify GPG signatures", results.Commits[1].Reason)
+		require.False(t, results.Compliant())
+	})
+
+	t.Run("no commits are not compliant", func(t *testing.T) {
+		require.False(t, verifier.VerifyCommits([]*gitview.SignedCommit{}).Compliant())
+	})
+}
+
+func TestLoadAllowedSigners(t *testing.T) {
+	key := authorizedKey(newSSHSigner(t))
+	for _, tt := range []struct {
+		name           string
+		content        string
+		wantSigners    int
+		wantNamespaces []string
+		wantValidAfter time.Time
+		wantErrorRegex string
+	}{
+		{
+			name:        "signers are read, ignoring comments and empty lines",
+			content:     "# signers\n\nalice@example.com " + key + "\nbob@example.com,carol@example.com " + key + " comment\n",
+			wantSigners: 2,
+		},
+		{
+			name:           "the options of a signer are read",
+			content:        `alice@example.com namespaces="git,file",valid-after="20240131Z" ` + key,
+			wantSigners:    1,
+			wantNamespaces: []string{"git", "file"},
+			wantValidAfter: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:           "a certificate authority is an error",
+			content:        "*@example.com cert-authority " + key,
+			wantErrorRegex: "^invalid line 1 of allowed signers file .*: certificate authorities are not supported$",
+		},
+		{
+			name:           "an invalid time is an error",
+			content:        `alice@example.com valid-before="2024-01-31" ` + key,
+			wantErrorRegex: "^invalid line 1 of allowed signers file .*: invalid time 2024-01-31. It must be of the form YYYYMMDD\\[HHMM\\[SS\\]\\]\\[Z\\]$",
+		},
+		{
+			name:           "a line without a key is an error",
+			content:        "# signers\nalice@example.com",
+			wantErrorRegex: "^invalid line 2 of allowed signers file .*: it must be of the form: principals \\[options\\] keytype base64-key \\[comment\\]$",
+		},
+		{
+			name:           "a file without signers is an error",
+			content:        "# no signers yet\n",
+			wantErrorRegex: "^allowed signers file .* has no signers$",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			signers, err := LoadAllowedSigners(writeFile(t, "allowed_signers", tt.content))
+			if tt.wantErrorRegex != "" {
+				require.Error(t, err)
+				require.Regexp(t, tt.wantErrorRegex, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, signers, tt.wantSigners)
+			require.Equal(t, tt.wantNamespaces, signers[0].Namespaces)
+			require.True(t, tt.wantValidAfter.Equal(signers[0].ValidAfter))
+		})
+	}
+}
+
+func TestLoadGPGKeyRing(t *testing.T) {
+	entity := newGPGEntity(t, "Alice")
+	keyRing, err := LoadGPGKeyRing(writeGPGKeyRing(t, entity))
+	require.NoError(t, err)
+	require.Len(t, keyRing, 1)
+	require.Equal(t, entity.PrimaryKey.Fingerprint, keyRing[0].PrimaryKey.Fingerprint)
+
+	var binary strings.Builder
+	require.NoError(t, entity.Serialize(&binary))
+	keyRing, err = LoadGPGKeyRing(writeFile(t, "keyring.gpg", binary.String()))
+	require.NoError(t, err)
+	require.Len(t, keyRing, 1)
+
+	_, err = LoadGPGKeyRing(writeFile(t, "keyring.asc", "not a keyring"))
+	require.Error(t, err)
+
+	_, err = LoadGPGKeyRing(filepath.Join(t.TempDir(), "missing.asc"))
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package commitsignature
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/kosli-dev/cli/internal/gitview"
+)
+
+// LoadGPGKeyRing reads the public keys of an armored or binary GPG keyring,
+// e.g. exported with 'gpg --export --armor'
+func LoadGPGKeyRing(file string) (openpgp.EntityList, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GPG keyring %s: %v", file, err)
+	}
+	var keyRing openpgp.EntityList
+	if bytes.Contains(content, []byte("-----BEGIN PGP")) {
+		keyRing, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(content))
+	} else {
+		keyRing, err = openpgp.ReadKeyRing(bytes.NewReader(content))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GPG keyring %s: %v", file, err)
+	}
+	if len(keyRing) == 0 {
+		return nil, fmt.Errorf("GPG keyring %s has no keys", file)
+	}
+	return keyRing, nil
+}
+
+// verifyGPG verifies a GPG signature at the commit time, so that keys which expired after the commit are still valid
+func (v *Verifier) verifyGPG(commit *gitview.SignedCommit, result *CommitSignature) {
+	result.KeyID = gpgSignatureKeyID(commit.Signature)
+	if len(v.KeyRing) == 0 {
+		result.untrusted("no GPG keyring is given to verify GPG signatures")
+		return
+	}
+
+	config := &packet.Config{Time: func() time.Time { return commit.CommitTime }}
+	signer, err := openpgp.CheckArmoredDetachedSignature(v.KeyRing, bytes.NewReader(commit.Payload),
+		strings.NewReader(commit.Signature), config)
+	switch {
+	case errors.Is(err, pgperrors.ErrUnknownIssuer):
+		result.untrusted("the signing key is not in the GPG keyring")
+	case err != nil:
+		result.invalid("the GPG signature does not verify: %v", err)
+	default:
+		result.Status = StatusVerified
+		result.KeyID = fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint)
+		if identity := signer.PrimaryIdentity(); identity != nil {
+			result.Signer = identity.Name
+		}
+	}
+}
+
+// gpgSignatureKeyID returns the ID of the key which made a GPG signature, or its fingerprint when it is recorded
+func gpgSignatureKeyID(signature string) string {
+	block, err := armor.Decode(strings.NewReader(signature))
+	if err != nil {
+		return ""
+	}
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return ""
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok {
+		return ""
+	}
+	if len(sig.IssuerFingerprint) > 0 {
+		return fmt.Sprintf("%X", sig.IssuerFingerprint)
+	}
+	if sig.IssuerKeyId != nil {
+		return fmt.Sprintf("%016X", *sig.IssuerKeyId)
+	}
+	return ""
+}
@@ -0,0 +1,255 @@
+package commitsignature
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/gitview"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	sshSignatureArmorStart = "-----BEGIN SSH SIGNATURE-----"
+	sshSignatureArmorEnd   = "-----END SSH SIGNATURE-----"
+	sshSignatureMagic      = "SSHSIG"
+	// gitNamespace is the namespace of the SSH signatures made by git
+	gitNamespace = "git"
+)
+
+// AllowedSigner is an entry of an SSH allowed signers file, as used by git's gpg.ssh.allowedSignersFile
+type AllowedSigner struct {
+	Principals string
+	Key        ssh.PublicKey
+	// Namespaces are the namespaces the key may sign in, any namespace when empty
+	Namespaces []string
+	// ValidAfter and ValidBefore limit the time at which the key may sign, when set
+	ValidAfter  time.Time
+	ValidBefore time.Time
+}
+
+// sshSignature is the blob of an SSH signature, see PROTOCOL.sshsig in OpenSSH
+type sshSignature struct {
+	Magic         [6]byte
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData is the data signed by the key of an SSH signature
+type sshSignedData struct {
+	Magic         [6]byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// LoadAllowedSigners reads an SSH allowed signers file. Each line is of the form:
+// principals [options] keytype base64-key [comment]
+func LoadAllowedSigners(file string) ([]*AllowedSigner, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowed signers file %s: %v", file, err)
+	}
+	signers := []*AllowedSigner{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		signer, err := parseAllowedSigner(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d of allowed signers file %s: %v", lineNumber, file, err)
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("allowed signers file %s has no signers", file)
+	}
+	return signers, nil
+}
+
+func parseAllowedSigner(line string) (*AllowedSigner, error) {
+	principals, rest, found := strings.Cut(line, " ")
+	if !found {
+		return nil, fmt.Errorf("it must be of the form: principals [options] keytype base64-key [comment]")
+	}
+	key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(rest)))
+	if err != nil {
+		return nil, err
+	}
+	signer := &AllowedSigner{Principals: principals, Key: key}
+	for _, option := range options {
+		name, value, _ := strings.Cut(option, "=")
+		value = strings.Trim(value, `"`)
+		switch strings.ToLower(name) {
+		case "namespaces":
+			signer.Namespaces = strings.Split(value, ",")
+		case "valid-after":
+			signer.ValidAfter, err = parseSignerTime(value)
+		case "valid-before":
+			signer.ValidBefore, err = parseSignerTime(value)
+		case "cert-authority":
+			return nil, fmt.Errorf("certificate authorities are not supported")
+		default:
+			return nil, fmt.Errorf("unknown option %s", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return signer, nil
+}
+
+// parseSignerTime parses the time of the valid-after and valid-before options, of the form
+// YYYYMMDD[HHMM[SS]] in local time, or in UTC with a Z suffix
+func parseSignerTime(value string) (time.Time, error) {
+	location := time.Local
+	if strings.HasSuffix(value, "Z") {
+		location = time.UTC
+		value = strings.TrimSuffix(value, "Z")
+	}
+	for _, layout := range []string{"20060102", "200601021504", "20060102150405"} {
+		if len(value) == len(layout) {
+			return time.ParseInLocation(layout, value, location)
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %s. It must be of the form YYYYMMDD[HHMM[SS]][Z]", value)
+}
+
+// allowsAt is true when the signer may sign in the git namespace at the given time
+func (s *AllowedSigner) allowsAt(when time.Time) bool {
+	if len(s.Namespaces) > 0 && !containsNamespace(s.Namespaces, gitNamespace) {
+		return false
+	}
+	if !s.ValidAfter.IsZero() && when.Before(s.ValidAfter) {
+		return false
+	}
+	if !s.ValidBefore.IsZero() && !when.Before(s.ValidBefore) {
+		return false
+	}
+	return true
+}
+
+func containsNamespace(namespaces []string, namespace string) bool {
+	for _, n := range namespaces {
+		if strings.TrimSpace(n) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// verifySSH verifies an SSH signature and checks that its key is an allowed signer at the commit time
+func (v *Verifier) verifySSH(commit *gitview.SignedCommit, result *CommitSignature) {
+	sig, key, err := parseSSHSignature(commit.Signature)
+	if err != nil {
+		result.invalid("the SSH signature cannot be parsed: %v", err)
+		return
+	}
+	result.KeyID = ssh.FingerprintSHA256(key)
+
+	if sig.Namespace != gitNamespace {
+		result.invalid("the SSH signature is in namespace '%s' instead of '%s'", sig.Namespace, gitNamespace)
+		return
+	}
+	err = verifySSHSignature(sig, key, commit.Payload)
+	if err != nil {
+		result.invalid("the SSH signature does not verify: %v", err)
+		return
+	}
+
+	if len(v.AllowedSigners) == 0 {
+		result.untrusted("no allowed signers are given to verify SSH signatures")
+		return
+	}
+	keyFound := false
+	for _, signer := range v.AllowedSigners {
+		if !bytes.Equal(signer.Key.Marshal(), key.Marshal()) {
+			continue
+		}
+		keyFound = true
+		if signer.allowsAt(commit.CommitTime) {
+			result.Status = StatusVerified
+			result.Signer = signer.Principals
+			return
+		}
+	}
+	if keyFound {
+		result.untrusted("the signing key is not allowed to sign git commits at %s", commit.CommitTime.UTC().Format(time.RFC3339))
+		return
+	}
+	result.untrusted("the signing key is not in the allowed signers")
+}
+
+// parseSSHSignature decodes an armored SSH signature and the public key it was made with
+func parseSSHSignature(armored string) (*sshSignature, ssh.PublicKey, error) {
+	body := strings.TrimSpace(armored)
+	if !strings.HasPrefix(body, sshSignatureArmorStart) || !strings.HasSuffix(body, sshSignatureArmorEnd) {
+		return nil, nil, fmt.Errorf("missing armor")
+	}
+	body = strings.TrimSuffix(strings.TrimPrefix(body, sshSignatureArmorStart), sshSignatureArmorEnd)
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sig := &sshSignature{}
+	err = ssh.Unmarshal(blob, sig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if string(sig.Magic[:]) != sshSignatureMagic {
+		return nil, nil, fmt.Errorf("invalid magic preamble")
+	}
+	if sig.Version != 1 {
+		return nil, nil, fmt.Errorf("unsupported version %d", sig.Version)
+	}
+	key, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sig, key, nil
+}
+
+// verifySSHSignature verifies that the signature was made over the message by the key
+func verifySSHSignature(sig *sshSignature, key ssh.PublicKey, message []byte) error {
+	var hash []byte
+	switch sig.HashAlgorithm {
+	case "sha256":
+		digest := sha256.Sum256(message)
+		hash = digest[:]
+	case "sha512":
+		digest := sha512.Sum512(message)
+		hash = digest[:]
+	default:
+		return fmt.Errorf("unsupported hash algorithm %s", sig.HashAlgorithm)
+	}
+
+	signature := &ssh.Signature{}
+	err := ssh.Unmarshal(sig.Signature, signature)
+	if err != nil {
+		return err
+	}
+	signedData := sshSignedData{
+		Namespace:     sig.Namespace,
+		Reserved:      sig.Reserved,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          hash,
+	}
+	copy(signedData.Magic[:], sshSignatureMagic)
+	return key.Verify(ssh.Marshal(signedData), signature)
+}
@@ -0,0 +1,65 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+type coberturaReport struct {
+	XMLName  xml.Name `xml:"coverage"`
+	Packages []struct {
+		Name    string `xml:"name,attr"`
+		Classes []struct {
+			Filename string `xml:"filename,attr"`
+			Lines    []struct {
+				Number            int    `xml:"number,attr"`
+				Hits              int64  `xml:"hits,attr"`
+				Branch            bool   `xml:"branch,attr"`
+				ConditionCoverage string `xml:"condition-coverage,attr"`
+			} `xml:"lines>line"`
+		} `xml:"classes>class"`
+	} `xml:"packages>package"`
+}
+
+// conditionCoverage matches e.g. "50% (1/2)"
+var conditionCoverage = regexp.MustCompile(`\((\d+)/(\d+)\)`)
+
+func parseCobertura(content []byte) (map[string]*PackageCoverage, error) {
+	var report coberturaReport
+	if err := xml.Unmarshal(content, &report); err != nil {
+		return nil, err
+	}
+
+	packages := map[string]*PackageCoverage{}
+	for _, p := range report.Packages {
+		files := fileCollector{}
+		for _, class := range p.Classes {
+			file := files.file(class.Filename)
+			for _, line := range class.Lines {
+				file.line(line.Number, line.Hits > 0)
+				if !line.Branch || line.ConditionCoverage == "" {
+					continue
+				}
+				match := conditionCoverage.FindStringSubmatch(line.ConditionCoverage)
+				if match == nil {
+					return nil, fmt.Errorf("invalid condition-coverage %q on line %d of %s", line.ConditionCoverage, line.Number, class.Filename)
+				}
+				covered, _ := strconv.Atoi(match[1])
+				total, _ := strconv.Atoi(match[2])
+				// only the number of covered conditions is known, so conditions
+				// are numbered to merge lines which are reported more than once
+				for i := 0; i < total; i++ {
+					file.branch(fmt.Sprintf("%d#%d", line.Number, i), i < covered)
+				}
+			}
+		}
+		pkg := packageFor(packages, p.Name)
+		for _, counted := range files.packages(func(string) string { return p.Name }) {
+			pkg.Lines.add(counted.Lines)
+			pkg.Branches.add(counted.Branches)
+		}
+	}
+	return packages, nil
+}
@@ -0,0 +1,272 @@
+package coverage
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatCobertura = "cobertura"
+	FormatLcov      = "lcov"
+	FormatJacoco    = "jacoco"
+	FormatGo        = "go"
+
+	ScopeOverall = "overall"
+	MetricLine   = "line"
+	MetricBranch = "branch"
+)
+
+var AllowedFormats = []string{FormatCobertura, FormatLcov, FormatJacoco, FormatGo}
+
+// Counter is the coverage of one metric. Percent is nil when
+// the report has no data for the metric, e.g. branches in Go coverprofiles
+type Counter struct {
+	Covered int      `json:"covered"`
+	Total   int      `json:"total"`
+	Percent *float64 `json:"percent,omitempty"`
+}
+
+type PackageCoverage struct {
+	Name     string  `json:"name"`
+	Lines    Counter `json:"lines"`
+	Branches Counter `json:"branches"`
+}
+
+// Drop is a coverage decrease compared to a baseline report, in percentage points
+type Drop struct {
+	Scope    string  `json:"scope"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+	Drop     float64 `json:"drop"`
+}
+
+type BaselineComparison struct {
+	File     string  `json:"file"`
+	Lines    Counter `json:"lines"`
+	Branches Counter `json:"branches"`
+	Drops    []Drop  `json:"drops,omitempty"`
+}
+
+// Thresholds decide the compliance of a coverage report. Zero values are not checked.
+type Thresholds struct {
+	MinLine   float64 `json:"min_line,omitempty"`
+	MinBranch float64 `json:"min_branch,omitempty"`
+	MaxDrop   float64 `json:"max_drop,omitempty"`
+}
+
+type CoverageData struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Format        string              `json:"format"`
+	Lines         Counter             `json:"lines"`
+	Branches      Counter             `json:"branches"`
+	Packages      []PackageCoverage   `json:"packages"`
+	Baseline      *BaselineComparison `json:"baseline,omitempty"`
+	Thresholds    Thresholds          `json:"thresholds"`
+	Violations    []string            `json:"violations,omitempty"`
+}
+
+// ValidateFormat checks that format is one of AllowedFormats
+func ValidateFormat(format string) error {
+	for _, allowed := range AllowedFormats {
+		if format == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not a valid coverage format. Allowed formats are: %s", format, strings.Join(AllowedFormats, ", "))
+}
+
+// ProcessCoverageFile parses a coverage report of the given format and
+// computes its line and branch coverage, overall and per package
+func ProcessCoverageFile(file, format string) (*CoverageData, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages map[string]*PackageCoverage
+	switch format {
+	case FormatCobertura:
+		packages, err = parseCobertura(content)
+	case FormatLcov:
+		packages, err = parseLcov(content)
+	case FormatJacoco:
+		packages, err = parseJacoco(content)
+	case FormatGo:
+		packages, err = parseGoCoverprofile(content)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s coverage report [%s]: %v", format, file, err)
+	}
+
+	data := &CoverageData{
+		SchemaVersion: 1,
+		Format:        format,
+		Packages:      []PackageCoverage{},
+	}
+	for _, pkg := range packages {
+		pkg.Lines.setPercent()
+		pkg.Branches.setPercent()
+		data.Packages = append(data.Packages, *pkg)
+		data.Lines.add(pkg.Lines)
+		data.Branches.add(pkg.Branches)
+	}
+	sort.Slice(data.Packages, func(i, j int) bool {
+		return data.Packages[i].Name < data.Packages[j].Name
+	})
+	data.Lines.setPercent()
+	data.Branches.setPercent()
+	return data, nil
+}
+
+// Compare records the coverage drops against a baseline report, overall and
+// for the packages present in both reports
+func (data *CoverageData) Compare(file string, baseline *CoverageData) {
+	comparison := &BaselineComparison{
+		File:     file,
+		Lines:    baseline.Lines,
+		Branches: baseline.Branches,
+	}
+	comparison.addDrop(ScopeOverall, MetricLine, baseline.Lines, data.Lines)
+	comparison.addDrop(ScopeOverall, MetricBranch, baseline.Branches, data.Branches)
+
+	baselinePackages := map[string]PackageCoverage{}
+	for _, pkg := range baseline.Packages {
+		baselinePackages[pkg.Name] = pkg
+	}
+	for _, pkg := range data.Packages {
+		if previous, ok := baselinePackages[pkg.Name]; ok {
+			comparison.addDrop(pkg.Name, MetricLine, previous.Lines, pkg.Lines)
+			comparison.addDrop(pkg.Name, MetricBranch, previous.Branches, pkg.Branches)
+		}
+	}
+	data.Baseline = comparison
+}
+
+// Evaluate checks the coverage against the thresholds, records the violations
+// and returns whether the coverage is compliant.
+// Only overall drops against the baseline count, package drops are informative.
+func (data *CoverageData) Evaluate(thresholds Thresholds) bool {
+	data.Thresholds = thresholds
+	data.Violations = nil
+	data.checkMinimum(MetricLine, data.Lines, thresholds.MinLine)
+	data.checkMinimum(MetricBranch, data.Branches, thresholds.MinBranch)
+	if data.Baseline != nil {
+		for _, drop := range data.Baseline.Drops {
+			if drop.Scope == ScopeOverall && drop.Drop > thresholds.MaxDrop {
+				data.Violations = append(data.Violations, fmt.Sprintf("%s coverage dropped by %.2f%% compared to the baseline (%.2f%% -> %.2f%%)",
+					drop.Metric, drop.Drop, drop.Baseline, drop.Current))
+			}
+		}
+	}
+	return len(data.Violations) == 0
+}
+
+func (data *CoverageData) checkMinimum(metric string, counter Counter, minimum float64) {
+	if minimum <= 0 {
+		return
+	}
+	if counter.Percent == nil {
+		data.Violations = append(data.Violations, fmt.Sprintf("the report has no %s coverage data to check against the minimum of %.2f%%", metric, minimum))
+	} else if *counter.Percent < minimum {
+		data.Violations = append(data.Violations, fmt.Sprintf("%s coverage %.2f%% is below the minimum of %.2f%%", metric, *counter.Percent, minimum))
+	}
+}
+
+func (comparison *BaselineComparison) addDrop(scope, metric string, baseline, current Counter) {
+	if baseline.Percent == nil || current.Percent == nil {
+		return
+	}
+	drop := round(*baseline.Percent - *current.Percent)
+	if drop > 0 {
+		comparison.Drops = append(comparison.Drops, Drop{
+			Scope:    scope,
+			Metric:   metric,
+			Baseline: *baseline.Percent,
+			Current:  *current.Percent,
+			Drop:     drop,
+		})
+	}
+}
+
+func (c *Counter) add(other Counter) {
+	c.Covered += other.Covered
+	c.Total += other.Total
+}
+
+func (c *Counter) setPercent() {
+	c.Percent = nil
+	if c.Total > 0 {
+		percent := round(float64(c.Covered) * 100 / float64(c.Total))
+		c.Percent = &percent
+	}
+}
+
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// fileCoverage collects the line hits and branch outcomes of a source file,
+// so that files reported more than once are merged instead of counted twice
+type fileCoverage struct {
+	lines    map[int]bool
+	branches map[string]bool
+}
+
+type fileCollector map[string]*fileCoverage
+
+func (fc fileCollector) file(name string) *fileCoverage {
+	if _, ok := fc[name]; !ok {
+		fc[name] = &fileCoverage{lines: map[int]bool{}, branches: map[string]bool{}}
+	}
+	return fc[name]
+}
+
+func (f *fileCoverage) line(number int, covered bool) {
+	f.lines[number] = f.lines[number] || covered
+}
+
+func (f *fileCoverage) branch(id string, covered bool) {
+	f.branches[id] = f.branches[id] || covered
+}
+
+// packages sums the file coverage per package, using packageOf to name the package of a file
+func (fc fileCollector) packages(packageOf func(file string) string) map[string]*PackageCoverage {
+	packages := map[string]*PackageCoverage{}
+	for name, file := range fc {
+		pkg := packageFor(packages, packageOf(name))
+		for _, covered := range file.lines {
+			pkg.Lines.count(covered)
+		}
+		for _, covered := range file.branches {
+			pkg.Branches.count(covered)
+		}
+	}
+	return packages
+}
+
+func packageFor(packages map[string]*PackageCoverage, name string) *PackageCoverage {
+	if _, ok := packages[name]; !ok {
+		packages[name] = &PackageCoverage{Name: name}
+	}
+	return packages[name]
+}
+
+func (c *Counter) count(covered bool) {
+	c.Total++
+	if covered {
+		c.Covered++
+	}
+}
+
+// dirOf is the package of a file for formats which do not report packages
+func dirOf(file string) string {
+	return path.Dir(strings.ReplaceAll(file, "\\", "/"))
+}
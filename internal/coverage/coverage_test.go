@@ -0,0 +1,212 @@
+package coverage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func percent(value float64) *float64 {
+	return &value
+}
+
+func counter(covered, total int, value float64) Counter {
+	return Counter{Covered: covered, Total: total, Percent: percent(value)}
+}
+
+func TestProcessCoverageFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		format   string
+		lines    Counter
+		branches Counter
+		packages []PackageCoverage
+		wantErr  string
+	}{
+		{
+			name:     "cobertura report is summarized per package, without counting method lines twice",
+			file:     "testdata/cobertura.xml",
+			format:   FormatCobertura,
+			lines:    counter(7, 10, 70),
+			branches: counter(3, 6, 50),
+			packages: []PackageCoverage{
+				{Name: "app", Lines: counter(4, 5, 80), Branches: counter(3, 4, 75)},
+				{Name: "app.utils", Lines: counter(3, 5, 60), Branches: counter(0, 2, 0)},
+			},
+		},
+		{
+			name:     "lcov report merges records of the same file and uses summaries when there are no details",
+			file:     "testdata/lcov.info",
+			format:   FormatLcov,
+			lines:    counter(12, 14, 85.71),
+			branches: counter(3, 6, 50),
+			packages: []PackageCoverage{
+				{Name: "src/app", Lines: counter(3, 4, 75), Branches: counter(1, 4, 25)},
+				{Name: "src/lib", Lines: counter(9, 10, 90), Branches: counter(2, 2, 100)},
+			},
+		},
+		{
+			name:     "jacoco report uses the package counters",
+			file:     "testdata/jacoco.xml",
+			format:   FormatJacoco,
+			lines:    counter(9, 15, 60),
+			branches: counter(3, 4, 75),
+			packages: []PackageCoverage{
+				{Name: "com.example.app", Lines: counter(4, 5, 80), Branches: counter(3, 4, 75)},
+				{Name: "com.example.util", Lines: counter(5, 10, 50)},
+			},
+		},
+		{
+			name:   "go coverprofile counts statements and merges blocks reported more than once",
+			file:   "testdata/coverage.out",
+			format: FormatGo,
+			lines:  counter(6, 10, 60),
+			packages: []PackageCoverage{
+				{Name: "github.com/example/app", Lines: counter(3, 3, 100)},
+				{Name: "github.com/example/app/internal/store", Lines: counter(3, 7, 42.86)},
+			},
+		},
+		{
+			name:    "invalid format causes an error",
+			file:    "testdata/coverage.out",
+			format:  "clover",
+			wantErr: "clover is not a valid coverage format. Allowed formats are: cobertura, lcov, jacoco, go",
+		},
+		{
+			name:    "cobertura parser fails on a report of another format",
+			file:    "testdata/jacoco.xml",
+			format:  FormatCobertura,
+			wantErr: "failed to parse cobertura coverage report [testdata/jacoco.xml]",
+		},
+		{
+			name:    "lcov parser fails on a file which is not a tracefile",
+			file:    "testdata/invalid.txt",
+			format:  FormatLcov,
+			wantErr: "invalid record on line 1: not a coverage report",
+		},
+		{
+			name:    "go parser fails on a file without a mode line",
+			file:    "testdata/lcov.info",
+			format:  FormatGo,
+			wantErr: "the first line must be the coverage mode",
+		},
+		{
+			name:    "missing file causes an error",
+			file:    "testdata/missing.out",
+			format:  FormatGo,
+			wantErr: "no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ProcessCoverageFile(tt.file, tt.format)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, data.Format)
+			assert.Equal(t, tt.lines, data.Lines)
+			assert.Equal(t, tt.branches, data.Branches)
+			assert.Equal(t, tt.packages, data.Packages)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		format     string
+		baseline   string
+		thresholds Thresholds
+		want       bool
+		violations []string
+		drops      []Drop
+	}{
+		{
+			name:   "no thresholds is compliant",
+			file:   "testdata/cobertura.xml",
+			format: FormatCobertura,
+			want:   true,
+		},
+		{
+			name:       "coverage at the minimums is compliant",
+			file:       "testdata/cobertura.xml",
+			format:     FormatCobertura,
+			thresholds: Thresholds{MinLine: 70, MinBranch: 50},
+			want:       true,
+		},
+		{
+			name:       "coverage below the minimums is not compliant",
+			file:       "testdata/cobertura.xml",
+			format:     FormatCobertura,
+			thresholds: Thresholds{MinLine: 80, MinBranch: 60},
+			violations: []string{
+				"line coverage 70.00% is below the minimum of 80.00%",
+				"branch coverage 50.00% is below the minimum of 60.00%",
+			},
+		},
+		{
+			name:       "a branch minimum is not compliant without branch data",
+			file:       "testdata/coverage.out",
+			format:     FormatGo,
+			thresholds: Thresholds{MinBranch: 50},
+			violations: []string{"the report has no branch coverage data to check against the minimum of 50.00%"},
+		},
+		{
+			name:     "overall drops against a baseline are not compliant",
+			file:     "testdata/cobertura.xml",
+			format:   FormatCobertura,
+			baseline: "testdata/cobertura-baseline.xml",
+			violations: []string{
+				"line coverage dropped by 13.33% compared to the baseline (83.33% -> 70.00%)",
+				"branch coverage dropped by 25.00% compared to the baseline (75.00% -> 50.00%)",
+			},
+			drops: []Drop{
+				{Scope: ScopeOverall, Metric: MetricLine, Baseline: 83.33, Current: 70, Drop: 13.33},
+				{Scope: ScopeOverall, Metric: MetricBranch, Baseline: 75, Current: 50, Drop: 25},
+				{Scope: "app", Metric: MetricLine, Baseline: 100, Current: 80, Drop: 20},
+			},
+		},
+		{
+			name:       "drops within the maximum drop are compliant",
+			file:       "testdata/cobertura.xml",
+			format:     FormatCobertura,
+			baseline:   "testdata/cobertura-baseline.xml",
+			thresholds: Thresholds{MaxDrop: 25},
+			want:       true,
+			drops: []Drop{
+				{Scope: ScopeOverall, Metric: MetricLine, Baseline: 83.33, Current: 70, Drop: 13.33},
+				{Scope: ScopeOverall, Metric: MetricBranch, Baseline: 75, Current: 50, Drop: 25},
+				{Scope: "app", Metric: MetricLine, Baseline: 100, Current: 80, Drop: 20},
+			},
+		},
+		{
+			name:     "an increase against the baseline is compliant",
+			file:     "testdata/cobertura-baseline.xml",
+			format:   FormatCobertura,
+			baseline: "testdata/cobertura.xml",
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ProcessCoverageFile(tt.file, tt.format)
+			require.NoError(t, err)
+			if tt.baseline != "" {
+				baseline, err := ProcessCoverageFile(tt.baseline, tt.format)
+				require.NoError(t, err)
+				data.Compare(tt.baseline, baseline)
+				assert.Equal(t, tt.baseline, data.Baseline.File)
+				assert.Equal(t, tt.drops, data.Baseline.Drops)
+			}
+			assert.Equal(t, tt.want, data.Evaluate(tt.thresholds))
+			assert.Equal(t, tt.violations, data.Violations)
+			assert.Equal(t, tt.thresholds, data.Thresholds)
+		})
+	}
+}
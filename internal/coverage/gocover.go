@@ -0,0 +1,70 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type goBlock struct {
+	statements int
+	covered    bool
+}
+
+// parseGoCoverprofile reads a profile written by 'go test -coverprofile'.
+// Go profiles count statements, not lines, and have no branch data,
+// so the line coverage is the statement coverage.
+func parseGoCoverprofile(content []byte) (map[string]*PackageCoverage, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	if !scanner.Scan() || !strings.HasPrefix(scanner.Text(), "mode: ") {
+		return nil, fmt.Errorf("the first line must be the coverage mode, e.g. 'mode: set'")
+	}
+
+	// blocks are keyed by file and position, as a profile merged from
+	// several test binaries can report the same block more than once
+	blocks := map[string]*goBlock{}
+	lineNumber := 1
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode: ") {
+			continue
+		}
+		// e.g. github.com/org/repo/pkg/file.go:12.34,15.2 3 1
+		fields := strings.Fields(line)
+		if len(fields) != 3 || !strings.Contains(fields[0], ":") {
+			return nil, fmt.Errorf("invalid block on line %d: %s", lineNumber, line)
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid number of statements on line %d: %s", lineNumber, line)
+		}
+		count, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid count on line %d: %s", lineNumber, line)
+		}
+		block, ok := blocks[fields[0]]
+		if !ok {
+			block = &goBlock{statements: statements}
+			blocks[fields[0]] = block
+		}
+		block.covered = block.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	packages := map[string]*PackageCoverage{}
+	for position, block := range blocks {
+		file := position[:strings.LastIndex(position, ":")]
+		pkg := packageFor(packages, dirOf(file))
+		pkg.Lines.Total += block.statements
+		if block.covered {
+			pkg.Lines.Covered += block.statements
+		}
+	}
+	return packages, nil
+}
@@ -0,0 +1,42 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+type jacocoCounter struct {
+	Type    string `xml:"type,attr"`
+	Missed  int    `xml:"missed,attr"`
+	Covered int    `xml:"covered,attr"`
+}
+
+type jacocoReport struct {
+	XMLName  xml.Name `xml:"report"`
+	Packages []struct {
+		Name     string          `xml:"name,attr"`
+		Counters []jacocoCounter `xml:"counter"`
+	} `xml:"package"`
+}
+
+func parseJacoco(content []byte) (map[string]*PackageCoverage, error) {
+	var report jacocoReport
+	// the DOCTYPE of JaCoCo reports is skipped, its DTD is never fetched
+	if err := xml.Unmarshal(content, &report); err != nil {
+		return nil, err
+	}
+
+	packages := map[string]*PackageCoverage{}
+	for _, p := range report.Packages {
+		pkg := packageFor(packages, strings.ReplaceAll(p.Name, "/", "."))
+		for _, counter := range p.Counters {
+			switch counter.Type {
+			case "LINE":
+				pkg.Lines.add(Counter{Covered: counter.Covered, Total: counter.Covered + counter.Missed})
+			case "BRANCH":
+				pkg.Branches.add(Counter{Covered: counter.Covered, Total: counter.Covered + counter.Missed})
+			}
+		}
+	}
+	return packages, nil
+}
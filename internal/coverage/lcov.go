@@ -0,0 +1,98 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseLcov reads the SF, DA and BRDA records of an LCOV tracefile.
+// The LF/LH/BRF/BRH summaries are used for files without DA/BRDA records.
+func parseLcov(content []byte) (map[string]*PackageCoverage, error) {
+	files := fileCollector{}
+	summaries := map[string]*PackageCoverage{}
+	var current string
+	var summary PackageCoverage
+	var detailed bool
+	records := 0
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == "end_of_record" {
+			if current != "" && !detailed {
+				pkg := packageFor(summaries, dirOf(current))
+				pkg.Lines.add(summary.Lines)
+				pkg.Branches.add(summary.Branches)
+			}
+			current, summary, detailed = "", PackageCoverage{}, false
+			records++
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid record on line %d: %s", lineNumber, line)
+		}
+		if key != "SF" && key != "TN" && current == "" {
+			return nil, fmt.Errorf("%s record outside of a source file on line %d", key, lineNumber)
+		}
+		fields := strings.Split(value, ",")
+		var err error
+		switch key {
+		case "SF":
+			current = value
+		case "DA":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("invalid DA record on line %d: %s", lineNumber, line)
+			}
+			var number int
+			var hits float64
+			if number, err = strconv.Atoi(fields[0]); err == nil {
+				hits, err = strconv.ParseFloat(fields[1], 64)
+			}
+			files.file(current).line(number, hits > 0)
+			detailed = true
+		case "BRDA":
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("invalid BRDA record on line %d: %s", lineNumber, line)
+			}
+			// a '-' means the block was never executed
+			taken := fields[3] != "-" && fields[3] != "0"
+			files.file(current).branch(strings.Join(fields[:3], ":"), taken)
+			detailed = true
+		case "LF":
+			summary.Lines.Total, err = strconv.Atoi(value)
+		case "LH":
+			summary.Lines.Covered, err = strconv.Atoi(value)
+		case "BRF":
+			summary.Branches.Total, err = strconv.Atoi(value)
+		case "BRH":
+			summary.Branches.Covered, err = strconv.Atoi(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s record on line %d: %s", key, lineNumber, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if records == 0 {
+		return nil, fmt.Errorf("no end_of_record found")
+	}
+
+	packages := files.packages(dirOf)
+	for name, summary := range summaries {
+		pkg := packageFor(packages, name)
+		pkg.Lines.add(summary.Lines)
+		pkg.Branches.add(summary.Branches)
+	}
+	return packages, nil
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/containers/image/v5/docker"
@@ -30,8 +31,64 @@ var (
 		"has it been pushed to or pulled from a registry?")
 )
 
+// Supported symlink modes for directory fingerprints
+const (
+	// SymlinkFollow fingerprints the content of the file or directory a symlink points to
+	SymlinkFollow = "follow"
+	// SymlinkRecordTarget fingerprints the target path of a symlink instead of its content
+	SymlinkRecordTarget = "record-target"
+	// SymlinkSkip leaves symlinks out of the fingerprint
+	SymlinkSkip = "skip"
+)
+
+// DirFingerprintOptions controls how the entries of a directory are fingerprinted.
+// The zero value gives the default fingerprint: symlinks are followed, file modes are
+// ignored and empty directories contribute their names. Unset (nil) booleans are left
+// to the header of the directory's .kosli_ignore file.
+type DirFingerprintOptions struct {
+	Symlinks        string `mapstructure:"symlinks" validate:"omitempty,oneof=follow record-target skip"`
+	IncludeFileMode *bool  `mapstructure:"include-file-mode"`
+	SkipEmptyDirs   *bool  `mapstructure:"skip-empty-dirs"`
+}
+
+// Validate checks that the directory fingerprint options have supported values
+func (o DirFingerprintOptions) Validate() error {
+	switch o.Symlinks {
+	case "", SymlinkFollow, SymlinkRecordTarget, SymlinkSkip:
+		return nil
+	default:
+		return fmt.Errorf("%s is not a supported symlink mode. It should be one of: [%s, %s, %s]",
+			o.Symlinks, SymlinkFollow, SymlinkRecordTarget, SymlinkSkip)
+	}
+}
+
+// merge returns the options where values set in o take precedence over the ones in other
+func (o DirFingerprintOptions) merge(other DirFingerprintOptions) DirFingerprintOptions {
+	if o.Symlinks == "" {
+		o.Symlinks = other.Symlinks
+	}
+	if o.IncludeFileMode == nil {
+		o.IncludeFileMode = other.IncludeFileMode
+	}
+	if o.SkipEmptyDirs == nil {
+		o.SkipEmptyDirs = other.SkipEmptyDirs
+	}
+	return o
+}
+
+// includeFileMode returns whether the executable bit of files is part of the fingerprint
+func (o DirFingerprintOptions) includeFileMode() bool {
+	return o.IncludeFileMode != nil && *o.IncludeFileMode
+}
+
+// skipEmptyDirs returns whether empty directories are left out of the fingerprint
+func (o DirFingerprintOptions) skipEmptyDirs() bool {
+	return o.SkipEmptyDirs != nil && *o.SkipEmptyDirs
+}
+
 // DirSha256 returns sha256 digest of a directory
-func DirSha256(dirPath string, excludePaths []string, logger *logger.Logger) (string, error) {
+// options set in opts take precedence over the ones declared in the header of the directory's .kosli_ignore file
+func DirSha256(dirPath string, excludePaths []string, opts DirFingerprintOptions, logger *logger.Logger) (string, error) {
 	logger.Debug("calculating fingerprint for path [%s] -- excluding paths: %s", dirPath, excludePaths)
 	info, err := os.Stat(dirPath)
 	if err != nil {
@@ -41,17 +98,6 @@ func DirSha256(dirPath string, excludePaths []string, logger *logger.Logger) (st
 		return "", fmt.Errorf("%s is not a directory", dirPath)
 	}
 
-	tmpDir, err := os.MkdirTemp("", "*")
-	if err != nil {
-		return "", err
-	}
-	defer os.RemoveAll(tmpDir)
-
-	digestsFile, err := os.Create(filepath.Join(tmpDir, "digests"))
-	if err != nil {
-		return "", err
-	}
-	defer digestsFile.Close()
 	ignoreFilePath := filepath.Join(dirPath, ".kosli_ignore")
 	ignoredPaths, err := excludePathsFromFile(ignoreFilePath)
 	if err != nil {
@@ -61,12 +107,25 @@ func DirSha256(dirPath string, excludePaths []string, logger *logger.Logger) (st
 		logger.Debug("  -> ignore file used %s -- excluding paths: %s", ignoreFilePath, ignoredPaths)
 	}
 	excludePaths = append(excludePaths, ignoredPaths...)
-	err = calculateDirContentSha256(digestsFile, dirPath, tmpDir, excludePaths, logger)
+
+	headerOpts, err := fingerprintOptionsFromFile(ignoreFilePath)
+	if err != nil {
+		return "", err
+	}
+	opts = opts.merge(headerOpts)
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	logger.Debug("  -> fingerprint options: symlinks=%s include-file-mode=%t skip-empty-dirs=%t",
+		opts.Symlinks, opts.includeFileMode(), opts.skipEmptyDirs())
+
+	hasher := sha256.New()
+	err = calculateDirContentSha256(hasher, dirPath, excludePaths, opts, logger)
 	if err != nil {
 		return "", err
 	}
 
-	return FileSha256(digestsFile.Name())
+	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
 // OciSha256 gets the digest of a docker/OCI image from its registry
@@ -95,7 +154,7 @@ func OciSha256(artifactName string, registryUsername string, registryPassword st
 }
 
 // calculateDirContentSha256 calculates a sha256 digest for a directory content
-func calculateDirContentSha256(digestsFile *os.File, dirPath, tmpDir string, excludePaths []string, logger *logger.Logger) error {
+func calculateDirContentSha256(digests io.Writer, dirPath string, excludePaths []string, opts DirFingerprintOptions, logger *logger.Logger) error {
 	pathsToExclude := []string{}
 	for _, p := range excludePaths {
 		found, err := filepathx.Glob(filepath.Join(dirPath, p))
@@ -105,65 +164,142 @@ func calculateDirContentSha256(digestsFile *os.File, dirPath, tmpDir string, exc
 		pathsToExclude = append(pathsToExclude, found...)
 	}
 
-	return filepath.WalkDir(dirPath, func(path string, info fs.DirEntry, err error) error {
-		if err != nil {
+	w := &dirWalker{
+		pathsToExclude: pathsToExclude,
+		opts:           opts,
+		logger:         logger,
+		ancestors:      make(map[string]bool),
+	}
+	// the provided top level dir is not added. Otherwise, the name of that dir is included in
+	// the fingerprint calculation (i.e. changing the dir name would change the fingerprint)
+	entryDigests, err := w.walk(dirPath)
+	if err != nil {
+		return err
+	}
+	for _, d := range entryDigests {
+		if _, err := io.WriteString(digests, d); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		// skip the provided top level dir. Otherwise, the name of that dir is included in
-		// the fingerprint calculation (i.e. changing the dir name would change the fingerprint)
-		if path == dirPath {
-			return nil
-		}
+// dirWalker collects the name and content digests of a directory's entries in lexical order
+type dirWalker struct {
+	pathsToExclude []string
+	opts           DirFingerprintOptions
+	logger         *logger.Logger
+	// the real paths of the directories being walked, used to detect symlink cycles
+	ancestors map[string]bool
+}
+
+// walk returns the digests of the entries of a directory (recursively)
+func (w *dirWalker) walk(dirPath string) ([]string, error) {
+	realPath, err := filepath.EvalSymlinks(dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if w.ancestors[realPath] {
+		return nil, fmt.Errorf("symlink cycle detected at %s", dirPath)
+	}
+	w.ancestors[realPath] = true
+	defer delete(w.ancestors, realPath)
 
-		if utils.Contains(pathsToExclude, path) {
-			if info.IsDir() {
-				logger.Debug("skipping dir %s (and its contents) as it matches excluded paths", path)
-				return fs.SkipDir
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	digests := []string{}
+	for _, entry := range entries {
+		path := filepath.Join(dirPath, entry.Name())
+		if utils.Contains(w.pathsToExclude, path) {
+			if entry.IsDir() {
+				w.logger.Debug("skipping dir %s (and its contents) as it matches excluded paths", path)
+			} else {
+				w.logger.Debug("skipping %s as it matches excluded paths", path)
 			}
-			logger.Debug("skipping %s as it matches excluded paths", path)
-			return nil
+			continue
 		}
-
-		nameSha256, err := addNameDigest(tmpDir, info.Name(), digestsFile)
+		entryDigests, err := w.entryDigests(path, entry)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		digests = append(digests, entryDigests...)
+	}
+	return digests, nil
+}
+
+// entryDigests returns the digests contributed by a single directory entry
+func (w *dirWalker) entryDigests(path string, entry fs.DirEntry) ([]string, error) {
+	info, err := entry.Info()
+	if err != nil {
+		return nil, err
+	}
 
-		if info.IsDir() {
-			logger.Debug("dir path: %s -- dirname digest: %v", path, nameSha256)
-		} else {
-			logger.Debug("file path: %s -- filename digest: %s", path, nameSha256)
-			fileContentSha256, err := FileSha256(path)
+	if info.Mode()&fs.ModeSymlink != 0 {
+		switch w.opts.Symlinks {
+		case SymlinkSkip:
+			w.logger.Debug("skipping symlink %s", path)
+			return []string{}, nil
+		case SymlinkRecordTarget:
+			target, err := os.Readlink(path)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			logger.Debug("filename: %s -- content digest: %s", path, fileContentSha256)
-			if _, err := digestsFile.Write([]byte(fileContentSha256)); err != nil {
-				return err
+			nameSha256 := stringSha256(entry.Name())
+			targetSha256 := stringSha256("symlink:" + filepath.ToSlash(target))
+			w.logger.Debug("symlink path: %s -- filename digest: %s -- target: %s -- target digest: %s", path, nameSha256, target, targetSha256)
+			return []string{nameSha256, targetSha256}, nil
+		default:
+			info, err = os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to follow symlink %s: %v", path, err)
 			}
 		}
+	}
 
-		return nil
-	})
-}
-
-// addNameDigest calculates the sha256 digest of the filename and adds it to the digests file
-func addNameDigest(tmpDir string, filename string, digestsFile *os.File) (string, error) {
-	nameFilePath := filepath.Join(tmpDir, "name")
-	err := utils.CreateFileWithContent(nameFilePath, filename)
-	if err != nil {
-		return "", err
+	nameSha256 := stringSha256(entry.Name())
+	if info.IsDir() {
+		children, err := w.walk(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(children) == 0 && w.opts.skipEmptyDirs() {
+			w.logger.Debug("skipping empty dir %s", path)
+			return children, nil
+		}
+		w.logger.Debug("dir path: %s -- dirname digest: %v", path, nameSha256)
+		return append([]string{nameSha256}, children...), nil
 	}
 
-	nameSha256, err := FileSha256(nameFilePath)
+	w.logger.Debug("file path: %s -- filename digest: %s", path, nameSha256)
+	fileContentSha256, err := FileSha256(path)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	if _, err := digestsFile.Write([]byte(nameSha256)); err != nil {
-		return "", err
+	w.logger.Debug("filename: %s -- content digest: %s", path, fileContentSha256)
+	digests := []string{nameSha256, fileContentSha256}
+
+	if w.opts.includeFileMode() {
+		// only the executable bit is recorded (as git does) so that the
+		// fingerprint does not depend on the umask used when deploying the files
+		mode := "100644"
+		if info.Mode().Perm()&0111 != 0 {
+			mode = "100755"
+		}
+		modeSha256 := stringSha256("mode:" + mode)
+		w.logger.Debug("filename: %s -- mode: %s -- mode digest: %s", path, mode, modeSha256)
+		digests = append(digests, modeSha256)
 	}
-	return nameSha256, nil
+	return digests, nil
+}
+
+// stringSha256 returns the sha256 digest of a string
+func stringSha256(content string) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(content))
+	return hex.EncodeToString(hasher.Sum(nil))
 }
 
 // FileSha256 returns a sha256 digest of a file.
@@ -317,6 +453,66 @@ func excludePathsFromFile(path string) ([]string, error) {
 	return nil, err
 }
 
+// fingerprintOptionsFromFile reads directory fingerprint options from the header of an ignore file.
+// The header is the comment lines before the first path in the file. Options are declared as:
+//
+//	# kosli-fingerprint: symlinks=record-target include-file-mode=true skip-empty-dirs=true
+func fingerprintOptionsFromFile(path string) (DirFingerprintOptions, error) {
+	opts := DirFingerprintOptions{}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return opts, nil
+		}
+		return opts, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		directive, found := strings.CutPrefix(strings.TrimSpace(strings.TrimPrefix(line, "#")), "kosli-fingerprint:")
+		if !found {
+			continue
+		}
+		for _, option := range strings.FieldsFunc(directive, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' }) {
+			key, value, _ := strings.Cut(option, "=")
+			switch key {
+			case "symlinks":
+				opts.Symlinks = value
+			case "include-file-mode":
+				opts.IncludeFileMode, err = parseBoolOption(value)
+			case "skip-empty-dirs":
+				opts.SkipEmptyDirs, err = parseBoolOption(value)
+			default:
+				return opts, fmt.Errorf("unknown fingerprint option '%s' in %s", key, path)
+			}
+			if err != nil {
+				return opts, fmt.Errorf("invalid value for fingerprint option '%s' in %s: %v", key, path, err)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return opts, err
+	}
+	return opts, opts.Validate()
+}
+
+// parseBoolOption parses the boolean value of a fingerprint option
+func parseBoolOption(value string) (*bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
 func removeComments(line string) string {
 	parts := strings.SplitN(line, "#", 2)
 	return strings.TrimRight(parts[0], " ")
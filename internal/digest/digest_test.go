@@ -518,7 +518,7 @@ func (suite *DigestTestSuite) TestDirSha256() {
 				suite.createNestedDir(topLevelPath, entry.files, entry.dirs)
 			}
 
-			sha256, err := DirSha256(topLevelPath, t.args.excludePaths, logger.NewStandardLogger())
+			sha256, err := DirSha256(topLevelPath, t.args.excludePaths, DirFingerprintOptions{}, logger.NewStandardLogger())
 			require.NoErrorf(suite.Suite.T(), err, "error creating digest for test dir %s", topLevelPath)
 
 			assert.Equal(suite.Suite.T(), t.want, sha256, fmt.Sprintf("TestDirSha256: %s , got: %v -- want: %v", t.name, sha256, t.want))
@@ -526,6 +526,142 @@ func (suite *DigestTestSuite) TestDirSha256() {
 	}
 }
 
+func (suite *DigestTestSuite) TestDirSha256WithOptions() {
+	// createArtifact creates a dir with a script, a symlink to it, a symlinked dir and an empty dir
+	createArtifact := func(name, linkTarget string, scriptMode os.FileMode) string {
+		dirPath := filepath.Join(suite.tmpDir, name)
+		require.NoError(suite.Suite.T(), os.MkdirAll(filepath.Join(dirPath, "lib"), 0755))
+		require.NoError(suite.Suite.T(), os.MkdirAll(filepath.Join(dirPath, "empty"), 0755))
+		suite.createFileWithContent(filepath.Join(dirPath, "run.sh"), "echo run")
+		suite.createFileWithContent(filepath.Join(dirPath, "other.sh"), "echo run")
+		suite.createFileWithContent(filepath.Join(dirPath, "lib", "lib.txt"), "lib")
+		require.NoError(suite.Suite.T(), os.Chmod(filepath.Join(dirPath, "run.sh"), scriptMode))
+		require.NoError(suite.Suite.T(), os.Symlink(linkTarget, filepath.Join(dirPath, "current.sh")))
+		require.NoError(suite.Suite.T(), os.Symlink("lib", filepath.Join(dirPath, "lib-link")))
+		return dirPath
+	}
+	fingerprint := func(dirPath string, opts DirFingerprintOptions) string {
+		sha256, err := DirSha256(dirPath, []string{}, opts, logger.NewStandardLogger())
+		require.NoError(suite.Suite.T(), err)
+		return sha256
+	}
+
+	base := createArtifact("base", "run.sh", 0755)
+	retargeted := createArtifact("retargeted", "other.sh", 0755)
+	notExecutable := createArtifact("not-executable", "run.sh", 0644)
+
+	// following symlinks hides the retargeting since both targets have the same content
+	follow := DirFingerprintOptions{Symlinks: SymlinkFollow}
+	assert.Equal(suite.Suite.T(), fingerprint(base, follow), fingerprint(retargeted, follow))
+	assert.Equal(suite.Suite.T(), fingerprint(base, DirFingerprintOptions{}), fingerprint(base, follow))
+
+	recordTarget := DirFingerprintOptions{Symlinks: SymlinkRecordTarget}
+	assert.NotEqual(suite.Suite.T(), fingerprint(base, recordTarget), fingerprint(retargeted, recordTarget))
+	assert.NotEqual(suite.Suite.T(), fingerprint(base, follow), fingerprint(base, recordTarget))
+
+	skip := DirFingerprintOptions{Symlinks: SymlinkSkip}
+	assert.Equal(suite.Suite.T(), fingerprint(base, skip), fingerprint(retargeted, skip))
+	assert.NotEqual(suite.Suite.T(), fingerprint(base, follow), fingerprint(base, skip))
+
+	// the executable bit is only part of the fingerprint when asked for
+	assert.Equal(suite.Suite.T(), fingerprint(base, DirFingerprintOptions{}), fingerprint(notExecutable, DirFingerprintOptions{}))
+	yes, no := true, false
+	withMode := DirFingerprintOptions{IncludeFileMode: &yes}
+	assert.NotEqual(suite.Suite.T(), fingerprint(base, withMode), fingerprint(notExecutable, withMode))
+
+	// empty dirs contribute their names unless skipped
+	withoutEmpty := createArtifact("without-empty", "run.sh", 0755)
+	require.NoError(suite.Suite.T(), os.Remove(filepath.Join(withoutEmpty, "empty")))
+	assert.NotEqual(suite.Suite.T(), fingerprint(base, DirFingerprintOptions{}), fingerprint(withoutEmpty, DirFingerprintOptions{}))
+	skipEmpty := DirFingerprintOptions{SkipEmptyDirs: &yes}
+	assert.Equal(suite.Suite.T(), fingerprint(base, skipEmpty), fingerprint(withoutEmpty, skipEmpty))
+
+	// options declared in the .kosli_ignore header are applied, and flags take precedence
+	suite.createFileWithContent(filepath.Join(base, ".kosli_ignore"), "# kosli-fingerprint: symlinks=record-target\n.kosli_ignore\n")
+	suite.createFileWithContent(filepath.Join(retargeted, ".kosli_ignore"), ".kosli_ignore\n")
+	assert.Equal(suite.Suite.T(), fingerprint(base, DirFingerprintOptions{}), fingerprint(base, recordTarget))
+	assert.Equal(suite.Suite.T(), fingerprint(base, follow), fingerprint(retargeted, DirFingerprintOptions{}))
+
+	// booleans set to false take precedence over the ones set to true in the header
+	withHeader := createArtifact("with-header", "run.sh", 0644)
+	plain := fingerprint(withHeader, DirFingerprintOptions{})
+	suite.createFileWithContent(filepath.Join(withHeader, ".kosli_ignore"), "# kosli-fingerprint: include-file-mode=true skip-empty-dirs=true\n.kosli_ignore\n")
+	assert.NotEqual(suite.Suite.T(), plain, fingerprint(withHeader, DirFingerprintOptions{}))
+	assert.NotEqual(suite.Suite.T(), plain, fingerprint(withHeader, DirFingerprintOptions{IncludeFileMode: &no}))
+	assert.NotEqual(suite.Suite.T(), plain, fingerprint(withHeader, DirFingerprintOptions{SkipEmptyDirs: &no}))
+	assert.Equal(suite.Suite.T(), plain, fingerprint(withHeader, DirFingerprintOptions{IncludeFileMode: &no, SkipEmptyDirs: &no}))
+
+	_, err := DirSha256(base, []string{}, DirFingerprintOptions{Symlinks: "unknown"}, logger.NewStandardLogger())
+	require.Error(suite.Suite.T(), err)
+
+	// following a symlink cycle is an error
+	cyclic := filepath.Join(suite.tmpDir, "cyclic")
+	require.NoError(suite.Suite.T(), os.MkdirAll(filepath.Join(cyclic, "a"), 0755))
+	require.NoError(suite.Suite.T(), os.Symlink("..", filepath.Join(cyclic, "a", "parent")))
+	_, err = DirSha256(cyclic, []string{}, follow, logger.NewStandardLogger())
+	require.Error(suite.Suite.T(), err)
+	_, err = DirSha256(cyclic, []string{}, recordTarget, logger.NewStandardLogger())
+	require.NoError(suite.Suite.T(), err)
+}
+
+func (suite *DigestTestSuite) TestFingerprintOptionsFromFile() {
+	yes := true
+	for _, t := range []struct {
+		name        string
+		content     string
+		want        DirFingerprintOptions
+		expectError bool
+	}{
+		{
+			name:    "an ignore file without a header has no options",
+			content: "logs\n# kosli-fingerprint: symlinks=skip\n",
+			want:    DirFingerprintOptions{},
+		},
+		{
+			name:    "options can be declared on one header line",
+			content: "# kosli-fingerprint: symlinks=record-target include-file-mode=true, skip-empty-dirs=true\nlogs\n",
+			want:    DirFingerprintOptions{Symlinks: SymlinkRecordTarget, IncludeFileMode: &yes, SkipEmptyDirs: &yes},
+		},
+		{
+			name:    "options can be declared on several header lines among comments",
+			content: "# my artifact\n\n#kosli-fingerprint: symlinks=skip\n# kosli-fingerprint: include-file-mode=true\nlogs\n",
+			want:    DirFingerprintOptions{Symlinks: SymlinkSkip, IncludeFileMode: &yes},
+		},
+		{
+			name:        "unknown options are rejected",
+			content:     "# kosli-fingerprint: follow-links=true\n",
+			expectError: true,
+		},
+		{
+			name:        "invalid symlink modes are rejected",
+			content:     "# kosli-fingerprint: symlinks=maybe\n",
+			expectError: true,
+		},
+		{
+			name:        "invalid boolean values are rejected",
+			content:     "# kosli-fingerprint: include-file-mode=yes-please\n",
+			expectError: true,
+		},
+	} {
+		suite.Suite.Run(t.name, func() {
+			ignoreFilePath := filepath.Join(suite.tmpDir, "options.ignore")
+			suite.createFileWithContent(ignoreFilePath, t.content)
+
+			actual, err := fingerprintOptionsFromFile(ignoreFilePath)
+			if t.expectError {
+				require.Error(suite.Suite.T(), err)
+			} else {
+				require.NoError(suite.Suite.T(), err)
+				assert.Equal(suite.Suite.T(), t.want, actual)
+			}
+		})
+	}
+
+	actual, err := fingerprintOptionsFromFile(filepath.Join(suite.tmpDir, "missing.ignore"))
+	require.NoError(suite.Suite.T(), err)
+	assert.Equal(suite.Suite.T(), DirFingerprintOptions{}, actual)
+}
+
 func (suite *DigestTestSuite) createNestedDir(path string, files []fileEntry, dirs []dirEntry) {
 	for _, f := range files {
 		filePath := filepath.Join(path, f.name)
@@ -584,7 +720,7 @@ func (suite *DigestTestSuite) TestDirSha256Validation() {
 				suite.createFileWithContent(dirPath, "")
 			}
 
-			_, err := DirSha256(dirPath, []string{}, logger.NewStandardLogger())
+			_, err := DirSha256(dirPath, []string{}, DirFingerprintOptions{}, logger.NewStandardLogger())
 			if t.errExpected {
 				require.Errorf(suite.Suite.T(), err, "TestDirSha256Validation: error was expected")
 			}
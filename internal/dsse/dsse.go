@@ -0,0 +1,43 @@
+package dsse
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// Envelope is a DSSE (Dead Simple Signing Envelope) envelope
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// DecodedPayload returns the base64 decoded payload
+func (e *Envelope) DecodedPayload() ([]byte, error) {
+	payload, err := DecodeBase64(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode DSSE payload: %s", err)
+	}
+	return payload, nil
+}
+
+// PAE returns the pre-authentication encoding of the payload, which is what DSSE signatures sign
+// https://github.com/secure-systems-lab/dsse/blob/master/protocol.md
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// DecodeBase64 decodes standard or URL-safe base64, with or without padding
+func DecodeBase64(value string) ([]byte, error) {
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if decoded, err := encoding.DecodeString(value); err == nil {
+			return decoded, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid base64 value")
+}
@@ -0,0 +1,26 @@
+package dsse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPAE(t *testing.T) {
+	// example from the DSSE protocol specification
+	assert.Equal(t, "DSSEv1 29 http://example.com/HelloWorld 11 hello world",
+		string(PAE("http://example.com/HelloWorld", []byte("hello world"))))
+}
+
+func TestDecodedPayload(t *testing.T) {
+	for _, payload := range []string{"aGVsbG8/Pz8=", "aGVsbG8_Pz8=", "aGVsbG8/Pz8", "aGVsbG8_Pz8"} {
+		e := &Envelope{Payload: payload}
+		decoded, err := e.DecodedPayload()
+		require.NoError(t, err, payload)
+		assert.Equal(t, "hello???", string(decoded))
+	}
+
+	_, err := (&Envelope{Payload: "not base64!"}).DecodedPayload()
+	require.Error(t, err)
+}
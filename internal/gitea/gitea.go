@@ -0,0 +1,263 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// pageLimit is the number of items requested per page from the Gitea API
+const pageLimit = 50
+
+// Config holds the settings to query a Gitea or Forgejo repository
+type Config struct {
+	Token   string
+	BaseURL string
+	Org     string
+	// Repository is either the repository name or 'org/repository'
+	Repository  string
+	Logger      *logger.Logger
+	KosliClient *requests.Client
+}
+
+func (c *Config) PREvidenceForCommit(commit string) ([]*types.PREvidence, error) {
+	pullRequestsEvidence := []*types.PREvidence{}
+	prs, err := c.pullRequestsForCommit(commit)
+	if err != nil {
+		return pullRequestsEvidence, err
+	}
+	for _, pr := range prs {
+		evidence, err := c.newPREvidence(pr)
+		if err != nil {
+			return pullRequestsEvidence, err
+		}
+		pullRequestsEvidence = append(pullRequestsEvidence, evidence)
+	}
+	return pullRequestsEvidence, nil
+}
+
+// pullRequestsForCommit returns the pull requests for a commit: the pull request it is the
+// merge commit of, or otherwise the open pull requests it is the head commit of
+func (c *Config) pullRequestsForCommit(commit string) ([]pullRequest, error) {
+	prs := []pullRequest{}
+	// Gitea answers 404 when the commit is not the merge commit of a pull request
+	var mergedPR pullRequest
+	err := c.getJSON(c.repoURL("commits/%s/pull", commit), &mergedPR)
+	if err == nil {
+		return append(prs, mergedPR), nil
+	}
+	if !requests.IsNotFound(err) {
+		return prs, err
+	}
+	c.Logger.Debug("no merged pull request found for commit %s, looking for open pull requests", commit)
+
+	for page := 1; ; page++ {
+		var openPRs []pullRequest
+		err := c.getJSON(c.repoURL("pulls?state=open&limit=%d&page=%d", pageLimit, page), &openPRs)
+		if err != nil {
+			return prs, err
+		}
+		for _, pr := range openPRs {
+			if pr.Head.Sha == commit {
+				prs = append(prs, pr)
+			}
+		}
+		if len(openPRs) < pageLimit {
+			return prs, nil
+		}
+	}
+}
+
+// GetPullRequestApprovers returns the reviewers who approved a pull request, ignoring dismissed approvals
+func (c *Config) GetPullRequestApprovers(number int) ([]string, error) {
+	approvers := []string{}
+	reviews, err := c.listReviews(number)
+	if err != nil {
+		return approvers, err
+	}
+	for _, r := range reviews {
+		if r.State == "APPROVED" && !r.Dismissed {
+			approvers = append(approvers, r.User.Login)
+		}
+	}
+	return approvers, nil
+}
+
+func (c *Config) newPREvidence(pr pullRequest) (*types.PREvidence, error) {
+	reviews, err := c.listReviews(pr.Number)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := c.listCommits(pr.Number)
+	if err != nil {
+		return nil, err
+	}
+	return buildPREvidence(pr, reviews, commits), nil
+}
+
+// buildPREvidence builds the evidence of a pull request from its reviews and commits.
+// Users are identified by their login.
+func buildPREvidence(pr pullRequest, reviews []review, commits []commit) *types.PREvidence {
+	state := pr.State
+	if pr.Merged {
+		state = "merged"
+	}
+	evidence := &types.PREvidence{
+		URL:         pr.HTMLURL,
+		MergeCommit: pr.MergeCommitSha,
+		State:       state,
+		Author:      pr.User.Login,
+		Approvers:   []string{},
+		Reviews:     []types.PRReview{},
+	}
+	for _, r := range reviews {
+		state := ""
+		switch {
+		case r.Dismissed:
+			state = types.ReviewDismissed
+		case r.State == "APPROVED":
+			state = types.ReviewApproved
+			evidence.Approvers = append(evidence.Approvers, r.User.Login)
+		case r.State == "REQUEST_CHANGES":
+			state = types.ReviewChangesRequested
+		case r.State == "COMMENT":
+			state = types.ReviewCommented
+		default:
+			// pending reviews and review requests are not decisions
+			continue
+		}
+		prReview := types.PRReview{Reviewer: r.User.Login, State: state, CommitSha1: r.CommitID}
+		if r.SubmittedAt != nil {
+			prReview.Timestamp = r.SubmittedAt.Unix()
+		}
+		evidence.Reviews = append(evidence.Reviews, prReview)
+	}
+
+	prCommits := []types.PRCommit{}
+	for _, c := range commits {
+		prCommit := types.PRCommit{Sha1: c.Sha}
+		if c.Author != nil && c.Author.Login != "" {
+			prCommit.Author = c.Author.Login
+		} else {
+			// commits without a linked account only have the git author name
+			prCommit.Author = c.Commit.Author.Name
+			prCommit.AuthorUnresolved = true
+		}
+		if c.Commit.Committer.Date != nil {
+			prCommit.Timestamp = c.Commit.Committer.Date.Unix()
+		}
+		prCommits = append(prCommits, prCommit)
+	}
+	evidence.Summarize(prCommits, pr.Head.Sha)
+	return evidence
+}
+
+// listReviews returns all the reviews of a pull request
+func (c *Config) listReviews(number int) ([]review, error) {
+	allReviews := []review{}
+	for page := 1; ; page++ {
+		var reviews []review
+		err := c.getJSON(c.repoURL("pulls/%d/reviews?limit=%d&page=%d", number, pageLimit, page), &reviews)
+		if err != nil {
+			return allReviews, err
+		}
+		allReviews = append(allReviews, reviews...)
+		if len(reviews) < pageLimit {
+			return allReviews, nil
+		}
+	}
+}
+
+// listCommits returns all the commits of a pull request
+func (c *Config) listCommits(number int) ([]commit, error) {
+	allCommits := []commit{}
+	for page := 1; ; page++ {
+		var commits []commit
+		err := c.getJSON(c.repoURL("pulls/%d/commits?limit=%d&page=%d", number, pageLimit, page), &commits)
+		if err != nil {
+			return allCommits, err
+		}
+		allCommits = append(allCommits, commits...)
+		if len(commits) < pageLimit {
+			return allCommits, nil
+		}
+	}
+}
+
+// repoURL returns the API URL of a path in the repository
+func (c *Config) repoURL(format string, a ...interface{}) string {
+	org, repository := c.Org, c.Repository
+	// the repository is 'org/repository' when defaulted in Gitea or Forgejo Actions
+	if before, after, found := strings.Cut(repository, "/"); found {
+		if org == "" {
+			org = before
+		}
+		repository = after
+	}
+	return fmt.Sprintf("%s/api/v1/repos/%s/%s/%s", strings.TrimSuffix(c.BaseURL, "/"),
+		url.PathEscape(org), url.PathEscape(repository), fmt.Sprintf(format, a...))
+}
+
+func (c *Config) get(url string) (*requests.HTTPResponse, error) {
+	c.Logger.Debug("getting " + url)
+	reqParams := &requests.RequestParams{
+		Method: http.MethodGet,
+		URL:    url,
+		Token:  c.Token,
+	}
+	return c.KosliClient.Do(reqParams)
+}
+
+func (c *Config) getJSON(url string, v interface{}) error {
+	response, err := c.get(url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(response.Body), v)
+}
+
+type user struct {
+	Login string `json:"login"`
+}
+
+type pullRequest struct {
+	Number         int    `json:"number"`
+	HTMLURL        string `json:"html_url"`
+	State          string `json:"state"`
+	Merged         bool   `json:"merged"`
+	MergeCommitSha string `json:"merge_commit_sha"`
+	User           user   `json:"user"`
+	Head           struct {
+		Sha string `json:"sha"`
+	} `json:"head"`
+}
+
+type review struct {
+	User user `json:"user"`
+	// State is one of APPROVED, REQUEST_CHANGES, COMMENT, PENDING or REQUEST_REVIEW
+	State       string     `json:"state"`
+	Dismissed   bool       `json:"dismissed"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	// CommitID is the head commit of the pull request when the review was submitted
+	CommitID string `json:"commit_id"`
+}
+
+type commit struct {
+	Sha    string `json:"sha"`
+	Author *user  `json:"author"`
+	Commit struct {
+		Author struct {
+			Name string `json:"name"`
+		} `json:"author"`
+		Committer struct {
+			Date *time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
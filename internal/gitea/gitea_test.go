@@ -0,0 +1,221 @@
+package gitea
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/maxcnunes/httpfake"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	mergeCommit = "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6"
+	headCommit  = "b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1"
+)
+
+type GiteaTestSuite struct {
+	suite.Suite
+	fakeService *httpfake.HTTPFake
+	config      *Config
+}
+
+// create a fake Gitea server before the suite execution
+func (suite *GiteaTestSuite) SetupSuite() {
+	t := suite.Suite.T()
+	suite.fakeService = httpfake.New()
+	suite.fakeService.NewHandler().
+		Get("/api/v1/repos/acme/shop/commits/" + mergeCommit + "/pull").
+		Reply(200).
+		BodyString(string(readFixture(t, "approved-before-last-commit", "pull_request.json")))
+	suite.fakeService.NewHandler().
+		Get("/api/v1/repos/acme/shop/commits/" + headCommit + "/pull").
+		Reply(404).
+		BodyString(`{"message": "pull request does not exist"}`)
+	suite.fakeService.NewHandler().
+		Get("/api/v1/repos/acme/shop/pulls").
+		Reply(200).
+		BodyString(`[{"number": 8, "state": "open", "html_url": "https://gitea.acme.com/acme/shop/pulls/8", "user": {"login": "alice"}, "head": {"sha": "0123456789abcdef0123456789abcdef01234567"}},
+			{"number": 7, "state": "open", "html_url": "https://gitea.acme.com/acme/shop/pulls/7", "user": {"login": "alice"}, "head": {"sha": "` + headCommit + `"}}]`)
+	suite.fakeService.NewHandler().
+		Get("/api/v1/repos/acme/shop/pulls/7/reviews").
+		Reply(200).
+		BodyString(string(readFixture(t, "approved-before-last-commit", "reviews.json")))
+	suite.fakeService.NewHandler().
+		Get("/api/v1/repos/acme/shop/pulls/7/commits").
+		Reply(200).
+		BodyString(string(readFixture(t, "approved-before-last-commit", "commits.json")))
+	suite.fakeService.NewHandler().
+		Get("/api/v1/repos/acme/broken/commits/" + mergeCommit + "/pull").
+		Reply(500).
+		BodyString(`{"message": "internal server error"}`)
+	suite.fakeService.NewHandler().
+		Get("/api/v1/repos/acme/broken/pulls").
+		Reply(200).
+		BodyString(`[]`)
+	suite.fakeService.NewHandler().
+		Get("/api/v1/repos/acme/private/pulls").
+		Reply(401).
+		BodyString(`{"message": "token is required"}`)
+
+	log := logger.NewStandardLogger()
+	client, err := requests.NewKosliClient("", 0, false, log)
+	require.NoError(t, err)
+	suite.config = &Config{
+		BaseURL:     suite.fakeService.ResolveURL(""),
+		Token:       "some-token",
+		Logger:      log,
+		KosliClient: client,
+	}
+}
+
+// shutdown the fake service after the suite execution
+func (suite *GiteaTestSuite) TearDownSuite() {
+	suite.fakeService.Close()
+}
+
+func (suite *GiteaTestSuite) TestPREvidenceForCommit() {
+	for _, t := range []struct {
+		name       string
+		org        string
+		repository string
+		commit     string
+		wantURLs   []string
+		wantError  bool
+	}{
+		{
+			name:       "a merge commit finds its merged pull request",
+			org:        "acme",
+			repository: "shop",
+			commit:     mergeCommit,
+			wantURLs:   []string{"https://gitea.acme.com/acme/shop/pulls/7"},
+		},
+		{
+			name:       "a head commit finds its open pull requests",
+			org:        "acme",
+			repository: "shop",
+			commit:     headCommit,
+			wantURLs:   []string{"https://gitea.acme.com/acme/shop/pulls/7"},
+		},
+		{
+			name:       "the org is taken from a repository given as 'org/repository'",
+			repository: "acme/shop",
+			commit:     mergeCommit,
+			wantURLs:   []string{"https://gitea.acme.com/acme/shop/pulls/7"},
+		},
+		{
+			name:       "a commit without pull requests finds none",
+			org:        "acme",
+			repository: "shop",
+			commit:     "1111111111111111111111111111111111111111",
+			wantURLs:   []string{},
+		},
+		{
+			name:       "a server error looking for the merged pull request causes an error",
+			org:        "acme",
+			repository: "broken",
+			commit:     mergeCommit,
+			wantError:  true,
+		},
+		{
+			name:       "an unauthorized request causes an error",
+			org:        "acme",
+			repository: "private",
+			commit:     mergeCommit,
+			wantError:  true,
+		},
+	} {
+		suite.Suite.Run(t.name, func() {
+			config := *suite.config
+			config.Org = t.org
+			config.Repository = t.repository
+			evidence, err := config.PREvidenceForCommit(t.commit)
+			if t.wantError {
+				require.Error(suite.Suite.T(), err)
+				return
+			}
+			require.NoError(suite.Suite.T(), err)
+			urls := []string{}
+			for _, pr := range evidence {
+				urls = append(urls, pr.URL)
+			}
+			require.Equal(suite.Suite.T(), t.wantURLs, urls)
+		})
+	}
+}
+
+func (suite *GiteaTestSuite) TestGetPullRequestApprovers() {
+	config := *suite.config
+	config.Org = "acme"
+	config.Repository = "shop"
+	approvers, err := config.GetPullRequestApprovers(7)
+	require.NoError(suite.Suite.T(), err)
+	require.Equal(suite.Suite.T(), []string{"carol"}, approvers)
+}
+
+func (suite *GiteaTestSuite) TestBuildPREvidence() {
+	var pr pullRequest
+	var reviews []review
+	var commits []commit
+	loadFixture(suite.Suite.T(), "approved-before-last-commit", "pull_request.json", &pr)
+	loadFixture(suite.Suite.T(), "approved-before-last-commit", "reviews.json", &reviews)
+	loadFixture(suite.Suite.T(), "approved-before-last-commit", "commits.json", &commits)
+
+	want := &types.PREvidence{
+		MergeCommit:             mergeCommit,
+		URL:                     "https://gitea.acme.com/acme/shop/pulls/7",
+		State:                   "merged",
+		Approvers:               []string{"carol"},
+		Author:                  "alice",
+		CommitAuthors:           []string{"alice"},
+		UnresolvedCommitAuthors: []string{"Bob Jones"},
+		Reviews: []types.PRReview{
+			{Reviewer: "carol", State: "APPROVED", Timestamp: 1709294400,
+				CommitSha1: "a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0", AfterLastCommit: boolPtr(false)},
+			{Reviewer: "dave", State: "CHANGES_REQUESTED", Timestamp: 1709391600, CommitSha1: headCommit, AfterLastCommit: boolPtr(true)},
+			{Reviewer: "erin", State: "DISMISSED", Timestamp: 1709395200, CommitSha1: headCommit, AfterLastCommit: boolPtr(true)},
+		},
+		LastCommit: &types.PRCommit{
+			Sha1:             headCommit,
+			Author:           "Bob Jones",
+			AuthorUnresolved: true,
+			Timestamp:        1709370000,
+		},
+		ApprovedAfterLastCommit: false,
+		SelfApproved:            false,
+	}
+	evidence := buildPREvidence(pr, reviews, commits)
+	require.Equal(suite.Suite.T(), want, evidence)
+
+	// the last commit is not linked to a Gitea account, so it could be any reviewer's own commit
+	result := evidence.EvaluateIndependentApproval(0)
+	require.False(suite.Suite.T(), result.Compliant)
+	require.Equal(suite.Suite.T(), "0 of 0 required independent approval(s) after the last commit; "+
+		"not counted: carol (approved before the last commit); "+
+		"independence cannot be verified, commit author(s) not matched to an account: Bob Jones", result.Reason)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func readFixture(t *testing.T, fixture, file string) []byte {
+	content, err := os.ReadFile(filepath.Join("testdata", fixture, file))
+	require.NoError(t, err)
+	return content
+}
+
+func loadFixture(t *testing.T, fixture, file string, v interface{}) {
+	require.NoError(t, json.Unmarshal(readFixture(t, fixture, file), v))
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestGiteaTestSuite(t *testing.T) {
+	suite.Run(t, new(GiteaTestSuite))
+}
@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"strings"
+	"time"
 
 	gh "github.com/google/go-github/v42/github"
 	"github.com/kosli-dev/cli/internal/types"
@@ -76,17 +77,105 @@ func (c *GithubConfig) PREvidenceForCommit(commit string) ([]*types.PREvidence,
 }
 
 func (c *GithubConfig) newPRGithubEvidence(pr *gh.PullRequest) (*types.PREvidence, error) {
+	ctx := context.Background()
+	client, err := NewGithubClientFromToken(ctx, c.Token, c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	reviews, err := c.listReviews(ctx, client, pr.GetNumber())
+	if err != nil {
+		return nil, err
+	}
+	commits, err := c.listCommits(ctx, client, pr.GetNumber())
+	if err != nil {
+		return nil, err
+	}
+	return buildPREvidence(pr, reviews, commits), nil
+}
+
+// buildPREvidence builds the evidence of a pull request from its reviews and commits.
+// Users are identified by their login.
+func buildPREvidence(pr *gh.PullRequest, reviews []*gh.PullRequestReview, commits []*gh.RepositoryCommit) *types.PREvidence {
 	evidence := &types.PREvidence{
 		URL:         pr.GetHTMLURL(),
 		MergeCommit: pr.GetMergeCommitSHA(),
 		State:       pr.GetState(),
+		Author:      pr.GetUser().GetLogin(),
+		Approvers:   []string{},
+		Reviews:     []types.PRReview{},
 	}
-	approvers, err := c.GetPullRequestApprovers(pr.GetNumber())
-	if err != nil {
-		return evidence, err
+	for _, r := range reviews {
+		if r.GetState() == "PENDING" {
+			continue
+		}
+		if r.GetState() == types.ReviewApproved {
+			evidence.Approvers = append(evidence.Approvers, r.GetUser().GetLogin())
+		}
+		evidence.Reviews = append(evidence.Reviews, types.PRReview{
+			Reviewer:   r.GetUser().GetLogin(),
+			State:      r.GetState(),
+			Timestamp:  unixOrZero(r.GetSubmittedAt()),
+			CommitSha1: r.GetCommitID(),
+		})
+	}
+
+	prCommits := []types.PRCommit{}
+	for _, commit := range commits {
+		prCommit := types.PRCommit{
+			Sha1:      commit.GetSHA(),
+			Author:    commit.GetAuthor().GetLogin(),
+			Timestamp: unixOrZero(commit.GetCommit().GetCommitter().GetDate()),
+		}
+		// commits without a linked GitHub account only have the git author name
+		if prCommit.Author == "" {
+			prCommit.Author = commit.GetCommit().GetAuthor().GetName()
+			prCommit.AuthorUnresolved = true
+		}
+		prCommits = append(prCommits, prCommit)
+	}
+	evidence.Summarize(prCommits, pr.GetHead().GetSHA())
+	return evidence
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// listReviews returns all the reviews of a pull request
+func (c *GithubConfig) listReviews(ctx context.Context, client *gh.Client, number int) ([]*gh.PullRequestReview, error) {
+	allReviews := []*gh.PullRequestReview{}
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		reviews, resp, err := client.PullRequests.ListReviews(ctx, c.Org, c.Repository, number, opts)
+		if err != nil {
+			return allReviews, err
+		}
+		allReviews = append(allReviews, reviews...)
+		if resp.NextPage == 0 {
+			return allReviews, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listCommits returns all the commits of a pull request
+func (c *GithubConfig) listCommits(ctx context.Context, client *gh.Client, number int) ([]*gh.RepositoryCommit, error) {
+	allCommits := []*gh.RepositoryCommit{}
+	opts := &gh.ListOptions{PerPage: 100}
+	for {
+		commits, resp, err := client.PullRequests.ListCommits(ctx, c.Org, c.Repository, number, opts)
+		if err != nil {
+			return allCommits, err
+		}
+		allCommits = append(allCommits, commits...)
+		if resp.NextPage == 0 {
+			return allCommits, nil
+		}
+		opts.Page = resp.NextPage
 	}
-	evidence.Approvers = approvers
-	return evidence, nil
 }
 
 // PullRequestsForCommit returns a list of pull requests for a specific commit
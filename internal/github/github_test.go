@@ -2,10 +2,14 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 
+	gh "github.com/google/go-github/v42/github"
 	"github.com/kosli-dev/cli/internal/testHelpers"
+	"github.com/kosli-dev/cli/internal/types"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -219,6 +223,113 @@ func (suite *GithubTestSuite) TestGetPullRequestApprovers() {
 	}
 }
 
+func (suite *GithubTestSuite) TestBuildPREvidence() {
+	for _, t := range []struct {
+		name    string
+		fixture string
+		want    *types.PREvidence
+	}{
+		{
+			name:    "reviews and commits are recorded, and approvals are classified against the last commit",
+			fixture: "approved-before-last-commit",
+			want: &types.PREvidence{
+				MergeCommit:             "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6",
+				URL:                     "https://github.com/acme/shop/pull/42",
+				State:                   "closed",
+				Approvers:               []string{"carol", "bob"},
+				Author:                  "alice",
+				CommitAuthors:           []string{"alice"},
+				UnresolvedCommitAuthors: []string{"Bob Jones"},
+				Reviews: []types.PRReview{
+					{Reviewer: "carol", State: "APPROVED", Timestamp: 1709294400,
+						CommitSha1: "a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0", AfterLastCommit: gh.Bool(false)},
+					{Reviewer: "dave", State: "COMMENTED", Timestamp: 1709391600,
+						CommitSha1: "b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1", AfterLastCommit: gh.Bool(true)},
+					{Reviewer: "bob", State: "APPROVED", Timestamp: 1709391600,
+						CommitSha1: "b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1", AfterLastCommit: gh.Bool(true)},
+				},
+				LastCommit: &types.PRCommit{
+					Sha1:             "b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1",
+					Author:           "Bob Jones",
+					AuthorUnresolved: true,
+					Timestamp:        1709370000,
+				},
+				ApprovedAfterLastCommit: true,
+				SelfApproved:            false,
+			},
+		},
+	} {
+		suite.Suite.Run(t.name, func() {
+			var pr gh.PullRequest
+			var reviews []*gh.PullRequestReview
+			var commits []*gh.RepositoryCommit
+			loadFixture(suite.Suite.T(), t.fixture, "pull_request.json", &pr)
+			loadFixture(suite.Suite.T(), t.fixture, "reviews.json", &reviews)
+			loadFixture(suite.Suite.T(), t.fixture, "commits.json", &commits)
+
+			evidence := buildPREvidence(&pr, reviews, commits)
+			require.Equal(suite.Suite.T(), t.want, evidence)
+		})
+	}
+}
+
+func (suite *GithubTestSuite) TestIndependentApproval() {
+	for _, t := range []struct {
+		name          string
+		fixture       string
+		required      int
+		wantCompliant bool
+		wantReason    string
+	}{
+		{
+			name:          "a commit author without a linked account makes approvals non-independent",
+			fixture:       "approved-before-last-commit",
+			required:      1,
+			wantCompliant: false,
+			// the last commit is not linked to a GitHub account, so it could be bob's own commit
+			wantReason: "1 of 1 required independent approval(s) after the last commit: bob; " +
+				"not counted: carol (approved before the last commit); " +
+				"independence cannot be verified, commit author(s) not matched to an account: Bob Jones",
+		},
+		{
+			name:          "approvals by commit authors are not independent",
+			fixture:       "self-approved",
+			required:      1,
+			wantCompliant: true,
+			wantReason: "1 of 1 required independent approval(s) after the last commit: carol; " +
+				"not counted: dave (approved before the last commit), bob (commit author)",
+		},
+		{
+			name:          "not enough independent approvals is non-compliant",
+			fixture:       "self-approved",
+			required:      2,
+			wantCompliant: false,
+			wantReason: "1 of 2 required independent approval(s) after the last commit: carol; " +
+				"not counted: dave (approved before the last commit), bob (commit author)",
+		},
+	} {
+		suite.Suite.Run(t.name, func() {
+			var pr gh.PullRequest
+			var reviews []*gh.PullRequestReview
+			var commits []*gh.RepositoryCommit
+			loadFixture(suite.Suite.T(), t.fixture, "pull_request.json", &pr)
+			loadFixture(suite.Suite.T(), t.fixture, "reviews.json", &reviews)
+			loadFixture(suite.Suite.T(), t.fixture, "commits.json", &commits)
+
+			result := buildPREvidence(&pr, reviews, commits).EvaluateIndependentApproval(t.required)
+			require.Equal(suite.Suite.T(), t.wantCompliant, result.Compliant)
+			require.Equal(suite.Suite.T(), t.wantReason, result.Reason)
+		})
+	}
+}
+
+// loadFixture decodes a recorded GitHub API response from testdata
+func loadFixture(t *testing.T, fixture, file string, v interface{}) {
+	content, err := os.ReadFile(filepath.Join("testdata", fixture, file))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, v))
+}
+
 func (suite *GithubTestSuite) TestExtractRepoName() {
 	for _, t := range []struct {
 		name  string
@@ -0,0 +1,77 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// IssueReferencePattern matches GitHub issue references, e.g. "#12" or "GH-12"
+const IssueReferencePattern = `(#|GH-)[0-9]+`
+
+// ReferencePattern returns the pattern of GitHub issue references
+func (c *GithubConfig) ReferencePattern() string {
+	return IssueReferencePattern
+}
+
+// GetIssueInfo looks up a GitHub issue of the repository from a reference like "#12".
+// A reference to a pull request is not an existing issue, and is marked as a pull request.
+func (c *GithubConfig) GetIssueInfo(reference string) (*types.IssueInfo, error) {
+	number, err := issueNumber(reference)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.IssueInfo{IssueID: reference}
+
+	ctx := context.Background()
+	client, err := NewGithubClientFromToken(ctx, c.Token, c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	issue, response, err := client.Issues.Get(ctx, c.Org, c.Repository, number)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	result.IssueURL = issue.GetHTMLURL()
+	// the issues API also returns pull requests, which merges and squash merges reference in their commit message
+	if issue.IsPullRequest() {
+		result.IsPullRequest = true
+		result.IssueFields = map[string]interface{}{"title": issue.GetTitle()}
+		return result, nil
+	}
+
+	result.IssueExists = true
+	result.Status = issue.GetState()
+	labels := []string{}
+	for _, label := range issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+	assignees := []string{}
+	for _, assignee := range issue.Assignees {
+		assignees = append(assignees, assignee.GetLogin())
+	}
+	result.IssueFields = map[string]interface{}{
+		"title":     issue.GetTitle(),
+		"labels":    labels,
+		"assignees": assignees,
+		"author":    issue.GetUser().GetLogin(),
+	}
+	return result, nil
+}
+
+// issueNumber returns the number in an issue reference like "#12" or "GH-12"
+func issueNumber(reference string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimLeft(reference, "#GH-"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid GitHub issue reference: %s", reference)
+	}
+	return number, nil
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/maxcnunes/httpfake"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetIssueInfo(t *testing.T) {
+	fakeGithub := httpfake.New(httpfake.WithTesting(t))
+	defer fakeGithub.Close()
+	fakeGithub.NewHandler().
+		Get("/api/v3/repos/acme/shop/issues/12").
+		Reply(200).
+		BodyString(`{"number": 12, "state": "closed", "title": "Basket total is wrong", "html_url": "https://github.com/acme/shop/issues/12",
+			"user": {"login": "alice"}, "labels": [{"name": "bug"}], "assignees": [{"login": "bob"}]}`)
+	fakeGithub.NewHandler().
+		Get("/api/v3/repos/acme/shop/issues/13").
+		Reply(404).
+		BodyString(`{"message": "Not Found"}`)
+	fakeGithub.NewHandler().
+		Get("/api/v3/repos/acme/shop/issues/15").
+		Reply(200).
+		BodyString(`{"number": 15, "state": "closed", "title": "Fix basket total", "html_url": "https://github.com/acme/shop/pull/15",
+			"user": {"login": "alice"}, "pull_request": {"url": "https://api.github.com/repos/acme/shop/pulls/15"}}`)
+	fakeGithub.NewHandler().
+		Get("/api/v3/repos/acme/shop/issues/14").
+		Reply(401).
+		BodyString(`{"message": "Bad credentials"}`)
+
+	config := NewGithubConfig("some-token", fakeGithub.ResolveURL("/"), "acme", "acme/shop")
+	require.Equal(t, IssueReferencePattern, config.ReferencePattern())
+
+	info, err := config.GetIssueInfo("#12")
+	require.NoError(t, err)
+	require.True(t, info.IssueExists)
+	require.Equal(t, "#12", info.IssueID)
+	require.Equal(t, "https://github.com/acme/shop/issues/12", info.IssueURL)
+	require.Equal(t, "closed", info.Status)
+	require.Equal(t, map[string]interface{}{
+		"title":     "Basket total is wrong",
+		"labels":    []string{"bug"},
+		"assignees": []string{"bob"},
+		"author":    "alice",
+	}, info.IssueFields)
+
+	info, err = config.GetIssueInfo("GH-13")
+	require.NoError(t, err)
+	require.False(t, info.IssueExists)
+	require.Equal(t, "GH-13", info.IssueID)
+
+	// a squash merge adds the pull request number to the commit message, which is not an issue
+	info, err = config.GetIssueInfo("#15")
+	require.NoError(t, err)
+	require.False(t, info.IssueExists)
+	require.Equal(t, "https://github.com/acme/shop/pull/15", info.IssueURL)
+	require.Empty(t, info.Status)
+	require.True(t, info.IsPullRequest)
+	require.Equal(t, map[string]interface{}{"title": "Fix basket total"}, info.IssueFields)
+
+	_, err = config.GetIssueInfo("#14")
+	require.Error(t, err)
+
+	_, err = config.GetIssueInfo("#")
+	require.Error(t, err)
+}
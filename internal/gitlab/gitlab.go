@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/kosli-dev/cli/internal/types"
 	gitlab "github.com/xanzy/go-gitlab"
@@ -12,6 +13,14 @@ type GitlabConfig struct {
 	BaseURL    string
 	Org        string
 	Repository string
+	// reviewHistory is true to get the system notes, diff versions and commit author accounts of merge requests
+	reviewHistory bool
+}
+
+// EnableReviewHistory makes the merge request evidence include the approval and push times,
+// and the accounts of the commit authors
+func (c *GitlabConfig) EnableReviewHistory() {
+	c.reviewHistory = true
 }
 
 // GetClientOptFns creates a list of ClientOptionFunc
@@ -55,17 +64,188 @@ func (c *GitlabConfig) PREvidenceForCommit(commit string) ([]*types.PREvidence,
 }
 
 func (c *GitlabConfig) newPRGitlabEvidence(mr *gitlab.MergeRequest) (*types.PREvidence, error) {
+	client, err := c.NewGitlabClientFromToken()
+	if err != nil {
+		return nil, err
+	}
+	approvals, _, err := client.MergeRequestApprovals.GetConfiguration(c.ProjectID(), mr.IID)
+	if err != nil {
+		return nil, err
+	}
+	commits, err := c.listCommits(client, mr.IID)
+	if err != nil {
+		return nil, err
+	}
+	if !c.reviewHistory {
+		return buildMREvidence(mr, approvals, nil, commits, nil, nil), nil
+	}
+	notes, err := c.listSystemNotes(client, mr.IID)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := c.listVersions(client, mr.IID)
+	if err != nil {
+		return nil, err
+	}
+	return buildMREvidence(mr, approvals, notes, commits, versions, c.commitAuthorAccounts(client, commits)), nil
+}
+
+// commitAuthorAccounts returns the usernames of the commit authors, keyed by lowercase email.
+// Only authors whose email matches exactly one GitLab user are resolved. Without admin rights, only public
+// emails match, and the authors whose email cannot be searched are left unresolved.
+func (c *GitlabConfig) commitAuthorAccounts(client *gitlab.Client, commits []*gitlab.Commit) map[string]string {
+	accounts := map[string]string{}
+	searched := map[string]bool{}
+	for _, commit := range commits {
+		email := strings.ToLower(commit.AuthorEmail)
+		if email == "" || searched[email] {
+			continue
+		}
+		searched[email] = true
+		users, _, err := client.Users.ListUsers(&gitlab.ListUsersOptions{Search: gitlab.String(email)})
+		if err == nil && len(users) == 1 {
+			accounts[email] = users[0].Username
+		}
+	}
+	return accounts
+}
+
+// buildMREvidence builds the evidence of a merge request from its approvals, system notes, commits
+// and diff versions. Users are identified by their username; git commits only carry the author name and
+// email, so commit authors are resolved through accounts, the usernames keyed by lowercase email.
+// Each push creates a diff version, which records when a commit became the head of the merge request.
+func buildMREvidence(mr *gitlab.MergeRequest, approvals *gitlab.MergeRequestApprovals, notes []*gitlab.Note,
+	commits []*gitlab.Commit, versions []*gitlab.MergeRequestDiffVersion, accounts map[string]string) *types.PREvidence {
 	evidence := &types.PREvidence{
 		URL:         mr.WebURL,
 		MergeCommit: mr.MergeCommitSHA,
 		State:       mr.State,
+		Approvers:   []string{},
+		Reviews:     []types.PRReview{},
 	}
-	approvers, err := c.GetMergeRequestApprovers(mr.IID)
-	if err != nil {
-		return evidence, err
+	if mr.Author != nil {
+		evidence.Author = mr.Author.Username
+	}
+
+	// approval events are only recorded as system notes
+	for _, note := range notes {
+		state := reviewStateFromNote(note)
+		if state == "" {
+			continue
+		}
+		review := types.PRReview{Reviewer: note.Author.Username, State: state}
+		if note.CreatedAt != nil {
+			review.Timestamp = note.CreatedAt.Unix()
+		}
+		evidence.Reviews = append(evidence.Reviews, review)
+	}
+
+	for _, approver := range approvals.ApprovedBy {
+		evidence.Approvers = append(evidence.Approvers, fmt.Sprintf("%s (@%s)", approver.User.Name, approver.User.Username))
+		if !hasApprovalFrom(evidence.Reviews, approver.User.Username) {
+			evidence.Reviews = append(evidence.Reviews, types.PRReview{Reviewer: approver.User.Username, State: types.ReviewApproved})
+		}
+	}
+
+	pushedAt := map[string]int64{}
+	for _, version := range versions {
+		if version.CreatedAt != nil && version.CreatedAt.Unix() > pushedAt[version.HeadCommitSHA] {
+			pushedAt[version.HeadCommitSHA] = version.CreatedAt.Unix()
+		}
+	}
+	prCommits := []types.PRCommit{}
+	for _, commit := range commits {
+		prCommit := types.PRCommit{Sha1: commit.ID, Author: accounts[strings.ToLower(commit.AuthorEmail)], PushedAt: pushedAt[commit.ID]}
+		if prCommit.Author == "" {
+			prCommit.Author = commit.AuthorName
+			prCommit.AuthorUnresolved = true
+		}
+		if commit.CommittedDate != nil {
+			prCommit.Timestamp = commit.CommittedDate.Unix()
+		}
+		prCommits = append(prCommits, prCommit)
+	}
+	evidence.Summarize(prCommits, mr.SHA)
+	return evidence
+}
+
+func reviewStateFromNote(note *gitlab.Note) string {
+	if !note.System {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(note.Body, "approved this merge request"):
+		return types.ReviewApproved
+	case strings.HasPrefix(note.Body, "unapproved this merge request"):
+		return types.ReviewUnapproved
+	case strings.HasPrefix(note.Body, "requested changes"):
+		return types.ReviewChangesRequested
+	}
+	return ""
+}
+
+func hasApprovalFrom(reviews []types.PRReview, reviewer string) bool {
+	for _, review := range reviews {
+		if review.State == types.ReviewApproved && types.EqualIdentity(review.Reviewer, reviewer) {
+			return true
+		}
+	}
+	return false
+}
+
+// listSystemNotes returns the notes of a merge request, oldest first
+func (c *GitlabConfig) listSystemNotes(client *gitlab.Client, mrIID int) ([]*gitlab.Note, error) {
+	allNotes := []*gitlab.Note{}
+	opts := &gitlab.ListMergeRequestNotesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		OrderBy:     gitlab.String("created_at"),
+		Sort:        gitlab.String("asc"),
+	}
+	for {
+		notes, resp, err := client.Notes.ListMergeRequestNotes(c.ProjectID(), mrIID, opts)
+		if err != nil {
+			return allNotes, err
+		}
+		allNotes = append(allNotes, notes...)
+		if resp.NextPage == 0 {
+			return allNotes, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listCommits returns the commits of a merge request
+func (c *GitlabConfig) listCommits(client *gitlab.Client, mrIID int) ([]*gitlab.Commit, error) {
+	allCommits := []*gitlab.Commit{}
+	opts := &gitlab.GetMergeRequestCommitsOptions{PerPage: 100}
+	for {
+		commits, resp, err := client.MergeRequests.GetMergeRequestCommits(c.ProjectID(), mrIID, opts)
+		if err != nil {
+			return allCommits, err
+		}
+		allCommits = append(allCommits, commits...)
+		if resp.NextPage == 0 {
+			return allCommits, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listVersions returns the diff versions of a merge request
+func (c *GitlabConfig) listVersions(client *gitlab.Client, mrIID int) ([]*gitlab.MergeRequestDiffVersion, error) {
+	allVersions := []*gitlab.MergeRequestDiffVersion{}
+	opts := &gitlab.GetMergeRequestDiffVersionsOptions{PerPage: 100}
+	for {
+		versions, resp, err := client.MergeRequests.GetMergeRequestDiffVersions(c.ProjectID(), mrIID, opts)
+		if err != nil {
+			return allVersions, err
+		}
+		allVersions = append(allVersions, versions...)
+		if resp.NextPage == 0 {
+			return allVersions, nil
+		}
+		opts.Page = resp.NextPage
 	}
-	evidence.Approvers = approvers
-	return evidence, nil
 }
 
 // MergeRequestsForCommit returns a list of MRs for a given commit
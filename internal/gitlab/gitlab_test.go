@@ -1,12 +1,20 @@
 package gitlab
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/kosli-dev/cli/internal/testHelpers"
+	"github.com/kosli-dev/cli/internal/types"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	gitlab "github.com/xanzy/go-gitlab"
 )
 
 type GitlabTestSuite struct {
@@ -299,6 +307,194 @@ func (suite *GitlabTestSuite) TestGetMergeRequestApprovers() {
 	}
 }
 
+func (suite *GitlabTestSuite) TestBuildMREvidence() {
+	for _, t := range []struct {
+		name    string
+		fixture string
+		want    *types.PREvidence
+	}{
+		{
+			name:    "approvals are taken from system notes and classified against the last commit",
+			fixture: "approved-before-last-commit",
+			want: &types.PREvidence{
+				MergeCommit:   "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6",
+				URL:           "https://gitlab.com/acme/shop/-/merge_requests/17",
+				State:         "merged",
+				Approvers:     []string{"Carol White (@carol)", "Dave Brown (@dave)", "Erin Green (@erin)"},
+				Author:        "alice",
+				CommitAuthors: []string{"bob", "alice"},
+				Reviews: []types.PRReview{
+					{Reviewer: "carol", State: "APPROVED", Timestamp: 1709294400, AfterLastCommit: gitlab.Bool(false)},
+					{Reviewer: "dave", State: "APPROVED", Timestamp: 1709391600, AfterLastCommit: gitlab.Bool(true)},
+					// an approval without a system note has no timestamp, so it cannot be placed
+					{Reviewer: "erin", State: "APPROVED", Timestamp: 0, AfterLastCommit: nil},
+				},
+				LastCommit: &types.PRCommit{
+					Sha1:      "b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1",
+					Author:    "bob",
+					Timestamp: 1709370000,
+					PushedAt:  1709370300,
+				},
+				ApprovedAfterLastCommit: true,
+				SelfApproved:            false,
+			},
+		},
+	} {
+		suite.Suite.Run(t.name, func() {
+			var mr gitlab.MergeRequest
+			var approvals gitlab.MergeRequestApprovals
+			var notes []*gitlab.Note
+			var commits []*gitlab.Commit
+			var versions []*gitlab.MergeRequestDiffVersion
+			loadFixture(suite.Suite.T(), t.fixture, "merge_request.json", &mr)
+			loadFixture(suite.Suite.T(), t.fixture, "approvals.json", &approvals)
+			loadFixture(suite.Suite.T(), t.fixture, "notes.json", &notes)
+			loadFixture(suite.Suite.T(), t.fixture, "commits.json", &commits)
+			loadFixture(suite.Suite.T(), t.fixture, "versions.json", &versions)
+
+			evidence := buildMREvidence(&mr, &approvals, notes, commits, versions, accounts)
+			require.Equal(suite.Suite.T(), t.want, evidence)
+		})
+	}
+}
+
+func (suite *GitlabTestSuite) TestIndependentApproval() {
+	for _, t := range []struct {
+		name          string
+		fixture       string
+		accounts      map[string]string
+		required      int
+		wantCompliant bool
+		wantReason    string
+	}{
+		{
+			name:          "approvals without a timestamp are not independent",
+			fixture:       "approved-before-last-commit",
+			accounts:      accounts,
+			required:      2,
+			wantCompliant: false,
+			wantReason: "1 of 2 required independent approval(s) after the last commit: dave; " +
+				"not counted: carol (approved before the last commit), erin (unknown whether approved after the last commit)",
+		},
+		{
+			name:          "approvals by commit authors are not independent",
+			fixture:       "self-approved",
+			accounts:      accounts,
+			required:      1,
+			wantCompliant: true,
+			wantReason: "1 of 1 required independent approval(s) after the last commit: carol; " +
+				"not counted: dave (approved before the last commit), bob (commit author)",
+		},
+		{
+			name:          "not enough independent approvals is non-compliant",
+			fixture:       "self-approved",
+			accounts:      accounts,
+			required:      2,
+			wantCompliant: false,
+			wantReason: "1 of 2 required independent approval(s) after the last commit: carol; " +
+				"not counted: dave (approved before the last commit), bob (commit author)",
+		},
+		{
+			name:          "a commit author whose email matches no GitLab user makes approvals non-independent",
+			fixture:       "self-approved",
+			accounts:      map[string]string{"alice@acme.com": "alice"},
+			required:      1,
+			wantCompliant: false,
+			// bob's approval would otherwise be counted, as his commit cannot be attributed to him
+			wantReason: "2 of 1 required independent approval(s) after the last commit: bob, carol; " +
+				"not counted: dave (approved before the last commit); " +
+				"independence cannot be verified, commit author(s) not matched to an account: Bob Jones",
+		},
+	} {
+		suite.Suite.Run(t.name, func() {
+			var mr gitlab.MergeRequest
+			var approvals gitlab.MergeRequestApprovals
+			var notes []*gitlab.Note
+			var commits []*gitlab.Commit
+			var versions []*gitlab.MergeRequestDiffVersion
+			loadFixture(suite.Suite.T(), t.fixture, "merge_request.json", &mr)
+			loadFixture(suite.Suite.T(), t.fixture, "approvals.json", &approvals)
+			loadFixture(suite.Suite.T(), t.fixture, "notes.json", &notes)
+			loadFixture(suite.Suite.T(), t.fixture, "commits.json", &commits)
+			loadFixture(suite.Suite.T(), t.fixture, "versions.json", &versions)
+
+			result := buildMREvidence(&mr, &approvals, notes, commits, versions, t.accounts).EvaluateIndependentApproval(t.required)
+			require.Equal(suite.Suite.T(), t.wantCompliant, result.Compliant)
+			require.Equal(suite.Suite.T(), t.wantReason, result.Reason)
+		})
+	}
+}
+
+func (suite *GitlabTestSuite) TestCommitAuthorAccounts() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("search") {
+		case "bob@acme.com":
+			fmt.Fprint(w, `[{"id": 2, "username": "bob", "name": "Bob Jones"}]`)
+		case "shared@acme.com":
+			fmt.Fprint(w, `[{"id": 2, "username": "bob"}, {"id": 3, "username": "carol"}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	c := &GitlabConfig{BaseURL: server.URL, Org: "acme", Repository: "shop"}
+	client, err := c.NewGitlabClientFromToken()
+	require.NoError(suite.Suite.T(), err)
+	accounts := c.commitAuthorAccounts(client, []*gitlab.Commit{
+		{AuthorEmail: "Bob@acme.com"},
+		{AuthorEmail: "shared@acme.com"},
+		{AuthorEmail: "unknown@acme.com"},
+	})
+	// emails matching several users are not resolved
+	require.Equal(suite.Suite.T(), map[string]string{"bob@acme.com": "bob"}, accounts)
+}
+
+func (suite *GitlabTestSuite) TestNewPRGitlabEvidenceReviewHistory() {
+	requested := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resource := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		requested[resource] = true
+		switch resource {
+		case "approvals":
+			fmt.Fprint(w, `{"approved_by": [{"user": {"name": "Bob Jones", "username": "bob"}}]}`)
+		case "commits":
+			fmt.Fprint(w, `[{"id": "b2c3d4e5", "author_name": "Alice", "author_email": "alice@acme.com"}]`)
+		case "notes", "versions":
+			fmt.Fprint(w, `[]`)
+		default:
+			// e.g. the users search, which needs more permissions
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "403 Forbidden"}`)
+		}
+	}))
+	defer server.Close()
+	mr := &gitlab.MergeRequest{IID: 1, SHA: "b2c3d4e5"}
+
+	c := &GitlabConfig{BaseURL: server.URL, Org: "acme", Repository: "shop"}
+	evidence, err := c.newPRGitlabEvidence(mr)
+	require.NoError(suite.Suite.T(), err)
+	require.Equal(suite.Suite.T(), []string{"Bob Jones (@bob)"}, evidence.Approvers)
+	require.Equal(suite.Suite.T(), map[string]bool{"approvals": true, "commits": true}, requested)
+
+	c.EnableReviewHistory()
+	evidence, err = c.newPRGitlabEvidence(mr)
+	require.NoError(suite.Suite.T(), err)
+	require.True(suite.Suite.T(), requested["notes"] && requested["versions"] && requested["users"])
+	// a commit author who cannot be searched is unresolved
+	require.Equal(suite.Suite.T(), []string{"Alice"}, evidence.UnresolvedCommitAuthors)
+}
+
+// accounts are the GitLab users of the commit authors of the fixtures
+var accounts = map[string]string{"alice@acme.com": "alice", "bob@acme.com": "bob"}
+
+// loadFixture decodes a recorded GitLab API response from testdata
+func loadFixture(t *testing.T, fixture, file string, v interface{}) {
+	content, err := os.ReadFile(filepath.Join("testdata", fixture, file))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, v))
+}
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestGitlabTestSuite(t *testing.T) {
@@ -0,0 +1,58 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// IssueReferencePattern matches GitLab issue references, e.g. "#12"
+const IssueReferencePattern = `#[0-9]+`
+
+// ReferencePattern returns the pattern of GitLab issue references
+func (c *GitlabConfig) ReferencePattern() string {
+	return IssueReferencePattern
+}
+
+// GetIssueInfo looks up a GitLab issue of the project from a reference like "#12"
+func (c *GitlabConfig) GetIssueInfo(reference string) (*types.IssueInfo, error) {
+	iid, err := strconv.Atoi(strings.TrimPrefix(reference, "#"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab issue reference: %s", reference)
+	}
+	result := &types.IssueInfo{IssueID: reference}
+
+	client, err := c.NewGitlabClientFromToken()
+	if err != nil {
+		return nil, err
+	}
+	issue, response, err := client.Issues.GetIssue(c.ProjectID(), iid)
+	if err != nil {
+		if response != nil && response.StatusCode == http.StatusNotFound {
+			return result, nil
+		}
+		return nil, err
+	}
+
+	result.IssueExists = true
+	result.IssueURL = issue.WebURL
+	result.Status = issue.State
+	assignees := []string{}
+	for _, assignee := range issue.Assignees {
+		assignees = append(assignees, assignee.Username)
+	}
+	author := ""
+	if issue.Author != nil {
+		author = issue.Author.Username
+	}
+	result.IssueFields = map[string]interface{}{
+		"title":     issue.Title,
+		"labels":    []string(issue.Labels),
+		"assignees": assignees,
+		"author":    author,
+	}
+	return result, nil
+}
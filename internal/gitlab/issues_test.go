@@ -0,0 +1,42 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/maxcnunes/httpfake"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetIssueInfo(t *testing.T) {
+	fakeGitlab := httpfake.New(httpfake.WithTesting(t))
+	defer fakeGitlab.Close()
+	fakeGitlab.NewHandler().
+		Get("/api/v4/projects/acme%2Fshop/issues/12").
+		Reply(200).
+		BodyString(`{"id": 1012, "iid": 12, "state": "opened", "title": "Basket total is wrong", "web_url": "https://gitlab.com/acme/shop/-/issues/12",
+			"author": {"username": "alice"}, "labels": ["bug"], "assignees": [{"username": "bob"}]}`)
+	fakeGitlab.NewHandler().
+		Get("/api/v4/projects/acme%2Fshop/issues/13").
+		Reply(404).
+		BodyString(`{"message": "404 Not found"}`)
+
+	config := &GitlabConfig{Token: "some-token", BaseURL: fakeGitlab.ResolveURL("/"), Org: "acme", Repository: "shop"}
+	require.Equal(t, IssueReferencePattern, config.ReferencePattern())
+
+	info, err := config.GetIssueInfo("#12")
+	require.NoError(t, err)
+	require.True(t, info.IssueExists)
+	require.Equal(t, "https://gitlab.com/acme/shop/-/issues/12", info.IssueURL)
+	require.Equal(t, "opened", info.Status)
+	require.Equal(t, map[string]interface{}{
+		"title":     "Basket total is wrong",
+		"labels":    []string{"bug"},
+		"assignees": []string{"bob"},
+		"author":    "alice",
+	}, info.IssueFields)
+
+	info, err = config.GetIssueInfo("#13")
+	require.NoError(t, err)
+	require.False(t, info.IssueExists)
+	require.Equal(t, "#13", info.IssueID)
+}
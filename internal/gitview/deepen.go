@@ -0,0 +1,230 @@
+package gitview
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	git "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/kosli-dev/cli/internal/logger"
+)
+
+// deepenSteps are the depths of history fetched, in turn, to deepen a shallow clone
+var deepenSteps = []int{100, 1000, 10000}
+
+// unshallowDepth is the depth which fetches the full history, like 'git fetch --unshallow'
+const unshallowDepth = math.MaxInt32
+
+// deepening is the state of the automatic deepening of a shallow clone
+type deepening struct {
+	// limit is the maximum depth of history fetched. It is 0 to fetch the full history if needed.
+	limit int
+	// depth is the depth of history fetched so far, it is 0 before deepening
+	depth  int
+	logger *logger.Logger
+}
+
+// EnableAutoDeepen makes the GitView fetch more history from the remote, in increasing steps, when the
+// commits it looks for are missing from a shallow clone. The depth of history fetched is at most limit,
+// or the full history when limit is 0. The remote is the one of RepoURL, and its credentials are taken
+// from the environment (see remoteAuth).
+func (gv *GitView) EnableAutoDeepen(limit int, logger *logger.Logger) {
+	gv.deepening = &deepening{limit: limit, logger: logger}
+}
+
+// nextDepth returns the depth of history to fetch in the next step, or 0 when the limit is reached
+func (d *deepening) nextDepth() int {
+	maxDepth := d.limit
+	if maxDepth == 0 {
+		maxDepth = unshallowDepth
+	}
+	if d.depth >= maxDepth {
+		return 0
+	}
+	for _, step := range deepenSteps {
+		if step > d.depth {
+			return min(step, maxDepth)
+		}
+	}
+	return maxDepth
+}
+
+// commitSHARegex matches full or abbreviated commit SHAs
+var commitSHARegex = regexp.MustCompile(`^[0-9a-f]{4,40}$`)
+
+// missingObjectError is an error caused by a git object missing from the repository, which deepening
+// a shallow clone may fetch
+type missingObjectError struct {
+	err error
+}
+
+func (e *missingObjectError) Error() string {
+	return e.err.Error()
+}
+
+func (e *missingObjectError) Unwrap() error {
+	return e.err
+}
+
+// missingObject returns err, marked as a missingObjectError when its cause is a missing git object: an
+// object which is not found, e.g. a parent beyond the shallow boundary, or a revision which is a commit
+// SHA and is not found. Other causes, e.g. a mistyped branch name, are not fixed by deepening.
+func missingObject(err, cause error, revision string) error {
+	if errors.Is(cause, plumbing.ErrObjectNotFound) ||
+		(errors.Is(cause, plumbing.ErrReferenceNotFound) && commitSHARegex.MatchString(revision)) {
+		return &missingObjectError{err: err}
+	}
+	return err
+}
+
+// withDeepening runs f and, when it fails on a missing git object in a shallow clone with auto deepening
+// enabled, deepens the clone and runs f again, until f succeeds or the clone cannot be deepened further
+func (gv *GitView) withDeepening(f func() error) error {
+	err := f()
+	var missing *missingObjectError
+	for err != nil && gv.deepening != nil && errors.As(err, &missing) {
+		deepened, deepenErr := gv.deepen()
+		if deepenErr != nil {
+			return fmt.Errorf("%v\nfailed to deepen the shallow git clone: %v", err, deepenErr)
+		}
+		if !deepened {
+			return err
+		}
+		err = f()
+	}
+	return err
+}
+
+// deepen fetches the next step of history from the remote. It returns false when the repository
+// is not a shallow clone, or the limit of the depth of history is reached.
+func (gv *GitView) deepen() (bool, error) {
+	shallowCommits, err := gv.repository.Storer.Shallow()
+	if err != nil {
+		return false, err
+	}
+	if len(shallowCommits) == 0 {
+		return false, nil
+	}
+	depth := gv.deepening.nextDepth()
+	if depth == 0 {
+		gv.deepening.logger.Info("the shallow git clone is not deepened beyond the limit of %d commit(s)", gv.deepening.limit)
+		return false, nil
+	}
+
+	repoRemote, err := gv.remote("")
+	if err != nil {
+		return false, err
+	}
+	remoteName := repoRemote.Config().Name
+	auth, err := remoteAuth(repoRemote.Config().URLs[0])
+	if err != nil {
+		return false, err
+	}
+	if depth == unshallowDepth {
+		gv.deepening.logger.Info("fetching the full git history from remote %s", remoteName)
+	} else {
+		gv.deepening.logger.Info("fetching %d commit(s) of git history from remote %s", depth, remoteName)
+	}
+	err = gv.repository.Fetch(&git.FetchOptions{
+		RemoteName: remoteName,
+		Depth:      depth,
+		Auth:       auth,
+		Tags:       git.NoTags,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return false, fmt.Errorf("failed to fetch from remote %s: %v", remoteName, err)
+	}
+	gv.deepening.depth = depth
+	return true, gv.pruneShallowCommits()
+}
+
+// pruneShallowCommits removes the commits whose parents are now fetched from the shallow commits of
+// the repository. go-git only adds the new shallow commits after a fetch, which would make git stop
+// the history at the previous ones.
+func (gv *GitView) pruneShallowCommits() error {
+	shallowCommits, err := gv.repository.Storer.Shallow()
+	if err != nil {
+		return err
+	}
+	stillShallow := []plumbing.Hash{}
+	for _, hash := range shallowCommits {
+		commit, err := gv.repository.CommitObject(hash)
+		if err != nil {
+			stillShallow = append(stillShallow, hash)
+			continue
+		}
+		for _, parent := range commit.ParentHashes {
+			if _, err := gv.repository.CommitObject(parent); err != nil {
+				stillShallow = append(stillShallow, hash)
+				break
+			}
+		}
+	}
+	// git considers a repository with an empty shallow file as shallow
+	if storage, ok := gv.repository.Storer.(*filesystem.Storage); ok && len(stillShallow) == 0 {
+		err := storage.Filesystem().Remove("shallow")
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	return gv.repository.Storer.SetShallow(stillShallow)
+}
+
+// remoteAuth returns the credentials to fetch from a remote URL, taken from the environment.
+// For HTTP remotes, they are KOSLI_GIT_USERNAME (defaulted to git) and KOSLI_GIT_PASSWORD. Over HTTPS,
+// they can also be the GITHUB_TOKEN of GitHub Actions when the remote is on the GITHUB_SERVER_URL host,
+// or the CI_JOB_TOKEN of GitLab CI when the remote is on the CI_SERVER_HOST. A password in the URL is kept.
+// SSH remotes use the SSH agent, and other remotes (e.g. local paths) need no credentials.
+func remoteAuth(remoteURL string) (transport.AuthMethod, error) {
+	if !strings.HasPrefix(remoteURL, "http://") && !strings.HasPrefix(remoteURL, "https://") {
+		return nil, nil
+	}
+	parsedURL, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	if password := os.Getenv("KOSLI_GIT_PASSWORD"); password != "" {
+		username := os.Getenv("KOSLI_GIT_USERNAME")
+		if username == "" {
+			username = "git"
+		}
+		return &http.BasicAuth{Username: username, Password: password}, nil
+	}
+	if _, hasPassword := parsedURL.User.Password(); hasPassword {
+		return nil, nil
+	}
+	// the CI tokens are only sent to their own git server, and never in clear text
+	if parsedURL.Scheme != "https" {
+		return nil, nil
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && sameHost(parsedURL, serverURLHost(os.Getenv("GITHUB_SERVER_URL"))) {
+		return &http.BasicAuth{Username: "x-access-token", Password: token}, nil
+	}
+	if token := os.Getenv("CI_JOB_TOKEN"); token != "" && sameHost(parsedURL, os.Getenv("CI_SERVER_HOST")) {
+		return &http.BasicAuth{Username: "gitlab-ci-token", Password: token}, nil
+	}
+	return nil, nil
+}
+
+// serverURLHost returns the host name of a server URL, or an empty string when it is not a valid URL
+func serverURLHost(serverURL string) string {
+	parsedURL, err := url.Parse(serverURL)
+	if err != nil {
+		return ""
+	}
+	return parsedURL.Hostname()
+}
+
+// sameHost is true when the host name of remoteURL is host, ignoring case
+func sameHost(remoteURL *url.URL, host string) bool {
+	return host != "" && strings.EqualFold(remoteURL.Hostname(), host)
+}
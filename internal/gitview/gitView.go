@@ -1,15 +1,26 @@
 package gitview
 
 import (
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
+	"path"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	git "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/kevinburke/ssh_config"
 	"github.com/kosli-dev/cli/internal/logger"
 	"github.com/kosli-dev/cli/internal/utils"
 )
@@ -33,8 +44,31 @@ type CommitInfo struct {
 type GitView struct {
 	repositoryRoot string
 	repository     *git.Repository
+	// remoteName is the remote whose URL is the repository URL. It is detected when empty, see RepoURL
+	remoteName string
+	// deepening is nil unless the missing history of a shallow clone is fetched, see EnableAutoDeepen
+	deepening *deepening
 }
 
+// preferredRemotes are the remotes tried first when detecting the remote of a commit.
+// In a fork-based workflow, upstream is the canonical repository and origin is the fork.
+var preferredRemotes = []string{"upstream", "origin"}
+
+// sshConfigValue returns the value of a key (e.g. HostName) for a host alias in the user's SSH config
+var sshConfigValue = ssh_config.Get
+
+// bitbucketDCSSHPort is the default SSH port of Bitbucket Data Center
+const bitbucketDCSSHPort = "7999"
+
+// scpLikeRemote matches the scp-like syntax of SSH remotes, e.g. git@github.com:kosli-dev/cli.git
+var scpLikeRemote = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// gitSuffix matches the .git suffix of the repository in a remote URL
+var gitSuffix = regexp.MustCompile(`\.git(/|$)`)
+
+// bitbucketDCCloneURL matches the path of a Bitbucket Data Center HTTP clone URL, e.g. /scm/proj/repo
+var bitbucketDCCloneURL = regexp.MustCompile(`^(.*?)/scm/([^/]+)/([^/]+)(/.*)?$`)
+
 const redactedCommitInfoValue = "**REDACTED**"
 
 // New opens a git repository from the given path. It detects if the
@@ -51,8 +85,37 @@ func New(repositoryRoot string) (*GitView, error) {
 	}, nil
 }
 
-// CommitsBetween list all commits that have happened between two commits in a git repo
-func (gv *GitView) CommitsBetween(oldest, newest string, logger *logger.Logger) ([]*CommitInfo, error) {
+// NewWithRemote opens a git repository like New, using the URL of the given remote as the
+// repository URL. When remoteName is empty, the remote is detected (see RepoURL).
+func NewWithRemote(repositoryRoot, remoteName string) (*GitView, error) {
+	gv, err := New(repositoryRoot)
+	if err != nil {
+		return nil, err
+	}
+	gv.remoteName = remoteName
+	return gv, nil
+}
+
+// CommitsBetween list all commits that have happened between two commits in a git repo.
+// Like git's oldest..newest, these are the commits reachable from newest and not from oldest, including the
+// commits of branches merged after oldest, and oldest must be an ancestor of newest.
+// When paths are given, only the commits changing a file matching one of the paths are listed (see MatchesPaths).
+// Renames are followed: the commits changing a file before it was renamed to a matching path are listed too.
+func (gv *GitView) CommitsBetween(oldest, newest string, paths []string, logger *logger.Logger) ([]*CommitInfo, error) {
+	var commits []*CommitInfo
+	err := gv.withDeepening(func() error {
+		var err error
+		commits, err = gv.commitsBetween(oldest, newest, logger)
+		return err
+	})
+	if err != nil {
+		return commits, err
+	}
+	return gv.commitsTouchingPaths(commits, paths, logger)
+}
+
+// commitsBetween list all commits between two commits, newest first
+func (gv *GitView) commitsBetween(oldest, newest string, logger *logger.Logger) ([]*CommitInfo, error) {
 	// Using 'var commits []*ArtifactCommit' will make '[]' convert to 'null' when converting to json
 	// which will fail on the server side.
 	// Using 'commits := make([]*ArtifactCommit, 0)' will make '[]' convert to '[]' when converting to json
@@ -67,11 +130,11 @@ func (gv *GitView) CommitsBetween(oldest, newest string, logger *logger.Logger)
 	newestHash, err := gv.repository.ResolveRevision(plumbing.Revision(newest))
 	hint := "The commit does not exist in the git repository.\nThis may be caused by insufficient git clone depth."
 	if err != nil {
-		return commits, fmt.Errorf("failed to resolve git reference %s\n%s", newest, hint)
+		return commits, missingObject(fmt.Errorf("failed to resolve git reference %s\n%s", newest, hint), err, newest)
 	}
 	oldestHash, err := gv.repository.ResolveRevision(plumbing.Revision(oldest))
 	if err != nil {
-		return commits, fmt.Errorf("failed to resolve git reference %s\n%s", oldest, hint)
+		return commits, missingObject(fmt.Errorf("failed to resolve git reference %s\n%s", oldest, hint), err, oldest)
 	}
 
 	logger.Debug("newest commit hash %s", newestHash.String())
@@ -86,38 +149,294 @@ func (gv *GitView) CommitsBetween(oldest, newest string, logger *logger.Logger)
 		commits = append(commits, commit)
 
 	} else {
-		commitsIter, err := gv.repository.Log(&git.LogOptions{From: *newestHash, Order: git.LogOrderCommitterTime})
+		rangeCommits, isAncestor, err := gv.commitsInRange(*oldestHash, *newestHash)
 		if err != nil {
-			return commits, fmt.Errorf("failed to git log: %v", err)
+			return commits, missingObject(fmt.Errorf("failed to get next git commit: %v\n%s", err, hint), err, "")
+		}
+		if !isAncestor {
+			return commits, fmt.Errorf("commit %s is not an ancestor of %s", oldest, newest)
 		}
+		for _, commit := range rangeCommits {
+			commits = append(commits, gv.asCommitInfo(commit, branchName, true, []string{}))
+		}
+	}
 
-		for {
-			commit, err := commitsIter.Next()
-			if err != nil {
-				return commits, fmt.Errorf("failed to get next git commit: %v\n%s", err, hint)
+	logger.Debug("parsed %d commits between newest and oldest git commits", len(commits))
+	return commits, nil
+}
+
+// commitsInRange returns the commits reachable from newest and not from oldest, newest first by committer
+// time, like git's oldest..newest, and whether oldest is an ancestor of newest.
+// The histories of both commits are walked together by committer time, and the walk stops when all the
+// commits left to visit are reachable from oldest, so a shallow clone is only needed as deep as the range.
+func (gv *GitView) commitsInRange(oldest, newest plumbing.Hash) ([]*object.Commit, bool, error) {
+	queue := &commitQueue{}
+	// reachableFromOldest is false for the commits only known to be reachable from newest
+	reachableFromOldest := map[plumbing.Hash]bool{}
+	push := func(hash plumbing.Hash, fromOldest bool) error {
+		if seenFromOldest, seen := reachableFromOldest[hash]; seen {
+			reachableFromOldest[hash] = seenFromOldest || fromOldest
+			return nil
+		}
+		commit, err := gv.repository.CommitObject(hash)
+		// the history of oldest beyond the shallow boundary is only needed when the history of newest
+		// goes there too, which then fails on the missing commits instead
+		if fromOldest && errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		reachableFromOldest[hash] = fromOldest
+		heap.Push(queue, commit)
+		return nil
+	}
+	if err := push(newest, false); err != nil {
+		return nil, false, err
+	}
+	if err := push(oldest, true); err != nil {
+		return nil, false, err
+	}
+
+	visited := []*object.Commit{}
+	isAncestor := false
+	for queue.Len() > 0 && !queue.all(reachableFromOldest) {
+		commit := heap.Pop(queue).(*object.Commit)
+		fromOldest := reachableFromOldest[commit.Hash]
+		if !fromOldest {
+			visited = append(visited, commit)
+		}
+		for _, parent := range commit.ParentHashes {
+			isAncestor = isAncestor || (!fromOldest && parent == oldest)
+			if err := push(parent, fromOldest); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	// with committer times out of order, a commit can be visited before it is found to be reachable from oldest
+	commits := []*object.Commit{}
+	for _, commit := range visited {
+		if !reachableFromOldest[commit.Hash] {
+			commits = append(commits, commit)
+		}
+	}
+	return commits, isAncestor, nil
+}
+
+// commitQueue is a priority queue of commits, the latest committed first
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	return q[i].Committer.When.After(q[j].Committer.When)
+}
+func (q commitQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x interface{}) { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() interface{} {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
+
+// all returns whether all the queued commits are marked
+func (q commitQueue) all(marked map[plumbing.Hash]bool) bool {
+	for _, commit := range q {
+		if !marked[commit.Hash] {
+			return false
+		}
+	}
+	return true
+}
+
+// commitsTouchingPaths returns the commits, newest first, which change a file matching one of the paths,
+// or a file which is later renamed to a matching path. All the commits are returned when there are no paths.
+func (gv *GitView) commitsTouchingPaths(commits []*CommitInfo, paths []string, logger *logger.Logger) ([]*CommitInfo, error) {
+	if len(paths) == 0 {
+		return commits, nil
+	}
+	// renamedPaths are the previous paths of the files which are renamed to a matching path
+	renamedPaths := make(map[string]struct{})
+	matches := func(path string) bool {
+		if path == "" {
+			return false
+		}
+		if _, ok := renamedPaths[path]; ok {
+			return true
+		}
+		return MatchesPaths(path, paths)
+	}
+
+	touchingCommits := make([]*CommitInfo, 0)
+	for _, commit := range commits {
+		changes, err := gv.commitChanges(plumbing.NewHash(commit.Sha1))
+		if err != nil {
+			return touchingCommits, err
+		}
+		touches := false
+		for _, change := range changes {
+			from, to := change.From.Name, change.To.Name
+			if matches(to) {
+				touches = true
+				if from != "" && from != to {
+					renamedPaths[from] = struct{}{}
+				}
+			} else if matches(from) {
+				touches = true
 			}
-			if commit.Hash != *oldestHash {
-				nextCommit := gv.asCommitInfo(commit, branchName, true, []string{})
-				commits = append(commits, nextCommit)
-			} else {
-				break
+		}
+		if touches {
+			touchingCommits = append(touchingCommits, commit)
+		}
+	}
+	logger.Debug("%d of %d commits change files matching paths %v", len(touchingCommits), len(commits), paths)
+	return touchingCommits, nil
+}
+
+// ValidatePaths checks that the paths are valid patterns for MatchesPaths
+func ValidatePaths(paths []string) error {
+	for _, pattern := range paths {
+		for _, segment := range strings.Split(strings.Trim(pattern, "/"), "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid path pattern '%s': %v", pattern, err)
 			}
 		}
 	}
+	return nil
+}
 
-	logger.Debug("parsed %d commits between newest and oldest git commits", len(commits))
-	return commits, nil
+// MatchesPaths returns true when the file path, relative to the repository root, matches one of the path
+// patterns. A pattern is a path whose segments can use the wildcards of path.Match, and where a '**' segment
+// matches any number of directories, e.g. services/a/** or libs/*/go.mod.
+// A pattern matches the files in the directories it matches, e.g. services/a matches services/a/main.go.
+func MatchesPaths(filePath string, paths []string) bool {
+	fileSegments := strings.Split(filePath, "/")
+	for _, pattern := range paths {
+		patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+		// a pattern matching a directory matches the files in the directory
+		for i := 1; i <= len(fileSegments); i++ {
+			if matchSegments(patternSegments, fileSegments[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchSegments matches the segments of a path against the segments of a pattern
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	matched, err := path.Match(pattern[0], segments[0])
+	return err == nil && matched && matchSegments(pattern[1:], segments[1:])
 }
 
-// RepoURL returns HTTPS URL for the `origin` remote of a repo
-func (gv *GitView) RepoURL() (string, error) {
-	repoRemote, err := gv.repository.Remote("origin") // TODO: We hard code this for now. Should we have a flag to set it from the cmdline? 2022-12-06
+// RepoURL returns the HTTPS URL of the remote of a commit of the repo. The remote is the one the GitView
+// is created with, if any. Otherwise, it is the first remote which contains the commit, trying upstream,
+// origin and then the other remotes by name, or the first of these remotes when none contains the commit.
+// The commit can be empty to only use this fallback order.
+func (gv *GitView) RepoURL(commitSHA string) (string, error) {
+	repoRemote, err := gv.remote(commitSHA)
 	if err != nil {
-		return "", fmt.Errorf("remote('origin') is not found in git repository: %s", gv.repositoryRoot)
+		return "", err
 	}
 	return ExtractRepoURLFromRemote(repoRemote.Config().URLs[0])
 }
 
+// remote returns the remote of a commit, see RepoURL
+func (gv *GitView) remote(commitSHA string) (*git.Remote, error) {
+	if gv.remoteName != "" {
+		repoRemote, err := gv.repository.Remote(gv.remoteName)
+		if err != nil {
+			return nil, fmt.Errorf("remote('%s') is not found in git repository: %s", gv.remoteName, gv.repositoryRoot)
+		}
+		return repoRemote, nil
+	}
+
+	remotes, err := gv.repository.Remotes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the remotes of git repository %s: %v", gv.repositoryRoot, err)
+	}
+	if len(remotes) == 0 {
+		return nil, fmt.Errorf("no remote is found in git repository: %s", gv.repositoryRoot)
+	}
+	sort.SliceStable(remotes, func(i, j int) bool {
+		return remoteRank(remotes[i].Config().Name) < remoteRank(remotes[j].Config().Name)
+	})
+
+	if commitSHA != "" {
+		hash, err := gv.repository.ResolveRevision(plumbing.Revision(commitSHA))
+		if err == nil {
+			for _, repoRemote := range remotes {
+				if gv.remoteContains(repoRemote.Config().Name, *hash) {
+					return repoRemote, nil
+				}
+			}
+		}
+	}
+	return remotes[0], nil
+}
+
+// remoteRank orders the remotes: the preferred remotes first, then the other remotes by name
+func remoteRank(remoteName string) string {
+	for i, preferred := range preferredRemotes {
+		if remoteName == preferred {
+			return fmt.Sprintf("%d", i)
+		}
+	}
+	return fmt.Sprintf("%d%s", len(preferredRemotes), remoteName)
+}
+
+// remoteContains returns true when the commit is reachable from one of the remote-tracking
+// branches of the remote, as fetched in the repository
+func (gv *GitView) remoteContains(remoteName string, hash plumbing.Hash) bool {
+	refs, err := gv.repository.References()
+	if err != nil {
+		return false
+	}
+	defer refs.Close()
+
+	commit, err := gv.repository.CommitObject(hash)
+	if err != nil {
+		return false
+	}
+	prefix := fmt.Sprintf("refs/remotes/%s/", remoteName)
+	found := false
+	_ = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference || !strings.HasPrefix(ref.Name().String(), prefix) {
+			return nil
+		}
+		if ref.Hash() == hash {
+			found = true
+			return storer.ErrStop
+		}
+		branchCommit, err := gv.repository.CommitObject(ref.Hash())
+		if err != nil {
+			return nil
+		}
+		// an error, e.g. a missing parent in a shallow clone, means the commit is not found
+		if isAncestor, err := commit.IsAncestor(branchCommit); err == nil && isAncestor {
+			found = true
+			return storer.ErrStop
+		}
+		return nil
+	})
+	return found
+}
+
 // removeUsernamePasswordFromURL removes username and password from a URL
 func removeUsernamePasswordFromURL(inputURL string) (string, error) {
 	parsedURL, err := url.Parse(inputURL)
@@ -131,28 +450,85 @@ func removeUsernamePasswordFromURL(inputURL string) (string, error) {
 	return parsedURL.String(), nil
 }
 
-// ExtractRepoURLFromRemote converts an SSH or http remote into a URL
+// ExtractRepoURLFromRemote converts an SSH or http remote into a URL.
+// SSH remotes, in the scp-like (git@host:path) or ssh:// syntax, are converted to the HTTPS URL of the
+// repository: host aliases are resolved with the SSH config, Azure DevOps remotes are converted to their
+// _git URL, and Bitbucket Data Center remotes (on SSH port 7999, or with a /scm/ clone path) to their
+// /projects/KEY/repos/ URL.
 func ExtractRepoURLFromRemote(remoteUrl string) (string, error) {
-	if strings.HasPrefix(remoteUrl, "git@") {
-		remoteUrl = strings.Replace(remoteUrl, ":", "/", 1)
-		remoteUrl = strings.Replace(remoteUrl, "git@", "https://", 1)
+	if strings.HasPrefix(remoteUrl, "ssh://") || strings.HasPrefix(remoteUrl, "git+ssh://") || strings.HasPrefix(remoteUrl, "ssh+git://") {
+		parsedURL, err := url.Parse(remoteUrl)
+		if err != nil {
+			return "", err
+		}
+		return sshRepoURL(parsedURL.Hostname(), parsedURL.Port(), parsedURL.Path), nil
+	}
+	if !strings.Contains(remoteUrl, "://") {
+		if match := scpLikeRemote.FindStringSubmatch(remoteUrl); match != nil {
+			return sshRepoURL(match[1], "", match[2]), nil
+		}
 	}
-	remoteUrl = strings.Replace(remoteUrl, ".git", "", 1)
+
+	remoteUrl = gitSuffix.ReplaceAllString(remoteUrl, "$1")
 	remoteUrl, err := removeUsernamePasswordFromURL(remoteUrl)
 	if err != nil {
 		return "", err
 	}
+	parsedURL, err := url.Parse(remoteUrl)
+	if err != nil {
+		return "", err
+	}
+	if match := bitbucketDCCloneURL.FindStringSubmatch(parsedURL.Path); match != nil {
+		parsedURL.Path = match[1] + bitbucketDCRepoPath(match[2], match[3]) + match[4]
+		return parsedURL.String(), nil
+	}
 	return remoteUrl, nil
 }
 
+// sshRepoURL returns the HTTPS URL of the repository at a path on an SSH host
+func sshRepoURL(host, port, repoPath string) string {
+	if hostName := sshConfigValue(host, "HostName"); hostName != "" {
+		if port == "" {
+			port = sshConfigValue(host, "Port")
+		}
+		host = hostName
+	}
+	repoPath = strings.TrimSuffix(gitSuffix.ReplaceAllString(strings.Trim(repoPath, "/"), "$1"), "/")
+	segments := strings.Split(repoPath, "/")
+
+	switch {
+	case host == "ssh.dev.azure.com" && len(segments) == 4 && segments[0] == "v3":
+		// git@ssh.dev.azure.com:v3/org/project/repo
+		return fmt.Sprintf("https://dev.azure.com/%s/%s/_git/%s", segments[1], segments[2], segments[3])
+	case host == "vs-ssh.visualstudio.com" && len(segments) == 4 && segments[0] == "v3":
+		// org@vs-ssh.visualstudio.com:v3/org/project/repo
+		return fmt.Sprintf("https://%s.visualstudio.com/%s/_git/%s", segments[1], segments[2], segments[3])
+	case port == bitbucketDCSSHPort && len(segments) >= 2:
+		// ssh://git@host:7999/proj/repo.git
+		return fmt.Sprintf("https://%s%s", host, bitbucketDCRepoPath(segments[0], strings.Join(segments[1:], "/")))
+	default:
+		return fmt.Sprintf("https://%s/%s", host, repoPath)
+	}
+}
+
+// bitbucketDCRepoPath returns the path of the web URL of a Bitbucket Data Center repository in a project,
+// or in the personal project of a user when the project is ~user
+func bitbucketDCRepoPath(project, repo string) string {
+	if user, found := strings.CutPrefix(project, "~"); found {
+		return fmt.Sprintf("/users/%s/repos/%s", user, repo)
+	}
+	return fmt.Sprintf("/projects/%s/repos/%s", strings.ToUpper(project), repo)
+}
+
 // ChangeLog attempts to collect the changelog list of commits for an artifact,
 // the changelog is all commits between current commit and the commit from which the previous artifact in Kosli
 // was created.
+// When paths are given, only the commits changing files matching the paths are in the changelog.
 // If collecting the changelog fails (e.g. if git history has been rewritten, or the clone depth is too shallow),
 // the changelog only contains the single commit info which is the current commit
-func (gv *GitView) ChangeLog(currentCommit, previousCommit string, logger *logger.Logger) ([]*CommitInfo, error) {
+func (gv *GitView) ChangeLog(currentCommit, previousCommit string, paths []string, logger *logger.Logger) ([]*CommitInfo, error) {
 	if previousCommit != "" {
-		commitsList, err := gv.CommitsBetween(previousCommit, currentCommit, logger)
+		commitsList, err := gv.CommitsBetween(previousCommit, currentCommit, paths, logger)
 		if err != nil {
 			logger.Warn(err.Error())
 		} else {
@@ -188,7 +564,7 @@ func (gv *GitView) GetCommitInfoFromCommitSHA(gitCommit string, ignoreURL bool,
 		return &CommitInfo{}, err
 	}
 
-	hash, err := gv.repository.ResolveRevision(plumbing.Revision(gitCommit))
+	hash, err := gv.resolveRevision(gitCommit)
 	if err != nil {
 		return &CommitInfo{}, fmt.Errorf("failed to resolve git reference %s: %v", gitCommit, err)
 	}
@@ -208,7 +584,7 @@ func (gv *GitView) asCommitInfo(commit *object.Commit, branchName string, ignore
 
 	commitURL := ""
 	if !ignoreURL {
-		repoURL, err := gv.RepoURL()
+		repoURL, err := gv.RepoURL(commit.Hash.String())
 		if err == nil {
 			commitURL = getCommitURL(repoURL, commit.Hash.String())
 		}
@@ -243,7 +619,7 @@ func (gv *GitView) asCommitInfo(commit *object.Commit, branchName string, ignore
 }
 
 // getCommitURL attempts to get a url for a commit by constructing
-// a url based on the repoURL extracted from the repo's "origin" remote
+// a url based on the repoURL extracted from the remote of the commit
 // empty string is returned if unable to construct a url
 func getCommitURL(repoURL, commitHash string) string {
 	parsedURL, err := url.Parse(repoURL)
@@ -261,10 +637,13 @@ func getCommitURL(repoURL, commitHash string) string {
 		return fmt.Sprintf("%s/commits/%s", repoURL, commitHash)
 	case strings.Contains(host, "dev.azure.com"):
 		return fmt.Sprintf("%s/commit/%s", repoURL, commitHash)
+	case strings.Contains(parsedURL.Path, "/projects/") && strings.Contains(parsedURL.Path, "/repos/"),
+		strings.HasPrefix(parsedURL.Path, "/users/") && strings.Contains(parsedURL.Path, "/repos/"):
+		// Bitbucket Data Center (self hosted)
+		return fmt.Sprintf("%s/commits/%s", repoURL, commitHash)
 	default:
 		// self-hosted instances can have custom domain names.
 		// in this case, we default to repoURL/commit/commitHash
-		// which works except for Bitbucket Data Center (self hosted)
 		return fmt.Sprintf("%s/commit/%s", repoURL, commitHash)
 	}
 }
@@ -307,11 +686,273 @@ func (gv *GitView) MatchPatternInCommitMessageORBranchName(pattern, commitSHA, s
 	return matches, commitInfo, nil
 }
 
+// CommitReferences are the matches of a pattern in the message of a commit
+type CommitReferences struct {
+	Sha1       string   `json:"sha1"`
+	Author     string   `json:"author"`
+	References []string `json:"references"`
+	// IsMerge is true for commits with more than one parent
+	IsMerge bool `json:"is_merge,omitempty"`
+}
+
+// MatchPatternInCommitRange finds the matches of a pattern in the messages of the commits
+// between oldest (excluded) and newest (included), newest first. When paths are given, only the
+// commits changing files matching the paths are searched.
+func (gv *GitView) MatchPatternInCommitRange(pattern, oldest, newest string, paths []string, logger *logger.Logger) ([]*CommitReferences, error) {
+	commitReferences := []*CommitReferences{}
+	commits, err := gv.CommitsBetween(oldest, newest, paths, logger)
+	if err != nil {
+		return commitReferences, err
+	}
+
+	re := regexp.MustCompile(pattern)
+	for _, commit := range commits {
+		references := uniqueSorted(re.FindAllString(commit.Message, -1))
+		commitReferences = append(commitReferences, &CommitReferences{
+			Sha1:       commit.Sha1,
+			Author:     commit.Author,
+			References: references,
+			IsMerge:    len(commit.Parents) > 1,
+		})
+	}
+	return commitReferences, nil
+}
+
+// ChangedPaths returns the sorted paths of the files changed by a commit, compared to its first parent.
+// All the files of the commit are changed when it has no parent.
+func (gv *GitView) ChangedPaths(commitSHA string) ([]string, error) {
+	hash, err := gv.repository.ResolveRevision(plumbing.Revision(commitSHA))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve git reference %s: %v", commitSHA, err)
+	}
+	changes, err := gv.commitChanges(*hash)
+	if err != nil {
+		return nil, err
+	}
+	paths := []string{}
+	for _, change := range changes {
+		// a renamed file is changed at both its old and new paths
+		for _, path := range []string{change.From.Name, change.To.Name} {
+			if path != "" {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return uniqueSorted(paths), nil
+}
+
+// commitChanges returns the changes of a commit compared to its first parent, detecting renames.
+// All the files of the commit are inserted when it has no parent.
+func (gv *GitView) commitChanges(hash plumbing.Hash) (object.Changes, error) {
+	commit, err := gv.repository.CommitObject(hash)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve commit for %s: %v", hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("could not retrieve the parent of commit %s: %v", hash, err)
+		}
+		parentTree, err = parent.Tree()
+		if err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTreeWithOptions(context.Background(), parentTree, tree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff commit %s: %v", hash, err)
+	}
+	return changes, nil
+}
+
+// SignedCommit is a commit with its signature, if any, and the content which the signature signs
+type SignedCommit struct {
+	Sha1   string
+	Author string
+	// CommitTime is the committer time, at which the signature is verified
+	CommitTime time.Time
+	// Signature is the armored GPG, SSH or x509 signature of the commit. It is empty when the commit is not signed.
+	Signature string
+	// Payload is the commit object without its signature
+	Payload []byte
+}
+
+// SignedCommitsBetween returns the commits between oldest (excluded) and newest (included), newest first,
+// with their signatures. When oldest and newest are the same commit, only this commit is returned.
+func (gv *GitView) SignedCommitsBetween(oldest, newest string, logger *logger.Logger) ([]*SignedCommit, error) {
+	signedCommits := []*SignedCommit{}
+	commits, err := gv.CommitsBetween(oldest, newest, nil, logger)
+	if err != nil {
+		return signedCommits, err
+	}
+
+	for _, commit := range commits {
+		commitObject, err := gv.repository.CommitObject(plumbing.NewHash(commit.Sha1))
+		if err != nil {
+			return signedCommits, err
+		}
+		encoded := &plumbing.MemoryObject{}
+		err = commitObject.EncodeWithoutSignature(encoded)
+		if err != nil {
+			return signedCommits, fmt.Errorf("failed to encode git commit %s: %v", commit.Sha1, err)
+		}
+		reader, err := encoded.Reader()
+		if err != nil {
+			return signedCommits, err
+		}
+		payload, err := io.ReadAll(reader)
+		if err != nil {
+			return signedCommits, err
+		}
+		signedCommits = append(signedCommits, &SignedCommit{
+			Sha1:       commit.Sha1,
+			Author:     commit.Author,
+			CommitTime: commitObject.Committer.When,
+			Signature:  commitObject.PGPSignature,
+			Payload:    payload,
+		})
+	}
+	return signedCommits, nil
+}
+
+// uniqueSorted returns the sorted values without duplicates
+func uniqueSorted(values []string) []string {
+	unique := make(map[string]struct{})
+	for _, value := range values {
+		unique[value] = struct{}{}
+	}
+	result := make([]string, 0, len(unique))
+	for value := range unique {
+		result = append(result, value)
+	}
+	sort.Strings(result)
+	return result
+}
+
 // ResolveRevision returns an explicit commit SHA1 from commit SHA or ref (e.g. HEAD~2)
 func (gv *GitView) ResolveRevision(commitSHAOrRef string) (string, error) {
-	hash, err := gv.repository.ResolveRevision(plumbing.Revision(commitSHAOrRef))
+	hash, err := gv.resolveRevision(commitSHAOrRef)
 	if err != nil {
 		return "", err
 	}
 	return hash.String(), nil
 }
+
+// resolveRevision resolves a commit SHA or ref, deepening a shallow clone if needed (see EnableAutoDeepen)
+func (gv *GitView) resolveRevision(commitSHAOrRef string) (*plumbing.Hash, error) {
+	var hash *plumbing.Hash
+	err := gv.withDeepening(func() error {
+		var err error
+		hash, err = gv.repository.ResolveRevision(plumbing.Revision(commitSHAOrRef))
+		return missingObject(err, err, commitSHAOrRef)
+	})
+	return hash, err
+}
+
+// TreeSha256 returns a sha256 digest of the git tree at a given commit SHA or ref (e.g. HEAD~2).
+// If subPath is not empty, only the subtree at that path (relative to the repository root) is fingerprinted.
+// The digest is calculated from the committed git objects only, so it does not depend on the
+// state of the worktree, line-ending conversion or any .git metadata.
+// Entries are processed in the same order and with the same name/content digests as a
+// 'dir' fingerprint, so a clean checkout of the same tree has the same fingerprint as long as
+// it contains no symlinks or submodules. Symlinks are fingerprinted by their target path and
+// submodules by the commit they point to.
+func (gv *GitView) TreeSha256(commitSHAOrRef, subPath string) (string, error) {
+	hash, err := gv.repository.ResolveRevision(plumbing.Revision(commitSHAOrRef))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve git reference %s: %v", commitSHAOrRef, err)
+	}
+	commit, err := gv.repository.CommitObject(*hash)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve commit for %s: %v", *hash, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve git tree for commit %s: %v", *hash, err)
+	}
+
+	subPath = strings.Trim(path.Clean("/"+strings.ReplaceAll(subPath, "\\", "/")), "/")
+	if subPath != "" {
+		tree, err = tree.Tree(subPath)
+		if err != nil {
+			return "", fmt.Errorf("path %s is not a directory in the git tree of commit %s: %v", subPath, *hash, err)
+		}
+	}
+
+	hasher := sha256.New()
+	if err := gv.writeTreeDigests(hasher, tree); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// writeTreeDigests recursively writes the name and content digests of a git tree's entries
+func (gv *GitView) writeTreeDigests(hasher io.Writer, tree *object.Tree) error {
+	// git sorts tree entries as if directory names had a trailing '/',
+	// sort them by plain name to match the order of a directory walk
+	entries := make([]object.TreeEntry, len(tree.Entries))
+	copy(entries, tree.Entries)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	for _, entry := range entries {
+		if err := writeDigest(hasher, strings.NewReader(entry.Name)); err != nil {
+			return err
+		}
+
+		switch entry.Mode {
+		case filemode.Dir:
+			subTree, err := object.GetTree(gv.repository.Storer, entry.Hash)
+			if err != nil {
+				return fmt.Errorf("could not retrieve git tree %s: %v", entry.Name, err)
+			}
+			if err := gv.writeTreeDigests(hasher, subTree); err != nil {
+				return err
+			}
+		case filemode.Submodule:
+			if err := writeDigest(hasher, strings.NewReader(entry.Hash.String())); err != nil {
+				return err
+			}
+		default:
+			blob, err := gv.repository.BlobObject(entry.Hash)
+			if err != nil {
+				return fmt.Errorf("could not retrieve git blob %s: %v", entry.Name, err)
+			}
+			reader, err := blob.Reader()
+			if err != nil {
+				return err
+			}
+			err = writeDigest(hasher, reader)
+			reader.Close()
+			if err != nil {
+				return fmt.Errorf("could not read git blob %s: %v", entry.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeDigest writes the hex encoded sha256 digest of the content of a reader
+func writeDigest(hasher io.Writer, r io.Reader) error {
+	digest, err := sha256Hex(r)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(hasher, digest)
+	return err
+}
+
+// sha256Hex returns the hex encoded sha256 digest of the content of a reader
+func sha256Hex(r io.Reader) (string, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
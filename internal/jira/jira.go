@@ -1,17 +1,26 @@
 package jira
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 
 	jira "github.com/andygrunwald/go-jira"
+	"github.com/kosli-dev/cli/internal/types"
 )
 
+// IssueKeyPattern matches Jira issue keys, which consist of [project-key]-[sequential-number].
+// The project key must be at least 2 characters long and start with an uppercase letter.
+// More info: https://support.atlassian.com/jira-software-cloud/docs/what-is-an-issue/#Workingwithissues-Projectandissuekeys
+const IssueKeyPattern = `[A-Z][A-Z0-9]{1,9}-[0-9]+`
+
 type JiraConfig struct {
 	Username string
 	APIToken string // API tokens are used in Jira Cloud
 	PAT      string // Personal access tokens are used in self-hosted Jira
 	BaseURL  string
+	// IssueFields is the comma-separated list of issue fields included by GetIssueInfo
+	IssueFields string
 }
 
 type JiraIssueInfo struct {
@@ -84,7 +93,7 @@ func (jc *JiraConfig) GetJiraIssueInfo(issueID string, issueFields string) (*Jir
 	}
 
 	issue, response, err := jiraClient.Issue.Get(issueID, &queryOptions)
-	if err != nil && response.StatusCode != http.StatusNotFound {
+	if err != nil && (response == nil || response.StatusCode != http.StatusNotFound) {
 		return result, err
 	}
 
@@ -96,3 +105,54 @@ func (jc *JiraConfig) GetJiraIssueInfo(issueID string, issueFields string) (*Jir
 	}
 	return result, nil
 }
+
+// ReferencePattern returns the pattern of Jira issue keys
+func (jc *JiraConfig) ReferencePattern() string {
+	return IssueKeyPattern
+}
+
+// GetIssueInfo looks up a Jira issue. The status of the issue is always retrieved,
+// and the fields in IssueFields are included in the result.
+func (jc *JiraConfig) GetIssueInfo(reference string) (*types.IssueInfo, error) {
+	fields := "status"
+	if jc.IssueFields != "" {
+		fields = jc.IssueFields + ",status"
+	}
+	jiraInfo, err := jc.GetJiraIssueInfo(reference, fields)
+	if err != nil {
+		return nil, err
+	}
+	result := &types.IssueInfo{
+		IssueID:     jiraInfo.IssueID,
+		IssueURL:    jiraInfo.IssueURL,
+		IssueExists: jiraInfo.IssueExists,
+	}
+	if jiraInfo.IssueFields == nil {
+		return result, nil
+	}
+	if jiraInfo.IssueFields.Status != nil {
+		result.Status = jiraInfo.IssueFields.Status.Name
+	}
+	if jc.IssueFields != "" {
+		result.IssueFields, err = fieldsToMap(jiraInfo.IssueFields)
+		if err != nil {
+			return nil, err
+		}
+		// status was only added to the requested fields to fill in Status
+		if !hasField(jc.IssueFields, "status") && !hasField(jc.IssueFields, "*all") {
+			delete(result.IssueFields, "status")
+		}
+	}
+	return result, nil
+}
+
+// fieldsToMap converts Jira issue fields, including custom fields, to a map
+func fieldsToMap(fields *jira.IssueFields) (map[string]interface{}, error) {
+	content, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]interface{}{}
+	err = json.Unmarshal(content, &result)
+	return result, err
+}
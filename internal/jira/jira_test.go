@@ -0,0 +1,102 @@
+package jira
+
+import (
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/types"
+	"github.com/maxcnunes/httpfake"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type JiraTestSuite struct {
+	suite.Suite
+	fakeJira *httpfake.HTTPFake
+}
+
+// create a fake Jira server before the suite execution
+func (suite *JiraTestSuite) SetupSuite() {
+	suite.fakeJira = httpfake.New()
+	suite.fakeJira.NewHandler().
+		Get("/rest/api/2/issue/ABC-1").
+		Reply(200).
+		BodyString(`{"id": "10001", "key": "ABC-1", "fields": {"summary": "Add basket", "status": {"name": "In Progress"}, "customfield_10020": "sprint 7"}}`)
+	suite.fakeJira.NewHandler().
+		Get("/rest/api/2/issue/ABC-2").
+		Reply(404).
+		BodyString(`{"errorMessages": ["Issue does not exist or you do not have permission to see it."], "errors": {}}`)
+	suite.fakeJira.NewHandler().
+		Get("/rest/api/2/issue/ABC-3").
+		Reply(500).
+		BodyString(`{"errorMessages": ["Internal server error"], "errors": {}}`)
+}
+
+// shutdown the fake Jira server after the suite execution
+func (suite *JiraTestSuite) TearDownSuite() {
+	suite.fakeJira.Close()
+}
+
+func (suite *JiraTestSuite) TestGetIssueInfo() {
+	for _, t := range []struct {
+		name        string
+		issueFields string
+		reference   string
+		wantExists  bool
+		wantStatus  string
+		wantFields  []string
+		wantError   bool
+	}{
+		{
+			name:       "an existing issue has its status and no fields by default",
+			reference:  "ABC-1",
+			wantExists: true,
+			wantStatus: "In Progress",
+		},
+		{
+			name:        "the requested fields are included",
+			issueFields: "summary,customfield_10020",
+			reference:   "ABC-1",
+			wantExists:  true,
+			wantStatus:  "In Progress",
+			wantFields:  []string{"summary", "customfield_10020"},
+		},
+		{
+			name:       "a missing issue does not exist",
+			reference:  "ABC-2",
+			wantExists: false,
+		},
+		{
+			name:      "a server error is an error",
+			reference: "ABC-3",
+			wantError: true,
+		},
+	} {
+		suite.Suite.Run(t.name, func() {
+			jc := NewJiraConfig(suite.fakeJira.ResolveURL(""), "", "", "some-pat")
+			jc.IssueFields = t.issueFields
+			var tracker types.IssueTracker = jc
+			require.Equal(suite.Suite.T(), IssueKeyPattern, tracker.ReferencePattern())
+
+			info, err := jc.GetIssueInfo(t.reference)
+			if t.wantError {
+				require.Error(suite.Suite.T(), err)
+				return
+			}
+			require.NoError(suite.Suite.T(), err)
+			require.Equal(suite.Suite.T(), t.reference, info.IssueID)
+			require.Equal(suite.Suite.T(), suite.fakeJira.ResolveURL("/browse/%s", t.reference), info.IssueURL)
+			require.Equal(suite.Suite.T(), t.wantExists, info.IssueExists)
+			require.Equal(suite.Suite.T(), t.wantStatus, info.Status)
+			for _, field := range t.wantFields {
+				require.Contains(suite.Suite.T(), info.IssueFields, field)
+			}
+			require.NotContains(suite.Suite.T(), info.IssueFields, "status")
+		})
+	}
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestJiraTestSuite(t *testing.T) {
+	suite.Run(t, new(JiraTestSuite))
+}
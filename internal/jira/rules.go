@@ -0,0 +1,156 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/kosli-dev/cli/internal/attestationtype"
+)
+
+// IssueRules are the rules a referenced Jira issue must satisfy to be compliant
+type IssueRules struct {
+	// RequiredStatuses are the statuses the issue may be in, any status is allowed when empty
+	RequiredStatuses []string
+	// JqRules are jq expressions over the issue fields which must all evaluate to true
+	JqRules []string
+}
+
+// IssueRuleResult is the outcome of evaluating the IssueRules against one issue
+type IssueRuleResult struct {
+	IssueID string `json:"issue_id"`
+	Status  string `json:"status,omitempty"`
+	Passed  bool   `json:"passed"`
+	// Failures explain which rules the issue does not satisfy
+	Failures []string `json:"failures,omitempty"`
+}
+
+// IsEmpty is true when there are no rules to evaluate
+func (r *IssueRules) IsEmpty() bool {
+	return len(r.RequiredStatuses) == 0 && len(r.JqRules) == 0
+}
+
+// FieldsToFetch returns the issue fields needed to evaluate the rules, in addition to the requested
+// issueFields. All fields are fetched for jq rules, since they can refer to any field, and a rule over
+// a field which was not fetched would see null. Use TrimFields to report only the requested fields.
+func (r *IssueRules) FieldsToFetch(issueFields string) string {
+	switch {
+	case len(r.JqRules) > 0:
+		return "*all"
+	case len(r.RequiredStatuses) > 0 && issueFields == "":
+		return "status"
+	case len(r.RequiredStatuses) > 0 && !hasField(issueFields, "status") && !hasField(issueFields, "*all"):
+		return issueFields + ",status"
+	default:
+		return issueFields
+	}
+}
+
+// TrimFields returns the issue fields reduced to the comma-separated issueFields, the way Jira
+// selects them: "*all" and "*navigable" keep all the fields and a "-" prefix excludes a field.
+// It returns nil when issueFields is empty.
+func TrimFields(fields *jira.IssueFields, issueFields string) (*jira.IssueFields, error) {
+	if fields == nil || issueFields == "" {
+		return nil, nil
+	}
+	all, err := fieldsToMap(fields)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := map[string]interface{}{}
+	for _, field := range splitFields(issueFields) {
+		switch {
+		case field == "*all" || field == "*navigable":
+			for key, value := range all {
+				trimmed[key] = value
+			}
+		case !strings.HasPrefix(field, "-"):
+			if value, ok := all[field]; ok {
+				trimmed[field] = value
+			}
+		}
+	}
+	for _, field := range splitFields(issueFields) {
+		if strings.HasPrefix(field, "-") {
+			delete(trimmed, strings.TrimPrefix(field, "-"))
+		}
+	}
+	content, err := json.Marshal(trimmed)
+	if err != nil {
+		return nil, err
+	}
+	result := &jira.IssueFields{}
+	err = json.Unmarshal(content, result)
+	return result, err
+}
+
+// hasField is true when field is one of the comma-separated fields
+func hasField(fields, field string) bool {
+	for _, f := range splitFields(fields) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFields splits a comma-separated list of issue fields
+func splitFields(fields string) []string {
+	result := []string{}
+	for _, field := range strings.Split(fields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// Evaluate checks an issue against the rules. An issue which does not exist fails all rules.
+// The issue must have been fetched with the fields returned by FieldsToFetch.
+func (r *IssueRules) Evaluate(issue *JiraIssueInfo) (*IssueRuleResult, error) {
+	result := &IssueRuleResult{IssueID: issue.IssueID}
+	if !issue.IssueExists {
+		result.Failures = append(result.Failures, "issue does not exist")
+		return result, nil
+	}
+
+	fields := map[string]interface{}{}
+	if issue.IssueFields != nil {
+		if issue.IssueFields.Status != nil {
+			result.Status = issue.IssueFields.Status.Name
+		}
+		var err error
+		fields, err = fieldsToMap(issue.IssueFields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(r.RequiredStatuses) > 0 && !containsFold(r.RequiredStatuses, result.Status) {
+		result.Failures = append(result.Failures, fmt.Sprintf("status '%s' is not one of: %s",
+			result.Status, strings.Join(r.RequiredStatuses, ", ")))
+	}
+
+	ruleResults, err := attestationtype.EvaluateRules(r.JqRules, fields)
+	if err != nil {
+		return nil, err
+	}
+	for _, ruleResult := range ruleResults {
+		if !ruleResult.Passed {
+			result.Failures = append(result.Failures, fmt.Sprintf("rule '%s' is not true: %s", ruleResult.Rule, ruleResult.Output))
+		}
+	}
+	result.Passed = len(result.Failures) == 0
+	return result, nil
+}
+
+// containsFold is true when value is in values, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
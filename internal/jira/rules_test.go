@@ -0,0 +1,185 @@
+package jira
+
+import (
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldsToFetch(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		rules       IssueRules
+		issueFields string
+		want        string
+	}{
+		{
+			name: "no rules fetch the requested fields",
+			want: "",
+		},
+		{
+			name:        "no rules fetch the requested fields when there are some",
+			issueFields: "summary",
+			want:        "summary",
+		},
+		{
+			name:  "required statuses fetch the status",
+			rules: IssueRules{RequiredStatuses: []string{"Done"}},
+			want:  "status",
+		},
+		{
+			name:        "required statuses add the status to the requested fields",
+			rules:       IssueRules{RequiredStatuses: []string{"Done"}},
+			issueFields: "summary",
+			want:        "summary,status",
+		},
+		{
+			name:        "required statuses do not add the status to all fields",
+			rules:       IssueRules{RequiredStatuses: []string{"Done"}},
+			issueFields: "*all",
+			want:        "*all",
+		},
+		{
+			name:  "jq rules fetch all fields",
+			rules: IssueRules{JqRules: []string{".fixVersions | length > 0"}},
+			want:  "*all",
+		},
+		{
+			name:        "jq rules fetch all fields when some fields are requested",
+			rules:       IssueRules{JqRules: []string{`.issuetype.name != "Spike"`}},
+			issueFields: "fixVersions",
+			want:        "*all",
+		},
+		{
+			name:        "required statuses add the status to requested fields with a similar name",
+			rules:       IssueRules{RequiredStatuses: []string{"Done"}},
+			issueFields: "summary,statuscategorychangedate",
+			want:        "summary,statuscategorychangedate,status",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.rules.FieldsToFetch(tt.issueFields))
+		})
+	}
+}
+
+func TestTrimFields(t *testing.T) {
+	fields := &jira.IssueFields{
+		Summary:     "Basket total is wrong",
+		Status:      &jira.Status{Name: "Done"},
+		Type:        jira.IssueType{Name: "Story"},
+		FixVersions: []*jira.FixVersion{{Name: "1.2.0"}},
+	}
+	for _, tt := range []struct {
+		name        string
+		issueFields string
+		want        *jira.IssueFields
+	}{
+		{
+			name: "no requested fields report no fields",
+			want: nil,
+		},
+		{
+			name:        "only the requested fields are reported",
+			issueFields: "summary, fixVersions",
+			want: &jira.IssueFields{
+				Summary:     "Basket total is wrong",
+				FixVersions: []*jira.FixVersion{{Name: "1.2.0"}},
+			},
+		},
+		{
+			name:        "all fields are reported except the excluded ones",
+			issueFields: "*all,-status,-fixVersions",
+			want: &jira.IssueFields{
+				Summary: "Basket total is wrong",
+				Type:    jira.IssueType{Name: "Story"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			trimmed, err := TrimFields(fields, tt.issueFields)
+			require.NoError(t, err)
+			if tt.want == nil {
+				require.Nil(t, trimmed)
+				return
+			}
+			want, err := fieldsToMap(tt.want)
+			require.NoError(t, err)
+			got, err := fieldsToMap(trimmed)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	approvedIssue := &JiraIssueInfo{
+		IssueID:     "ABC-1",
+		IssueExists: true,
+		IssueFields: &jira.IssueFields{
+			Status:      &jira.Status{Name: "Approved for release"},
+			Type:        jira.IssueType{Name: "Story"},
+			FixVersions: []*jira.FixVersion{{Name: "1.2.0"}},
+		},
+	}
+	spikeIssue := &JiraIssueInfo{
+		IssueID:     "ABC-2",
+		IssueExists: true,
+		IssueFields: &jira.IssueFields{
+			Status: &jira.Status{Name: "In Progress"},
+			Type:   jira.IssueType{Name: "Spike"},
+		},
+	}
+	policy := IssueRules{
+		RequiredStatuses: []string{"approved for release"},
+		JqRules:          []string{".fixVersions | length > 0", `.issuetype.name != "Spike"`},
+	}
+
+	for _, tt := range []struct {
+		name      string
+		rules     IssueRules
+		issue     *JiraIssueInfo
+		want      *IssueRuleResult
+		wantError bool
+	}{
+		{
+			name:  "an issue satisfying all rules passes",
+			rules: policy,
+			issue: approvedIssue,
+			want:  &IssueRuleResult{IssueID: "ABC-1", Status: "Approved for release", Passed: true},
+		},
+		{
+			name:  "an issue failing rules explains each failure",
+			rules: policy,
+			issue: spikeIssue,
+			want: &IssueRuleResult{IssueID: "ABC-2", Status: "In Progress", Failures: []string{
+				"status 'In Progress' is not one of: approved for release",
+				"rule '.fixVersions | length > 0' is not true: false",
+				"rule '.issuetype.name != \"Spike\"' is not true: false",
+			}},
+		},
+		{
+			name:  "an issue which does not exist fails",
+			rules: policy,
+			issue: &JiraIssueInfo{IssueID: "ABC-3"},
+			want:  &IssueRuleResult{IssueID: "ABC-3", Failures: []string{"issue does not exist"}},
+		},
+		{
+			name:      "an invalid jq rule is an error",
+			rules:     IssueRules{JqRules: []string{".fixVersions |"}},
+			issue:     approvedIssue,
+			wantError: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.rules.Evaluate(tt.issue)
+			if tt.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, result)
+		})
+	}
+}
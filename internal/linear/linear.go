@@ -0,0 +1,144 @@
+package linear
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/kosli-dev/cli/internal/types"
+)
+
+// DefaultBaseURL is the URL of the Linear API
+const DefaultBaseURL = "https://api.linear.app"
+
+// IssueReferencePattern matches Linear issue identifiers, which consist of [team-key]-[number]
+const IssueReferencePattern = `[A-Z][A-Z0-9]{0,6}-[0-9]+`
+
+const issueQuery = `query Issue($id: String!) {
+  issue(id: $id) {
+    identifier
+    url
+    title
+    state { name type }
+    assignee { name }
+    labels { nodes { name } }
+  }
+}`
+
+type LinearConfig struct {
+	// APIKey is a personal API key, or an OAuth access token prefixed with "Bearer "
+	APIKey      string
+	BaseURL     string
+	KosliClient *requests.Client
+}
+
+// NewLinearConfig returns a new LinearConfig
+func NewLinearConfig(apiKey, baseURL string) *LinearConfig {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &LinearConfig{
+		APIKey:  apiKey,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// ReferencePattern returns the pattern of Linear issue identifiers
+func (c *LinearConfig) ReferencePattern() string {
+	return IssueReferencePattern
+}
+
+// GetIssueInfo looks up a Linear issue from its identifier, e.g. "ENG-12"
+func (c *LinearConfig) GetIssueInfo(reference string) (*types.IssueInfo, error) {
+	result := &types.IssueInfo{IssueID: reference}
+
+	response, err := c.query(reference)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data.Issue == nil {
+		for _, e := range response.Errors {
+			if !strings.Contains(strings.ToLower(e.Message), "not found") {
+				return nil, fmt.Errorf("failed to get issue %s from Linear: %s", reference, e.Message)
+			}
+		}
+		return result, nil
+	}
+
+	issue := response.Data.Issue
+	result.IssueExists = true
+	result.IssueURL = issue.URL
+	result.Status = issue.State.Name
+	labels := []string{}
+	for _, label := range issue.Labels.Nodes {
+		labels = append(labels, label.Name)
+	}
+	assignee := ""
+	if issue.Assignee != nil {
+		assignee = issue.Assignee.Name
+	}
+	result.IssueFields = map[string]interface{}{
+		"title":      issue.Title,
+		"state_type": issue.State.Type,
+		"assignee":   assignee,
+		"labels":     labels,
+	}
+	return result, nil
+}
+
+// query runs the issue query against the Linear GraphQL API
+func (c *LinearConfig) query(reference string) (*graphQLResponse, error) {
+	reqParams := &requests.RequestParams{
+		Method: http.MethodPost,
+		URL:    c.BaseURL + "/graphql",
+		Payload: map[string]interface{}{
+			"query":     issueQuery,
+			"variables": map[string]string{"id": reference},
+		},
+		AdditionalHeaders: map[string]string{"Authorization": c.APIKey},
+	}
+	resp, err := c.KosliClient.Do(reqParams)
+	if err != nil {
+		var httpErr *requests.HTTPError
+		if errors.As(err, &httpErr) {
+			return nil, fmt.Errorf("failed to get issue %s from Linear, got HTTP status %d: %v", reference, httpErr.StatusCode, err)
+		}
+		return nil, fmt.Errorf("failed to get issue %s from Linear: %v", reference, err)
+	}
+	var response graphQLResponse
+	if err := json.Unmarshal([]byte(resp.Body), &response); err != nil {
+		return nil, fmt.Errorf("failed to get issue %s from Linear: %v", reference, err)
+	}
+	return &response, nil
+}
+
+type graphQLResponse struct {
+	Data struct {
+		Issue *issue `json:"issue"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type issue struct {
+	Identifier string `json:"identifier"`
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+	State      struct {
+		Name string `json:"name"`
+		// Type is one of triage, backlog, unstarted, started, completed or canceled
+		Type string `json:"type"`
+	} `json:"state"`
+	Assignee *struct {
+		Name string `json:"name"`
+	} `json:"assignee"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+}
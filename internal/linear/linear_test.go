@@ -0,0 +1,82 @@
+package linear
+
+import (
+	"testing"
+
+	"github.com/kosli-dev/cli/internal/logger"
+	"github.com/kosli-dev/cli/internal/requests"
+	"github.com/maxcnunes/httpfake"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetIssueInfo(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		status     int
+		response   string
+		wantExists bool
+		wantStatus string
+		wantFields map[string]interface{}
+		wantError  bool
+	}{
+		{
+			name:   "an existing issue is found with its state and fields",
+			status: 200,
+			response: `{"data": {"issue": {"identifier": "ENG-12", "url": "https://linear.app/acme/issue/ENG-12",
+				"title": "Basket total is wrong", "state": {"name": "In Review", "type": "started"},
+				"assignee": {"name": "Bob Jones"}, "labels": {"nodes": [{"name": "bug"}]}}}}`,
+			wantExists: true,
+			wantStatus: "In Review",
+			wantFields: map[string]interface{}{
+				"title":      "Basket total is wrong",
+				"state_type": "started",
+				"assignee":   "Bob Jones",
+				"labels":     []string{"bug"},
+			},
+		},
+		{
+			name:     "a missing issue is not found",
+			status:   200,
+			response: `{"data": null, "errors": [{"message": "Entity not found: Issue", "extensions": {"code": "INPUT_ERROR"}}]}`,
+		},
+		{
+			name:      "an authentication failure causes an error",
+			status:    400,
+			response:  `{"errors": [{"message": "Authentication required, not authenticated", "extensions": {"code": "AUTHENTICATION_ERROR"}}]}`,
+			wantError: true,
+		},
+		{
+			name:      "a response which is not GraphQL causes an error",
+			status:    502,
+			response:  `<html>bad gateway</html>`,
+			wantError: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeLinear := httpfake.New(httpfake.WithTesting(t))
+			defer fakeLinear.Close()
+			fakeLinear.NewHandler().
+				Post("/graphql").
+				AssertHeaderValue("Authorization", "lin_api_key").
+				Reply(tt.status).
+				BodyString(tt.response)
+
+			config := NewLinearConfig("lin_api_key", fakeLinear.ResolveURL("/"))
+			kosliClient, err := requests.NewKosliClient("", 0, false, logger.NewStandardLogger())
+			require.NoError(t, err)
+			config.KosliClient = kosliClient
+			require.Equal(t, IssueReferencePattern, config.ReferencePattern())
+
+			info, err := config.GetIssueInfo("ENG-12")
+			if tt.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "ENG-12", info.IssueID)
+			require.Equal(t, tt.wantExists, info.IssueExists)
+			require.Equal(t, tt.wantStatus, info.Status)
+			require.Equal(t, tt.wantFields, info.IssueFields)
+		})
+	}
+}
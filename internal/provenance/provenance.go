@@ -0,0 +1,347 @@
+package provenance
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/kosli-dev/cli/internal/dsse"
+)
+
+var scpLikeURL = regexp.MustCompile(`^[^/@:]+@[^/:]+:[^/]`)
+
+const (
+	StatementV01 = "https://in-toto.io/Statement/v0.1"
+	StatementV1  = "https://in-toto.io/Statement/v1"
+
+	PredicateSLSAv01 = "https://slsa.dev/provenance/v0.1"
+	PredicateSLSAv02 = "https://slsa.dev/provenance/v0.2"
+	PredicateSLSAv1  = "https://slsa.dev/provenance/v1"
+
+	InTotoPayloadType = "application/vnd.in-toto+json"
+
+	// SubjectMatch and the following constants are the results of checking the
+	// statement subjects against the artifact fingerprint
+	SubjectMatch    = "match"
+	SubjectMismatch = "mismatch"
+	SubjectSkipped  = "skipped"
+)
+
+type Subject struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+type ProvenanceData struct {
+	SchemaVersion    int       `json:"schema_version"`
+	StatementType    string    `json:"statement_type"`
+	PredicateType    string    `json:"predicate_type"`
+	Envelope         string    `json:"envelope,omitempty"`
+	SignatureKeyIDs  []string  `json:"signature_key_ids,omitempty"`
+	Subjects         []Subject `json:"subjects"`
+	SubjectCheck     string    `json:"subject_check"`
+	BuilderID        string    `json:"builder_id,omitempty"`
+	BuildType        string    `json:"build_type,omitempty"`
+	SourceRepo       string    `json:"source_repo,omitempty"`
+	SourceCommit     string    `json:"source_commit,omitempty"`
+	PolicyViolations []string  `json:"policy_violations,omitempty"`
+}
+
+// Policy holds the expected values of the provenance. Empty values are not checked.
+type Policy struct {
+	ExpectedBuilder    string
+	ExpectedSourceRepo string
+}
+
+type statement struct {
+	Type    string `json:"_type"`
+	Subject []struct {
+		Name   string            `json:"name"`
+		Digest map[string]string `json:"digest"`
+	} `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// ProcessProvenanceFile parses an in-toto statement, a DSSE envelope containing one,
+// or a sigstore bundle containing one. Files with one JSON document per line
+// (e.g. .intoto.jsonl) are supported, in which case the statement with a subject
+// matching the fingerprint is used (or the first statement if none matches).
+// The subjects are checked against the fingerprint, unless it is empty.
+func ProcessProvenanceFile(file, fingerprint string) (*ProvenanceData, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := [][]byte{bytes.TrimSpace(content)}
+	if !json.Valid(documents[0]) {
+		documents = [][]byte{}
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		scanner.Buffer(make([]byte, 1024*1024), 50*1024*1024)
+		for scanner.Scan() {
+			if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+				documents = append(documents, append([]byte{}, line...))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read provenance file [%s]: %s", file, err)
+		}
+	}
+	if len(documents) == 0 {
+		return nil, fmt.Errorf("failed to parse provenance file [%s]: the file is empty", file)
+	}
+
+	var selected *ProvenanceData
+	for _, document := range documents {
+		data, err := parseDocument(document)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse provenance file [%s]: %s", file, err)
+		}
+		data.checkSubjects(fingerprint)
+		if selected == nil || (selected.SubjectCheck == SubjectMismatch && data.SubjectCheck == SubjectMatch) {
+			selected = data
+		}
+	}
+	return selected, nil
+}
+
+func parseDocument(document []byte) (*ProvenanceData, error) {
+	var probe struct {
+		Type         string         `json:"_type"`
+		PayloadType  string         `json:"payloadType"`
+		DSSEEnvelope *dsse.Envelope `json:"dsseEnvelope"`
+	}
+	if err := json.Unmarshal(document, &probe); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case probe.Type != "":
+		return parseStatement(document)
+	case probe.PayloadType != "":
+		var envelope dsse.Envelope
+		if err := json.Unmarshal(document, &envelope); err != nil {
+			return nil, err
+		}
+		return parseEnvelope(&envelope)
+	case probe.DSSEEnvelope != nil:
+		return parseEnvelope(probe.DSSEEnvelope)
+	}
+	return nil, fmt.Errorf("not an in-toto statement, a DSSE envelope or a sigstore bundle")
+}
+
+func parseEnvelope(envelope *dsse.Envelope) (*ProvenanceData, error) {
+	if envelope.PayloadType != InTotoPayloadType {
+		return nil, fmt.Errorf("unsupported DSSE payload type: %s", envelope.PayloadType)
+	}
+	payload, err := envelope.DecodedPayload()
+	if err != nil {
+		return nil, err
+	}
+	data, err := parseStatement(payload)
+	if err != nil {
+		return nil, err
+	}
+	data.Envelope = "dsse"
+	for _, s := range envelope.Signatures {
+		if s.KeyID != "" {
+			data.SignatureKeyIDs = append(data.SignatureKeyIDs, s.KeyID)
+		}
+	}
+	return data, nil
+}
+
+func parseStatement(content []byte) (*ProvenanceData, error) {
+	var s statement
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, err
+	}
+	if s.Type != StatementV01 && s.Type != StatementV1 {
+		return nil, fmt.Errorf("unsupported in-toto statement type: %s", s.Type)
+	}
+	data := &ProvenanceData{
+		SchemaVersion: 1,
+		StatementType: s.Type,
+		PredicateType: s.PredicateType,
+		Subjects:      []Subject{},
+		SubjectCheck:  SubjectSkipped,
+	}
+	for _, subject := range s.Subject {
+		data.Subjects = append(data.Subjects, Subject{
+			Name:   subject.Name,
+			SHA256: strings.ToLower(subject.Digest["sha256"]),
+		})
+	}
+	if len(s.Predicate) > 0 {
+		if err := data.parsePredicate(s.Predicate); err != nil {
+			return nil, fmt.Errorf("failed to parse %s predicate: %s", s.PredicateType, err)
+		}
+	}
+	return data, nil
+}
+
+type material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+func (d *ProvenanceData) parsePredicate(predicate json.RawMessage) error {
+	switch d.PredicateType {
+	case PredicateSLSAv1:
+		var p struct {
+			BuildDefinition struct {
+				BuildType            string     `json:"buildType"`
+				ResolvedDependencies []material `json:"resolvedDependencies"`
+			} `json:"buildDefinition"`
+			RunDetails struct {
+				Builder struct {
+					ID string `json:"id"`
+				} `json:"builder"`
+			} `json:"runDetails"`
+		}
+		if err := json.Unmarshal(predicate, &p); err != nil {
+			return err
+		}
+		d.BuilderID = p.RunDetails.Builder.ID
+		d.BuildType = p.BuildDefinition.BuildType
+		d.setSource(p.BuildDefinition.ResolvedDependencies)
+	case PredicateSLSAv02:
+		var p struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			BuildType  string `json:"buildType"`
+			Invocation struct {
+				ConfigSource material `json:"configSource"`
+			} `json:"invocation"`
+			Materials []material `json:"materials"`
+		}
+		if err := json.Unmarshal(predicate, &p); err != nil {
+			return err
+		}
+		d.BuilderID = p.Builder.ID
+		d.BuildType = p.BuildType
+		d.setSource(append([]material{p.Invocation.ConfigSource}, p.Materials...))
+	case PredicateSLSAv01:
+		var p struct {
+			Builder struct {
+				ID string `json:"id"`
+			} `json:"builder"`
+			Recipe struct {
+				Type              string `json:"type"`
+				DefinedInMaterial *int   `json:"definedInMaterial"`
+			} `json:"recipe"`
+			Materials []material `json:"materials"`
+		}
+		if err := json.Unmarshal(predicate, &p); err != nil {
+			return err
+		}
+		d.BuilderID = p.Builder.ID
+		d.BuildType = p.Recipe.Type
+		materials := p.Materials
+		if i := p.Recipe.DefinedInMaterial; i != nil && *i >= 0 && *i < len(materials) {
+			materials = append([]material{materials[*i]}, materials...)
+		}
+		d.setSource(materials)
+	}
+	return nil
+}
+
+// setSource uses the first git material as the source repo and commit
+func (d *ProvenanceData) setSource(materials []material) {
+	for _, m := range materials {
+		if !strings.HasPrefix(m.URI, "git+") {
+			continue
+		}
+		d.SourceRepo = trimRef(strings.TrimPrefix(m.URI, "git+"))
+		for _, key := range []string{"gitCommit", "sha1", "sha256"} {
+			if commit := m.Digest[key]; commit != "" {
+				d.SourceCommit = commit
+				break
+			}
+		}
+		return
+	}
+}
+
+func (d *ProvenanceData) checkSubjects(fingerprint string) {
+	if fingerprint == "" {
+		d.SubjectCheck = SubjectSkipped
+		return
+	}
+	d.SubjectCheck = SubjectMismatch
+	for _, s := range d.Subjects {
+		if strings.EqualFold(s.SHA256, fingerprint) {
+			d.SubjectCheck = SubjectMatch
+			return
+		}
+	}
+}
+
+// CheckPolicy records the policy violations and returns true if there are none
+func (d *ProvenanceData) CheckPolicy(policy Policy) bool {
+	d.PolicyViolations = nil
+	if policy.ExpectedBuilder != "" && !builderMatches(policy.ExpectedBuilder, d.BuilderID) {
+		d.PolicyViolations = append(d.PolicyViolations,
+			fmt.Sprintf("builder ID %q does not match the expected builder %q", d.BuilderID, policy.ExpectedBuilder))
+	}
+	if policy.ExpectedSourceRepo != "" && NormalizeRepo(policy.ExpectedSourceRepo) != NormalizeRepo(d.SourceRepo) {
+		d.PolicyViolations = append(d.PolicyViolations,
+			fmt.Sprintf("source repo %q does not match the expected source repo %q", d.SourceRepo, policy.ExpectedSourceRepo))
+	}
+	return len(d.PolicyViolations) == 0
+}
+
+// builderMatches compares builder IDs. When the expected builder has no
+// "@<ref>" suffix, any ref of the builder matches.
+func builderMatches(expected, actual string) bool {
+	if expected == actual {
+		return true
+	}
+	if trimRef(expected) != expected {
+		return false
+	}
+	return expected == trimRef(actual)
+}
+
+// trimRef removes a trailing "@<ref>" from a URI, e.g.
+// "https://github.com/org/repo@refs/heads/main"
+func trimRef(uri string) string {
+	start := 0
+	if i := strings.Index(uri, "://"); i >= 0 {
+		start = i + 3
+		// skip the host, which can contain user info
+		if j := strings.Index(uri[start:], "/"); j >= 0 {
+			start += j
+		}
+	}
+	if i := strings.Index(uri[start:], "@"); i >= 0 {
+		return uri[:start+i]
+	}
+	return uri
+}
+
+// NormalizeRepo makes repo URLs comparable by removing the scheme,
+// user info, port, ref and ".git" suffix. SCP-like URLs
+// (e.g. "git@github.com:org/repo.git") are supported.
+func NormalizeRepo(repo string) string {
+	repo = strings.TrimPrefix(strings.TrimSpace(repo), "git+")
+	if _, rest, found := strings.Cut(repo, "://"); found {
+		repo = rest
+	} else if scpLikeURL.MatchString(repo) {
+		repo = strings.Replace(repo, ":", "/", 1)
+	}
+	host, path, _ := strings.Cut(repo, "/")
+	if _, h, hasUser := strings.Cut(host, "@"); hasUser {
+		host = h
+	}
+	host, _, _ = strings.Cut(host, ":")
+	path, _, _ = strings.Cut(path, "@")
+	repo = host + "/" + strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+	return strings.ToLower(strings.TrimSuffix(repo, "/"))
+}
@@ -0,0 +1,169 @@
+package provenance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fingerprint = "7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9"
+	commit      = "8f4d2d5d3f0a4b6ad6a3e8b1c2f9a0e7d6c5b4a3"
+)
+
+func TestProcessProvenanceFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		fingerprint string
+		want        *ProvenanceData
+		wantErr     string
+	}{
+		{
+			name:    "non-existing file causes an error",
+			file:    "testdata/non-existing.json",
+			wantErr: "no such file or directory",
+		},
+		{
+			name:    "a DSSE envelope with a non in-toto payload causes an error",
+			file:    "testdata/unsupported-payload-type.json",
+			wantErr: "unsupported DSSE payload type: application/json",
+		},
+		{
+			name:    "an unknown statement type causes an error",
+			file:    "testdata/unsupported-statement.json",
+			wantErr: "unsupported in-toto statement type: https://example.com/Statement/v2",
+		},
+		{
+			name:        "an in-toto v1 statement with SLSA v1 provenance is parsed",
+			file:        "testdata/slsa-v1-statement.json",
+			fingerprint: fingerprint,
+			want: &ProvenanceData{
+				SchemaVersion: 1,
+				StatementType: StatementV1,
+				PredicateType: PredicateSLSAv1,
+				Subjects: []Subject{
+					{Name: "app.tgz", SHA256: fingerprint},
+					{Name: "app.sbom", SHA256: "1234e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9"},
+				},
+				SubjectCheck: SubjectMatch,
+				BuilderID:    "https://github.com/actions/runner/github-hosted",
+				BuildType:    "https://actions.github.io/buildtypes/workflow/v1",
+				SourceRepo:   "https://github.com/kosli-dev/cli",
+				SourceCommit: commit,
+			},
+		},
+		{
+			name:        "a DSSE envelope with SLSA v0.2 provenance is parsed",
+			file:        "testdata/slsa-v02-dsse.json",
+			fingerprint: "1111e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9",
+			want: &ProvenanceData{
+				SchemaVersion:   1,
+				StatementType:   StatementV01,
+				PredicateType:   PredicateSLSAv02,
+				Envelope:        "dsse",
+				SignatureKeyIDs: []string{"key-1"},
+				Subjects:        []Subject{{Name: "app.tgz", SHA256: fingerprint}},
+				SubjectCheck:    SubjectMismatch,
+				BuilderID:       "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0",
+				BuildType:       "https://github.com/slsa-framework/slsa-github-generator/generic@v1",
+				SourceRepo:      "https://github.com/kosli-dev/cli",
+				SourceCommit:    commit,
+			},
+		},
+		{
+			name: "a sigstore bundle with SLSA v0.1 provenance is parsed, without a fingerprint to check",
+			file: "testdata/sigstore-bundle.json",
+			want: &ProvenanceData{
+				SchemaVersion: 1,
+				StatementType: StatementV01,
+				PredicateType: PredicateSLSAv01,
+				Envelope:      "dsse",
+				Subjects:      []Subject{{Name: "app.tgz", SHA256: fingerprint}},
+				SubjectCheck:  SubjectSkipped,
+				BuilderID:     "https://cloudbuild.googleapis.com/GoogleHostedWorker",
+				BuildType:     "https://cloudbuild.googleapis.com/CloudBuildYaml@v0.1",
+				SourceRepo:    "ssh://git@github.com:22/kosli-dev/cli.git",
+				SourceCommit:  commit,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ProcessProvenanceFile(tt.file, tt.fingerprint)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, data)
+		})
+	}
+}
+
+func TestProcessProvenanceFileSelectsMatchingStatement(t *testing.T) {
+	data, err := ProcessProvenanceFile("testdata/multi.intoto.jsonl", fingerprint)
+	require.NoError(t, err)
+	assert.Equal(t, SubjectMatch, data.SubjectCheck)
+	assert.Equal(t, "app.tgz", data.Subjects[0].Name)
+
+	data, err = ProcessProvenanceFile("testdata/multi.intoto.jsonl", "")
+	require.NoError(t, err)
+	assert.Equal(t, "other.tgz", data.Subjects[0].Name)
+}
+
+func TestCheckPolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         Policy
+		wantViolations int
+	}{
+		{
+			name: "an empty policy has no violations",
+		},
+		{
+			name: "the builder can be expected without its ref",
+			policy: Policy{
+				ExpectedBuilder:    "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml",
+				ExpectedSourceRepo: "git@github.com:kosli-dev/cli.git",
+			},
+		},
+		{
+			name: "the builder can be expected with its ref",
+			policy: Policy{
+				ExpectedBuilder:    "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v1.9.0",
+				ExpectedSourceRepo: "https://github.com/Kosli-Dev/cli",
+			},
+		},
+		{
+			name: "a different builder ref and source repo are violations",
+			policy: Policy{
+				ExpectedBuilder:    "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml@refs/tags/v2.0.0",
+				ExpectedSourceRepo: "https://github.com/kosli-dev/server",
+			},
+			wantViolations: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ProcessProvenanceFile("testdata/slsa-v02-dsse.json", fingerprint)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantViolations == 0, data.CheckPolicy(tt.policy))
+			assert.Len(t, data.PolicyViolations, tt.wantViolations)
+		})
+	}
+}
+
+func TestNormalizeRepo(t *testing.T) {
+	for _, repo := range []string{
+		"https://github.com/kosli-dev/cli",
+		"https://github.com/kosli-dev/cli.git",
+		"git+https://github.com/kosli-dev/cli@refs/heads/main",
+		"ssh://git@github.com:22/kosli-dev/cli.git",
+		"github.com/kosli-dev/cli/",
+	} {
+		assert.Equal(t, "github.com/kosli-dev/cli", NormalizeRepo(repo), repo)
+	}
+}
@@ -3,6 +3,7 @@ package requests
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -31,6 +32,22 @@ type HTTPResponse struct {
 	Resp *http.Response
 }
 
+// HTTPError is the error returned for a response with a status other than 200 or 201
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+// IsNotFound is true when err is an HTTPError with status 404
+func IsNotFound(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound
+}
+
 type Client struct {
 	MaxAPIRetries int
 	Debug         bool
@@ -252,7 +269,7 @@ func (c *Client) Do(p *RequestParams) (*HTTPResponse, error) {
 			var respBody interface{}
 			err := json.Unmarshal([]byte(body), &respBody)
 			if err != nil {
-				return &HTTPResponse{}, err
+				return &HTTPResponse{}, &HTTPError{StatusCode: resp.StatusCode, Message: err.Error()}
 			}
 			cleanedErrorMessage := ""
 			if reflect.ValueOf(respBody).Kind() == reflect.String {
@@ -274,7 +291,7 @@ func (c *Client) Do(p *RequestParams) (*HTTPResponse, error) {
 					cleanedErrorMessage = fmt.Sprintf("%s", respBodyMap)
 				}
 			}
-			return nil, fmt.Errorf("%s", cleanedErrorMessage)
+			return nil, &HTTPError{StatusCode: resp.StatusCode, Message: cleanedErrorMessage}
 		}
 		return &HTTPResponse{string(body), resp}, nil
 	}
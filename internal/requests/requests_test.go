@@ -386,6 +386,20 @@ func (suite *RequestsTestSuite) TestDo() {
 	}
 }
 
+func (suite *RequestsTestSuite) TestIsNotFound() {
+	client, err := NewKosliClient("", 0, false, logger.NewStandardLogger())
+	require.NoError(suite.Suite.T(), err)
+	for path, want := range map[string]bool{
+		"/no-go/":  true,
+		"/denied/": false,
+	} {
+		_, err := client.Do(&RequestParams{Method: http.MethodGet, URL: suite.fakeService.ResolveURL(path)})
+		require.Error(suite.Suite.T(), err)
+		require.Equal(suite.Suite.T(), want, IsNotFound(err), path)
+	}
+	require.False(suite.Suite.T(), IsNotFound(fmt.Errorf("resource not found")))
+}
+
 func (suite *RequestsTestSuite) TestCreateMultipartRequestBody() {
 	for _, t := range []struct {
 		name                      string
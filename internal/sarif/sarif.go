@@ -0,0 +1,366 @@
+package sarif
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/baseline"
+	gosarif "github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// Severity is the normalized severity of a SARIF finding
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityHigh     Severity = "high"
+	SeverityMedium   Severity = "medium"
+	SeverityLow      Severity = "low"
+	SeverityInfo     Severity = "info"
+	// SeverityNone is only valid as a threshold and means no finding fails the attestation
+	SeverityNone Severity = "none"
+)
+
+// AllowedThresholds are the values accepted by ParseThreshold
+var AllowedThresholds = []string{"critical", "high", "medium", "low", "info", "none"}
+
+var severityRank = map[Severity]int{
+	SeverityNone:     0,
+	SeverityInfo:     1,
+	SeverityLow:      2,
+	SeverityMedium:   3,
+	SeverityHigh:     4,
+	SeverityCritical: 5,
+}
+
+// AtLeast returns true if s is as severe as, or more severe than, threshold.
+// Nothing is at least SeverityNone.
+func (s Severity) AtLeast(threshold Severity) bool {
+	if threshold == SeverityNone {
+		return false
+	}
+	return severityRank[s] >= severityRank[threshold]
+}
+
+// ParseThreshold validates a --fail-on style threshold value
+func ParseThreshold(value string) (Severity, error) {
+	s := Severity(strings.ToLower(value))
+	if _, ok := severityRank[s]; !ok {
+		return "", fmt.Errorf("invalid severity threshold: %s. Allowed values are %v", value, AllowedThresholds)
+	}
+	return s, nil
+}
+
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type Location struct {
+	URI   string `json:"uri"`
+	Lines string `json:"lines,omitempty"`
+}
+
+type Finding struct {
+	RuleID           string     `json:"rule_id"`
+	Message          string     `json:"message"`
+	Severity         Severity   `json:"severity"`
+	Level            string     `json:"level,omitempty"`
+	SecuritySeverity *float64   `json:"security_severity,omitempty"`
+	Locations        []Location `json:"locations,omitempty"`
+}
+
+type SeverityCounts struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Info     int `json:"info"`
+}
+
+func (c *SeverityCounts) add(s Severity) {
+	switch s {
+	case SeverityCritical:
+		c.Critical++
+	case SeverityHigh:
+		c.High++
+	case SeverityMedium:
+		c.Medium++
+	case SeverityLow:
+		c.Low++
+	default:
+		c.Info++
+	}
+}
+
+type RunResult struct {
+	File     string         `json:"file"`
+	Tool     Tool           `json:"tool"`
+	Counts   SeverityCounts `json:"counts"`
+	Findings []Finding      `json:"findings"`
+}
+
+// AcceptedFinding is a finding matched by an entry in a baseline file
+type AcceptedFinding struct {
+	Finding
+	Tool       string         `json:"tool"`
+	Acceptance baseline.Entry `json:"acceptance"`
+}
+
+type SarifData struct {
+	SchemaVersion      int               `json:"schema_version"`
+	FailOn             Severity          `json:"fail_on"`
+	Counts             SeverityCounts    `json:"counts"`
+	FailingCount       int               `json:"failing_count"`
+	Runs               []RunResult       `json:"runs"`
+	Accepted           []AcceptedFinding `json:"accepted,omitempty"`
+	ExpiredAcceptances []AcceptedFinding `json:"expired_acceptances,omitempty"`
+}
+
+// ProcessSarifFiles parses one or more SARIF files, each of which can contain
+// multiple runs from different tools, and summarizes their findings by severity
+func ProcessSarifFiles(files []string) (*SarifData, error) {
+	data := &SarifData{
+		SchemaVersion: 1,
+		Runs:          []RunResult{},
+	}
+	for _, file := range files {
+		report, err := gosarif.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sarif file [%s]: %s", file, err)
+		}
+		if report.Version == "" {
+			return nil, fmt.Errorf("failed to parse sarif file [%s]: not a valid sarif file", file)
+		}
+		for _, run := range report.Runs {
+			if run == nil {
+				// a null run has no results
+				continue
+			}
+			result := processRun(run)
+			result.File = file
+			data.Runs = append(data.Runs, result)
+		}
+	}
+	data.recount()
+	return data, nil
+}
+
+// ApplyBaseline moves the findings accepted by a baseline file to the Accepted section.
+// Findings matched only by expired entries are kept and recorded in ExpiredAcceptances.
+func (d *SarifData) ApplyBaseline(b *baseline.Baseline, now time.Time) {
+	for i := range d.Runs {
+		run := &d.Runs[i]
+		remaining := []Finding{}
+		for _, f := range run.Findings {
+			uris := []string{}
+			for _, l := range f.Locations {
+				uris = append(uris, l.URI)
+			}
+			entry, expired := b.Match(f.RuleID, f.Message, uris, now)
+			if entry == nil {
+				remaining = append(remaining, f)
+				continue
+			}
+			accepted := AcceptedFinding{Finding: f, Tool: run.Tool.Name, Acceptance: *entry}
+			if expired {
+				d.ExpiredAcceptances = append(d.ExpiredAcceptances, accepted)
+				remaining = append(remaining, f)
+			} else {
+				d.Accepted = append(d.Accepted, accepted)
+			}
+		}
+		run.Findings = remaining
+	}
+	d.recount()
+}
+
+// Evaluate records the threshold and returns true if no finding
+// has a severity at or above it, and no finding has an expired acceptance
+func (d *SarifData) Evaluate(threshold Severity) bool {
+	d.FailOn = threshold
+	d.recount()
+	return d.FailingCount == 0 && len(d.ExpiredAcceptances) == 0
+}
+
+func (d *SarifData) recount() {
+	d.Counts = SeverityCounts{}
+	d.FailingCount = 0
+	for i := range d.Runs {
+		run := &d.Runs[i]
+		run.Counts = SeverityCounts{}
+		for _, f := range run.Findings {
+			run.Counts.add(f.Severity)
+			d.Counts.add(f.Severity)
+			if d.FailOn != "" && f.Severity.AtLeast(d.FailOn) {
+				d.FailingCount++
+			}
+		}
+	}
+}
+
+func processRun(run *gosarif.Run) RunResult {
+	result := RunResult{Findings: []Finding{}}
+	if run.Tool.Driver != nil {
+		result.Tool.Name = run.Tool.Driver.Name
+		if run.Tool.Driver.Version != nil {
+			result.Tool.Version = *run.Tool.Driver.Version
+		} else if run.Tool.Driver.SemanticVersion != nil {
+			result.Tool.Version = *run.Tool.Driver.SemanticVersion
+		}
+	}
+	for _, r := range run.Results {
+		if r == nil {
+			continue
+		}
+		if r.Kind != nil && *r.Kind != "" && *r.Kind != "fail" {
+			// pass, open, informational, notApplicable and review results are not findings
+			continue
+		}
+		rule := findRule(run, r)
+		finding := Finding{
+			Locations: findingLocations(r),
+		}
+		if r.RuleID != nil {
+			finding.RuleID = *r.RuleID
+		} else if rule != nil {
+			finding.RuleID = rule.ID
+		}
+		if r.Message.Text != nil {
+			finding.Message = *r.Message.Text
+		} else if r.Message.Markdown != nil {
+			finding.Message = *r.Message.Markdown
+		}
+		finding.Level, finding.SecuritySeverity, finding.Severity = severityOf(r, rule)
+		result.Findings = append(result.Findings, finding)
+	}
+	return result
+}
+
+// severityOf maps a result to a severity. A numeric security-severity property
+// (on the result or its rule) takes precedence, using the CVSS v3 bands.
+// Otherwise, the result level, then the rule's default level is used.
+// When no level is given, SARIF defines it as "warning".
+func severityOf(r *gosarif.Result, rule *gosarif.ReportingDescriptor) (string, *float64, Severity) {
+	level := ""
+	if r.Level != nil {
+		level = *r.Level
+	} else if rule != nil && rule.DefaultConfiguration != nil && rule.DefaultConfiguration.Level != "" {
+		level = rule.DefaultConfiguration.Level
+	}
+
+	score, ok := securitySeverity(r.Properties)
+	if !ok && rule != nil {
+		score, ok = securitySeverity(rule.Properties)
+	}
+	if ok {
+		return level, &score, scoreSeverity(score)
+	}
+
+	switch strings.ToLower(level) {
+	case "error":
+		return level, nil, SeverityHigh
+	case "note":
+		return level, nil, SeverityLow
+	case "none":
+		return level, nil, SeverityInfo
+	default:
+		if level == "" {
+			level = "warning"
+		}
+		return level, nil, SeverityMedium
+	}
+}
+
+func scoreSeverity(score float64) Severity {
+	switch {
+	case score >= 9.0:
+		return SeverityCritical
+	case score >= 7.0:
+		return SeverityHigh
+	case score >= 4.0:
+		return SeverityMedium
+	case score >= 0.1:
+		return SeverityLow
+	default:
+		return SeverityInfo
+	}
+}
+
+func securitySeverity(properties gosarif.Properties) (float64, bool) {
+	value, exists := properties["security-severity"]
+	if !exists || value == nil {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		score, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		return score, true
+	}
+	return 0, false
+}
+
+// findRule looks up the rule of a result, by index or ID, in the tool driver
+func findRule(run *gosarif.Run, r *gosarif.Result) *gosarif.ReportingDescriptor {
+	if run.Tool.Driver == nil {
+		return nil
+	}
+	rules := run.Tool.Driver.Rules
+	if r.RuleIndex != nil && int(*r.RuleIndex) < len(rules) {
+		return rules[*r.RuleIndex]
+	}
+
+	id := ""
+	if r.RuleID != nil {
+		id = *r.RuleID
+	} else if r.Rule != nil && r.Rule.Id != nil {
+		id = *r.Rule.Id
+	}
+	if id == "" {
+		return nil
+	}
+	// hierarchical rule IDs (e.g. "CA2000/1") fall back to their base rule
+	base, _, _ := strings.Cut(id, "/")
+	var baseRule *gosarif.ReportingDescriptor
+	for _, rule := range rules {
+		if rule == nil {
+			continue
+		}
+		if rule.ID == id {
+			return rule
+		}
+		if rule.ID == base {
+			baseRule = rule
+		}
+	}
+	return baseRule
+}
+
+func findingLocations(r *gosarif.Result) []Location {
+	locations := []Location{}
+	for _, l := range r.Locations {
+		if l == nil || l.PhysicalLocation == nil {
+			continue
+		}
+		lines := ""
+		if l.PhysicalLocation.Region != nil && l.PhysicalLocation.Region.StartLine != nil {
+			lines = strconv.Itoa(*l.PhysicalLocation.Region.StartLine)
+			if l.PhysicalLocation.Region.EndLine != nil && *l.PhysicalLocation.Region.EndLine != *l.PhysicalLocation.Region.StartLine {
+				lines += fmt.Sprintf("-%d", *l.PhysicalLocation.Region.EndLine)
+			}
+		}
+		uri := ""
+		if l.PhysicalLocation.ArtifactLocation != nil && l.PhysicalLocation.ArtifactLocation.URI != nil {
+			uri = *l.PhysicalLocation.ArtifactLocation.URI
+		}
+		locations = append(locations, Location{URI: uri, Lines: lines})
+	}
+	return locations
+}
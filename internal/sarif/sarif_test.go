@@ -0,0 +1,179 @@
+package sarif
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/baseline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessSarifFiles(t *testing.T) {
+	type run struct {
+		tool   Tool
+		counts SeverityCounts
+	}
+	tests := []struct {
+		name       string
+		files      []string
+		wantRuns   []run
+		wantCounts SeverityCounts
+		wantErr    bool
+	}{
+		{
+			name:    "a non-sarif json file causes an error",
+			files:   []string{"testdata/not-sarif.json"},
+			wantErr: true,
+		},
+		{
+			name:    "non-existing file causes an error",
+			files:   []string{"testdata/non-existing.sarif"},
+			wantErr: true,
+		},
+		{
+			name:       "a file with an empty run has no findings",
+			files:      []string{"testdata/empty.sarif"},
+			wantRuns:   []run{{tool: Tool{Name: "gosec", Version: "2.18.2"}}},
+			wantCounts: SeverityCounts{},
+		},
+		{
+			name:       "null runs are skipped",
+			files:      []string{"testdata/null-run.sarif"},
+			wantRuns:   []run{{tool: Tool{Name: "gosec", Version: "2.18.2"}}},
+			wantCounts: SeverityCounts{},
+		},
+		{
+			name:  "multiple runs and tools in one file are summarized separately",
+			files: []string{"testdata/multi-run.sarif"},
+			wantRuns: []run{
+				{tool: Tool{Name: "CodeQL", Version: "2.15.0"}, counts: SeverityCounts{Critical: 1, High: 1, Low: 1}},
+				{tool: Tool{Name: "Semgrep OSS", Version: "1.50.0"}, counts: SeverityCounts{High: 1, Medium: 1, Low: 1, Info: 1}},
+			},
+			wantCounts: SeverityCounts{Critical: 1, High: 2, Medium: 1, Low: 2, Info: 1},
+		},
+		{
+			name:  "multiple files are combined",
+			files: []string{"testdata/multi-run.sarif", "testdata/trivy.sarif"},
+			wantRuns: []run{
+				{tool: Tool{Name: "CodeQL", Version: "2.15.0"}, counts: SeverityCounts{Critical: 1, High: 1, Low: 1}},
+				{tool: Tool{Name: "Semgrep OSS", Version: "1.50.0"}, counts: SeverityCounts{High: 1, Medium: 1, Low: 1, Info: 1}},
+				{tool: Tool{Name: "Trivy", Version: "0.48.0"}, counts: SeverityCounts{Medium: 1}},
+			},
+			wantCounts: SeverityCounts{Critical: 1, High: 2, Medium: 2, Low: 2, Info: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ProcessSarifFiles(tt.files)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, data.Runs, len(tt.wantRuns))
+			for i, want := range tt.wantRuns {
+				assert.Equal(t, want.tool, data.Runs[i].Tool)
+				assert.Equal(t, want.counts, data.Runs[i].Counts)
+			}
+			assert.Equal(t, tt.wantCounts, data.Counts)
+		})
+	}
+}
+
+func TestFindingDetails(t *testing.T) {
+	data, err := ProcessSarifFiles([]string{"testdata/multi-run.sarif"})
+	require.NoError(t, err)
+
+	sqlInjection := data.Runs[0].Findings[0]
+	assert.Equal(t, "go/sql-injection", sqlInjection.RuleID)
+	assert.Equal(t, SeverityHigh, sqlInjection.Severity)
+	assert.Equal(t, "error", sqlInjection.Level)
+	require.NotNil(t, sqlInjection.SecuritySeverity)
+	assert.Equal(t, 8.8, *sqlInjection.SecuritySeverity)
+	assert.Equal(t, []Location{{URI: "internal/db/query.go", Lines: "12-14"}}, sqlInjection.Locations)
+
+	debugEnabled := data.Runs[1].Findings[0]
+	assert.Equal(t, "warning", debugEnabled.Level)
+	assert.Nil(t, debugEnabled.SecuritySeverity)
+	assert.Equal(t, SeverityMedium, debugEnabled.Severity)
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		threshold     Severity
+		wantCompliant bool
+		wantFailing   int
+	}{
+		{threshold: SeverityCritical, wantCompliant: false, wantFailing: 1},
+		{threshold: SeverityHigh, wantCompliant: false, wantFailing: 3},
+		{threshold: SeverityMedium, wantCompliant: false, wantFailing: 5},
+		{threshold: SeverityInfo, wantCompliant: false, wantFailing: 8},
+		{threshold: SeverityNone, wantCompliant: true, wantFailing: 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.threshold), func(t *testing.T) {
+			data, err := ProcessSarifFiles([]string{"testdata/multi-run.sarif", "testdata/trivy.sarif"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCompliant, data.Evaluate(tt.threshold))
+			assert.Equal(t, tt.wantFailing, data.FailingCount)
+			assert.Equal(t, tt.threshold, data.FailOn)
+		})
+	}
+
+	data, err := ProcessSarifFiles([]string{"testdata/empty.sarif"})
+	require.NoError(t, err)
+	assert.True(t, data.Evaluate(SeverityInfo))
+}
+
+func TestScoreSeverity(t *testing.T) {
+	for score, want := range map[float64]Severity{
+		10.0: SeverityCritical,
+		9.0:  SeverityCritical,
+		8.9:  SeverityHigh,
+		7.0:  SeverityHigh,
+		6.9:  SeverityMedium,
+		4.0:  SeverityMedium,
+		3.9:  SeverityLow,
+		0.1:  SeverityLow,
+		0.0:  SeverityInfo,
+	} {
+		assert.Equal(t, want, scoreSeverity(score), "score %v", score)
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	s, err := ParseThreshold("HIGH")
+	require.NoError(t, err)
+	assert.Equal(t, SeverityHigh, s)
+
+	_, err = ParseThreshold("severe")
+	require.Error(t, err)
+}
+
+func TestApplyBaseline(t *testing.T) {
+	b, err := baseline.Load("testdata/baseline.yml")
+	require.NoError(t, err)
+
+	data, err := ProcessSarifFiles([]string{"testdata/multi-run.sarif", "testdata/trivy.sarif"})
+	require.NoError(t, err)
+	data.ApplyBaseline(b, time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC))
+	require.Len(t, data.Accepted, 2)
+	assert.Equal(t, "go/sql-injection", data.Accepted[0].RuleID)
+	assert.Equal(t, "CodeQL", data.Accepted[0].Tool)
+	assert.Equal(t, "joe@example.com", data.Accepted[0].Acceptance.Approver)
+	assert.Equal(t, "CVE-2023-0001", data.Accepted[1].RuleID)
+	assert.Empty(t, data.ExpiredAcceptances)
+	assert.Equal(t, SeverityCounts{Critical: 1, High: 1, Medium: 1, Low: 2, Info: 1}, data.Counts)
+	assert.False(t, data.Evaluate(SeverityCritical))
+	assert.True(t, data.Evaluate(SeverityNone))
+
+	data, err = ProcessSarifFiles([]string{"testdata/multi-run.sarif", "testdata/trivy.sarif"})
+	require.NoError(t, err)
+	data.ApplyBaseline(b, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+	require.Len(t, data.Accepted, 1)
+	require.Len(t, data.ExpiredAcceptances, 1)
+	assert.Equal(t, "go/sql-injection", data.ExpiredAcceptances[0].RuleID)
+	assert.Equal(t, SeverityCounts{Critical: 1, High: 2, Medium: 1, Low: 2, Info: 1}, data.Counts)
+	assert.False(t, data.Evaluate(SeverityNone), "an expired acceptance makes the attestation non-compliant")
+}
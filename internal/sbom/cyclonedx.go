@@ -0,0 +1,107 @@
+package sbom
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxLicenseChoice struct {
+	License *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"license"`
+	Expression string `json:"expression"`
+}
+
+type cdxComponent struct {
+	Type       string             `json:"type"`
+	Name       string             `json:"name"`
+	Group      string             `json:"group"`
+	Version    string             `json:"version"`
+	PURL       string             `json:"purl"`
+	Hashes     []cdxHash          `json:"hashes"`
+	Licenses   []cdxLicenseChoice `json:"licenses"`
+	Components []cdxComponent     `json:"components"`
+}
+
+type cdxDocument struct {
+	SpecVersion string `json:"specVersion"`
+	Metadata    struct {
+		Component *cdxComponent `json:"component"`
+	} `json:"metadata"`
+	Components []cdxComponent `json:"components"`
+}
+
+func parseCycloneDX(content []byte) (*SbomData, error) {
+	var doc cdxDocument
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	data := &SbomData{
+		Format:      FormatCycloneDX,
+		SpecVersion: doc.SpecVersion,
+		Components:  []Component{},
+	}
+	if subject := doc.Metadata.Component; subject != nil {
+		data.Subject = Subject{
+			Name:    subject.Name,
+			Version: subject.Version,
+			Digests: digestsIn(subject.Version, subject.PURL),
+		}
+		for _, h := range subject.Hashes {
+			if strings.EqualFold(h.Alg, "SHA-256") {
+				data.Subject.Digests = append(data.Subject.Digests, strings.ToLower(h.Content))
+			}
+		}
+	}
+	data.addCycloneDXComponents(doc.Components)
+	return data, nil
+}
+
+// addCycloneDXComponents adds components and their nested components
+func (d *SbomData) addCycloneDXComponents(components []cdxComponent) {
+	for _, c := range components {
+		name := c.Name
+		if c.Group != "" {
+			name = c.Group + "/" + c.Name
+		}
+		d.Components = append(d.Components, Component{
+			Name:    name,
+			Version: c.Version,
+			PURL:    c.PURL,
+			License: cycloneDXLicense(c.Licenses),
+		})
+		d.addCycloneDXComponents(c.Components)
+	}
+}
+
+// cycloneDXLicense combines the license choices of a component into one expression.
+// Several choices are all considered to apply.
+func cycloneDXLicense(choices []cdxLicenseChoice) string {
+	licenses := []string{}
+	for _, choice := range choices {
+		license := ""
+		switch {
+		case choice.Expression != "":
+			license = choice.Expression
+		case choice.License != nil && choice.License.ID != "":
+			license = choice.License.ID
+		case choice.License != nil && choice.License.Name != "":
+			// names are free text, turn them into a single token
+			license = "LicenseRef-" + strings.Join(strings.Fields(choice.License.Name), "-")
+		}
+		if license == "" {
+			continue
+		}
+		if len(choices) > 1 && strings.ContainsAny(license, " ") {
+			license = "(" + license + ")"
+		}
+		licenses = append(licenses, license)
+	}
+	return strings.Join(licenses, " AND ")
+}
@@ -0,0 +1,135 @@
+package sbom
+
+import (
+	"fmt"
+	"strings"
+)
+
+// licenseExpr is a parsed SPDX license expression
+type licenseExpr struct {
+	id       string // set for a single license
+	operator string // "AND" or "OR" for compound expressions
+	operands []*licenseExpr
+}
+
+// parseLicenseExpression parses an SPDX license expression such as
+// "(MIT OR Apache-2.0) AND BSD-3-Clause". License exceptions ("WITH ...")
+// are kept as part of the license they apply to.
+func parseLicenseExpression(expression string) (*licenseExpr, error) {
+	p := &licenseParser{tokens: tokenizeLicense(expression)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s' in license expression: %s", p.tokens[p.pos], expression)
+	}
+	return expr, nil
+}
+
+func tokenizeLicense(expression string) []string {
+	expression = strings.ReplaceAll(expression, "(", " ( ")
+	expression = strings.ReplaceAll(expression, ")", " ) ")
+	return strings.Fields(expression)
+}
+
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *licenseParser) parseOr() (*licenseExpr, error) {
+	return p.parseBinary("OR", p.parseAnd)
+}
+
+func (p *licenseParser) parseAnd() (*licenseExpr, error) {
+	return p.parseBinary("AND", p.parseAtom)
+}
+
+func (p *licenseParser) parseBinary(operator string, operand func() (*licenseExpr, error)) (*licenseExpr, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	expr := &licenseExpr{operator: operator, operands: []*licenseExpr{first}}
+	for strings.EqualFold(p.peek(), operator) {
+		p.pos++
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		expr.operands = append(expr.operands, next)
+	}
+	if len(expr.operands) == 1 {
+		return first, nil
+	}
+	return expr, nil
+}
+
+func (p *licenseParser) parseAtom() (*licenseExpr, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of license expression")
+	case token == "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ')' in license expression")
+		}
+		p.pos++
+		return expr, nil
+	case token == ")" || strings.EqualFold(token, "AND") || strings.EqualFold(token, "OR") || strings.EqualFold(token, "WITH"):
+		return nil, fmt.Errorf("unexpected '%s' in license expression", token)
+	}
+	p.pos++
+	id := token
+	if strings.EqualFold(p.peek(), "WITH") && p.pos+1 < len(p.tokens) {
+		id += " WITH " + p.tokens[p.pos+1]
+		p.pos += 2
+	}
+	return &licenseExpr{id: id}, nil
+}
+
+// ids returns the licenses referenced in the expression
+func (e *licenseExpr) ids() []string {
+	if e.id != "" {
+		return []string{e.id}
+	}
+	ids := []string{}
+	for _, operand := range e.operands {
+		ids = append(ids, operand.ids()...)
+	}
+	return ids
+}
+
+// satisfiedBy returns true if the expression can be satisfied using only
+// licenses for which allowed returns true
+func (e *licenseExpr) satisfiedBy(allowed func(string) bool) bool {
+	if e.id != "" {
+		return allowed(e.id)
+	}
+	for _, operand := range e.operands {
+		ok := operand.satisfiedBy(allowed)
+		if e.operator == "OR" && ok {
+			return true
+		}
+		if e.operator == "AND" && !ok {
+			return false
+		}
+	}
+	return e.operator == "AND"
+}
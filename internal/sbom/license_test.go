@@ -0,0 +1,41 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	mitOnly := func(id string) bool { return id == "MIT" }
+	tests := []struct {
+		expression    string
+		wantIDs       []string
+		wantSatisfied bool
+		wantErr       bool
+	}{
+		{expression: "MIT", wantIDs: []string{"MIT"}, wantSatisfied: true},
+		{expression: "MIT OR Apache-2.0", wantIDs: []string{"MIT", "Apache-2.0"}, wantSatisfied: true},
+		{expression: "MIT AND Apache-2.0", wantIDs: []string{"MIT", "Apache-2.0"}, wantSatisfied: false},
+		{expression: "(MIT OR Apache-2.0) AND MIT", wantIDs: []string{"MIT", "Apache-2.0", "MIT"}, wantSatisfied: true},
+		{expression: "MIT AND Apache-2.0 OR MIT", wantIDs: []string{"MIT", "Apache-2.0", "MIT"}, wantSatisfied: true},
+		{expression: "GPL-2.0-only WITH Classpath-exception-2.0", wantIDs: []string{"GPL-2.0-only WITH Classpath-exception-2.0"}, wantSatisfied: false},
+		{expression: "", wantErr: true},
+		{expression: "(MIT", wantErr: true},
+		{expression: "MIT OR", wantErr: true},
+		{expression: "MIT Apache-2.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			expr, err := parseLicenseExpression(tt.expression)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIDs, expr.ids())
+			assert.Equal(t, tt.wantSatisfied, expr.satisfiedBy(mitOnly))
+		})
+	}
+}
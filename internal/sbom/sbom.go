@@ -0,0 +1,243 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatCycloneDX = "cyclonedx"
+	FormatSPDX      = "spdx"
+
+	// DigestMatch and the following constants are the results of checking the
+	// SBOM subject digest against the artifact fingerprint
+	DigestMatch    = "match"
+	DigestMismatch = "mismatch"
+	DigestNotFound = "not-found"
+	DigestSkipped  = "skipped"
+
+	unknownLicense   = "unknown"
+	unknownEcosystem = "unknown"
+)
+
+var sha256Pattern = regexp.MustCompile(`(?i)sha-?256(?::|%3A)([a-f0-9]{64})`)
+
+// Subject is what the SBOM describes, e.g. a container image
+type Subject struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version,omitempty"`
+	Digests []string `json:"digests,omitempty"`
+}
+
+// Component is a package listed in an SBOM
+type Component struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+	License string `json:"license,omitempty"`
+}
+
+type LicenseViolation struct {
+	Component string `json:"component"`
+	Version   string `json:"version,omitempty"`
+	License   string `json:"license"`
+}
+
+type SbomData struct {
+	SchemaVersion     int                `json:"schema_version"`
+	Format            string             `json:"format"`
+	SpecVersion       string             `json:"spec_version"`
+	Subject           Subject            `json:"subject"`
+	DigestCheck       string             `json:"digest_check"`
+	ComponentCount    int                `json:"component_count"`
+	Ecosystems        map[string]int     `json:"ecosystems"`
+	Licenses          map[string]int     `json:"licenses"`
+	LicenseViolations []LicenseViolation `json:"license_violations,omitempty"`
+	Components        []Component        `json:"-"`
+}
+
+// LicensePolicy decides which component licenses are compliant.
+// An empty Allowed list allows any license that is not denied.
+type LicensePolicy struct {
+	Allowed []string
+	Denied  []string
+}
+
+// ProcessSbomFile parses a CycloneDX (JSON) or SPDX (JSON or tag-value) SBOM file
+// and summarizes its content
+func ProcessSbomFile(file string) (*SbomData, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var data *SbomData
+	trimmed := bytes.TrimSpace(content)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var header struct {
+			BomFormat   string `json:"bomFormat"`
+			SpdxVersion string `json:"spdxVersion"`
+		}
+		if err := json.Unmarshal(trimmed, &header); err != nil {
+			return nil, fmt.Errorf("failed to parse SBOM file [%s]: %s", file, err)
+		}
+		switch {
+		case header.BomFormat == "CycloneDX":
+			data, err = parseCycloneDX(trimmed)
+		case header.SpdxVersion != "":
+			data, err = parseSPDXJSON(trimmed)
+		default:
+			return nil, fmt.Errorf("failed to parse SBOM file [%s]: not a CycloneDX or SPDX JSON document", file)
+		}
+	} else if bytes.HasPrefix(trimmed, []byte("SPDXVersion:")) {
+		data, err = parseSPDXTagValue(trimmed)
+	} else {
+		return nil, fmt.Errorf("failed to parse SBOM file [%s]: unsupported SBOM format. Supported formats are CycloneDX JSON, SPDX JSON and SPDX tag-value", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SBOM file [%s]: %s", file, err)
+	}
+
+	data.summarize()
+	return data, nil
+}
+
+func (d *SbomData) summarize() {
+	d.SchemaVersion = 1
+	d.DigestCheck = DigestSkipped
+	d.ComponentCount = len(d.Components)
+	d.Ecosystems = map[string]int{}
+	d.Licenses = map[string]int{}
+	for _, c := range d.Components {
+		d.Ecosystems[ecosystem(c.PURL)]++
+		expr, err := parseLicenseExpression(c.License)
+		if err != nil {
+			d.Licenses[unknownLicense]++
+			continue
+		}
+		for _, id := range uniqueStrings(expr.ids()) {
+			d.Licenses[id]++
+		}
+	}
+	d.Subject.Digests = uniqueStrings(d.Subject.Digests)
+}
+
+// CheckDigest compares the digests of the SBOM subject with an artifact fingerprint.
+// It returns false if a fingerprint is given and no subject digest matches it,
+// including when the SBOM has no subject digest.
+func (d *SbomData) CheckDigest(fingerprint string) bool {
+	switch {
+	case fingerprint == "":
+		d.DigestCheck = DigestSkipped
+	case len(d.Subject.Digests) == 0:
+		d.DigestCheck = DigestNotFound
+	default:
+		d.DigestCheck = DigestMismatch
+		for _, digest := range d.Subject.Digests {
+			if strings.EqualFold(digest, fingerprint) {
+				d.DigestCheck = DigestMatch
+			}
+		}
+	}
+	return d.DigestCheck == DigestMatch || d.DigestCheck == DigestSkipped
+}
+
+// CheckLicenses records the components whose license does not comply with the policy
+// and returns true if there are none. Components without a (valid) license only
+// comply when no allowed licenses are given.
+func (d *SbomData) CheckLicenses(policy LicensePolicy) bool {
+	d.LicenseViolations = nil
+	if len(policy.Allowed) == 0 && len(policy.Denied) == 0 {
+		return true
+	}
+	for _, c := range d.Components {
+		expr, err := parseLicenseExpression(c.License)
+		compliant := false
+		if err != nil {
+			compliant = len(policy.Allowed) == 0
+		} else {
+			compliant = expr.satisfiedBy(policy.allows)
+		}
+		if !compliant {
+			license := c.License
+			if license == "" {
+				license = unknownLicense
+			}
+			d.LicenseViolations = append(d.LicenseViolations, LicenseViolation{
+				Component: c.Name,
+				Version:   c.Version,
+				License:   license,
+			})
+		}
+	}
+	return len(d.LicenseViolations) == 0
+}
+
+func (p LicensePolicy) allows(license string) bool {
+	base, _, _ := strings.Cut(license, " WITH ")
+	if containsFold(p.Denied, license) || containsFold(p.Denied, base) {
+		return false
+	}
+	return len(p.Allowed) == 0 || containsFold(p.Allowed, license) || containsFold(p.Allowed, base)
+}
+
+// ecosystem returns the package URL type, e.g. "npm" for "pkg:npm/lodash@4.17.21"
+func ecosystem(purl string) string {
+	rest, found := strings.CutPrefix(purl, "pkg:")
+	if !found {
+		return unknownEcosystem
+	}
+	purlType, _, found := strings.Cut(strings.TrimLeft(rest, "/"), "/")
+	if !found || purlType == "" {
+		return unknownEcosystem
+	}
+	return strings.ToLower(purlType)
+}
+
+// digestsIn extracts sha256 digests from values such as "sha256:abc..." or
+// "pkg:oci/alpine@sha256%3Aabc..."
+func digestsIn(values ...string) []string {
+	digests := []string{}
+	for _, value := range values {
+		for _, match := range sha256Pattern.FindAllStringSubmatch(value, -1) {
+			digests = append(digests, strings.ToLower(match[1]))
+		}
+	}
+	return digests
+}
+
+// knownLicense returns an empty string for the SPDX placeholders for missing licenses
+func knownLicense(license string) string {
+	license = strings.TrimSpace(license)
+	if license == "NOASSERTION" || license == "NONE" {
+		return ""
+	}
+	return license
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,150 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const subjectDigest = "7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9"
+
+func TestProcessSbomFile(t *testing.T) {
+	spdxLicenses := map[string]int{"MIT": 2, "GPL-3.0-only": 1, "GPL-3.0-or-later": 1}
+	tests := []struct {
+		name           string
+		file           string
+		wantFormat     string
+		wantSpec       string
+		wantSubject    Subject
+		wantCount      int
+		wantEcosystems map[string]int
+		wantLicenses   map[string]int
+		wantErr        bool
+	}{
+		{
+			name:    "a json file which is not an SBOM causes an error",
+			file:    "testdata/not-sbom.json",
+			wantErr: true,
+		},
+		{
+			name:    "non-existing file causes an error",
+			file:    "testdata/non-existing.json",
+			wantErr: true,
+		},
+		{
+			name:        "a CycloneDX json file is parsed, including nested components",
+			file:        "testdata/cyclonedx.json",
+			wantFormat:  FormatCycloneDX,
+			wantSpec:    "1.5",
+			wantSubject: Subject{Name: "registry.example.com/app", Version: "sha256:" + subjectDigest, Digests: []string{subjectDigest}},
+			wantCount:   6,
+			wantEcosystems: map[string]int{
+				"npm": 2, "golang": 1, "apk": 2, "unknown": 1,
+			},
+			wantLicenses: map[string]int{
+				"MIT": 3, "GPL-3.0-only": 2, "BSD-3-Clause": 1, "LicenseRef-Example-Corp-Proprietary": 1, "unknown": 1,
+			},
+		},
+		{
+			name:           "an SPDX json file is parsed",
+			file:           "testdata/spdx.json",
+			wantFormat:     FormatSPDX,
+			wantSpec:       "SPDX-2.3",
+			wantSubject:    Subject{Name: "registry.example.com/app", Version: "1.0.0", Digests: []string{subjectDigest}},
+			wantCount:      3,
+			wantEcosystems: map[string]int{"npm": 1, "apk": 2},
+			wantLicenses:   spdxLicenses,
+		},
+		{
+			name:           "an SPDX tag-value file is parsed",
+			file:           "testdata/spdx.spdx",
+			wantFormat:     FormatSPDX,
+			wantSpec:       "SPDX-2.3",
+			wantSubject:    Subject{Name: "registry.example.com/app", Version: "1.0.0", Digests: []string{subjectDigest}},
+			wantCount:      3,
+			wantEcosystems: map[string]int{"npm": 1, "apk": 2},
+			wantLicenses:   spdxLicenses,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ProcessSbomFile(tt.file)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFormat, data.Format)
+			assert.Equal(t, tt.wantSpec, data.SpecVersion)
+			assert.Equal(t, tt.wantSubject, data.Subject)
+			assert.Equal(t, tt.wantCount, data.ComponentCount)
+			assert.Equal(t, tt.wantEcosystems, data.Ecosystems)
+			assert.Equal(t, tt.wantLicenses, data.Licenses)
+			assert.Equal(t, DigestSkipped, data.DigestCheck)
+		})
+	}
+}
+
+func TestCheckDigest(t *testing.T) {
+	data, err := ProcessSbomFile("testdata/cyclonedx.json")
+	require.NoError(t, err)
+
+	assert.True(t, data.CheckDigest(subjectDigest))
+	assert.Equal(t, DigestMatch, data.DigestCheck)
+
+	assert.False(t, data.CheckDigest("1234e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9"))
+	assert.Equal(t, DigestMismatch, data.DigestCheck)
+
+	assert.True(t, data.CheckDigest(""))
+	assert.Equal(t, DigestSkipped, data.DigestCheck)
+
+	data.Subject.Digests = []string{}
+	assert.False(t, data.CheckDigest(subjectDigest))
+	assert.Equal(t, DigestNotFound, data.DigestCheck)
+	assert.True(t, data.CheckDigest(""))
+}
+
+func TestCheckLicenses(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         LicensePolicy
+		wantViolations []LicenseViolation
+	}{
+		{
+			name:   "no policy has no violations",
+			policy: LicensePolicy{},
+		},
+		{
+			name:   "a denied license is only a violation when no alternative is allowed",
+			policy: LicensePolicy{Denied: []string{"gpl-3.0-only"}},
+			wantViolations: []LicenseViolation{
+				{Component: "musl-utils", Version: "1.2.4-r2", License: "MIT AND GPL-3.0-only"},
+			},
+		},
+		{
+			name:   "an allow list makes other and unknown licenses violations",
+			policy: LicensePolicy{Allowed: []string{"MIT", "BSD-3-Clause"}},
+			wantViolations: []LicenseViolation{
+				{Component: "musl-utils", Version: "1.2.4-r2", License: "MIT AND GPL-3.0-only"},
+				{Component: "internal-lib", Version: "1.0.0", License: "LicenseRef-Example-Corp-Proprietary"},
+				{Component: "no-license", Version: "0.1.0", License: "unknown"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ProcessSbomFile("testdata/cyclonedx.json")
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.wantViolations) == 0, data.CheckLicenses(tt.policy))
+			assert.Equal(t, tt.wantViolations, data.LicenseViolations)
+		})
+	}
+}
+
+func TestEcosystem(t *testing.T) {
+	assert.Equal(t, "maven", ecosystem("pkg:maven/org.apache/commons@1.0"))
+	assert.Equal(t, "oci", ecosystem("pkg:OCI/alpine@sha256%3Aabc"))
+	assert.Equal(t, "unknown", ecosystem("lodash"))
+	assert.Equal(t, "unknown", ecosystem("pkg:npm"))
+}
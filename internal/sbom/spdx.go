@@ -0,0 +1,208 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+type spdxPackage struct {
+	SPDXID           string `json:"SPDXID"`
+	Name             string `json:"name"`
+	VersionInfo      string `json:"versionInfo"`
+	LicenseConcluded string `json:"licenseConcluded"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+	Checksums        []struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	} `json:"checksums"`
+	ExternalRefs []struct {
+		ReferenceType    string `json:"referenceType"`
+		ReferenceLocator string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+// spdxPackageInfo is the format independent content of an SPDX package
+type spdxPackageInfo struct {
+	id        string
+	name      string
+	version   string
+	purl      string
+	license   string
+	checksums []string
+}
+
+func parseSPDXJSON(content []byte) (*SbomData, error) {
+	var doc spdxDocument
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	packages := []spdxPackageInfo{}
+	for _, p := range doc.Packages {
+		info := spdxPackageInfo{
+			id:      p.SPDXID,
+			name:    p.Name,
+			version: p.VersionInfo,
+			license: spdxLicense(p.LicenseConcluded, p.LicenseDeclared),
+		}
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" && info.purl == "" {
+				info.purl = ref.ReferenceLocator
+			}
+		}
+		for _, c := range p.Checksums {
+			if strings.EqualFold(c.Algorithm, "SHA256") {
+				info.checksums = append(info.checksums, strings.ToLower(c.ChecksumValue))
+			}
+		}
+		packages = append(packages, info)
+	}
+	described := doc.DocumentDescribes
+	for _, r := range doc.Relationships {
+		described = append(described, describedElement(r.Element, r.Type, r.Related)...)
+	}
+	return newSPDXData(doc.SPDXVersion, packages, described), nil
+}
+
+// parseSPDXTagValue parses the package information of an SPDX tag-value document.
+// Multi-line <text>...</text> values are skipped.
+func parseSPDXTagValue(content []byte) (*SbomData, error) {
+	version := ""
+	packages := []spdxPackageInfo{}
+	described := []string{}
+	var current *spdxPackageInfo
+	concluded, declared := "", ""
+	flush := func() {
+		if current != nil {
+			current.license = spdxLicense(concluded, declared)
+			packages = append(packages, *current)
+		}
+		current, concluded, declared = nil, "", ""
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+	inText := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if inText {
+			inText = !strings.Contains(line, "</text>")
+			continue
+		}
+		tag, value, found := strings.Cut(line, ":")
+		if !found || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "<text>") {
+			inText = !strings.Contains(value, "</text>")
+			continue
+		}
+		switch strings.TrimSpace(tag) {
+		case "SPDXVersion":
+			version = value
+		case "PackageName":
+			flush()
+			current = &spdxPackageInfo{name: value}
+		case "FileName", "SnippetSPDXID":
+			// the package section has ended
+			flush()
+		case "SPDXID":
+			if current != nil {
+				current.id = value
+			}
+		case "PackageVersion":
+			if current != nil {
+				current.version = value
+			}
+		case "PackageLicenseConcluded":
+			concluded = value
+		case "PackageLicenseDeclared":
+			declared = value
+		case "PackageChecksum":
+			algorithm, checksum, _ := strings.Cut(value, ":")
+			if current != nil && strings.EqualFold(strings.TrimSpace(algorithm), "SHA256") {
+				current.checksums = append(current.checksums, strings.ToLower(strings.TrimSpace(checksum)))
+			}
+		case "ExternalRef":
+			fields := strings.Fields(value)
+			if current != nil && len(fields) == 3 && fields[1] == "purl" && current.purl == "" {
+				current.purl = fields[2]
+			}
+		case "Relationship":
+			fields := strings.Fields(value)
+			if len(fields) == 3 {
+				described = append(described, describedElement(fields[0], fields[1], fields[2])...)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return newSPDXData(version, packages, described), nil
+}
+
+// describedElement returns the element described by the SPDX document, if the relationship says so
+func describedElement(element, relationship, related string) []string {
+	switch {
+	case relationship == "DESCRIBES" && element == "SPDXRef-DOCUMENT":
+		return []string{related}
+	case relationship == "DESCRIBED_BY" && related == "SPDXRef-DOCUMENT":
+		return []string{element}
+	}
+	return nil
+}
+
+// newSPDXData uses the first described package as the subject,
+// and all other packages as the components
+func newSPDXData(specVersion string, packages []spdxPackageInfo, described []string) *SbomData {
+	data := &SbomData{
+		Format:      FormatSPDX,
+		SpecVersion: specVersion,
+		Components:  []Component{},
+	}
+	subjectFound := false
+	for _, p := range packages {
+		if containsFold(described, p.id) && p.id != "" {
+			if !subjectFound {
+				subjectFound = true
+				data.Subject = Subject{
+					Name:    p.name,
+					Version: p.version,
+					Digests: append(digestsIn(p.version, p.purl), p.checksums...),
+				}
+			}
+			continue
+		}
+		data.Components = append(data.Components, Component{
+			Name:    p.name,
+			Version: p.version,
+			PURL:    p.purl,
+			License: p.license,
+		})
+	}
+	return data
+}
+
+// spdxLicense uses the concluded license, unless it is missing
+func spdxLicense(concluded, declared string) string {
+	if license := knownLicense(concluded); license != "" {
+		return license
+	}
+	return knownLicense(declared)
+}
@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// parseGitleaks reads a gitleaks JSON report, an array of findings.
+// Secret, Match and Line hold the secret value and are redacted.
+func parseGitleaks(content []byte) ([]Finding, []byte, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	findings := []Finding{}
+	records := []map[string]interface{}{}
+	for i, item := range raw {
+		record, err := decodeRecord(item)
+		if err != nil {
+			return nil, nil, fmt.Errorf("finding %d is not an object: %v", i, err)
+		}
+		rule := stringField(record, "RuleID")
+		if rule == "" {
+			return nil, nil, fmt.Errorf("finding %d has no RuleID", i)
+		}
+		finding := Finding{
+			RuleID:      rule,
+			File:        stringField(record, "File"),
+			Line:        intField(record, "StartLine"),
+			Commit:      stringField(record, "Commit"),
+			Fingerprint: stringField(record, "Fingerprint"),
+		}
+		if finding.Fingerprint == "" {
+			finding.Fingerprint = fingerprint(finding.Commit, finding.File, finding.RuleID, finding.Line)
+		}
+		findings = append(findings, finding)
+
+		// without a capture group, the whole match is the secret
+		if stringField(record, "Secret") == "" {
+			redact(record, "Match")
+		}
+		redact(record, "Secret")
+		records = append(records, record)
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", " ")
+	if err := encoder.Encode(records); err != nil {
+		return nil, nil, err
+	}
+	return findings, buffer.Bytes(), nil
+}
@@ -0,0 +1,217 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	FormatGitleaks   = "gitleaks"
+	FormatTrufflehog = "trufflehog"
+
+	// Redacted replaces secret values in reports
+	Redacted = "REDACTED"
+
+	// secret values shorter than this are only redacted from the fields holding them,
+	// so that short values do not mangle unrelated strings
+	minSubstringRedaction = 4
+)
+
+var AllowedFormats = []string{FormatGitleaks, FormatTrufflehog}
+
+// Finding is a secret found by a scanner, without the secret value
+type Finding struct {
+	RuleID      string `json:"rule_id"`
+	File        string `json:"file"`
+	Line        int    `json:"line,omitempty"`
+	Commit      string `json:"commit,omitempty"`
+	Fingerprint string `json:"fingerprint"`
+	Verified    *bool  `json:"verified,omitempty"`
+}
+
+type SecretsData struct {
+	SchemaVersion int            `json:"schema_version"`
+	Format        string         `json:"format"`
+	Total         int            `json:"total"`
+	Remaining     int            `json:"remaining"`
+	ByRule        map[string]int `json:"by_rule"`
+	ByFile        map[string]int `json:"by_file"`
+	Findings      []Finding      `json:"findings"`
+	Allowlisted   []Finding      `json:"allowlisted,omitempty"`
+	// UnusedAllowlist are allowlisted fingerprints which match no finding
+	UnusedAllowlist []string `json:"unused_allowlist,omitempty"`
+}
+
+// Compliant is true when no finding remains after the allowlist
+func (data *SecretsData) Compliant() bool {
+	return data.Remaining == 0
+}
+
+// ValidateFormat checks that format is one of AllowedFormats
+func ValidateFormat(format string) error {
+	for _, allowed := range AllowedFormats {
+		if format == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not a valid secrets report format. Allowed formats are: %s", format, strings.Join(AllowedFormats, ", "))
+}
+
+// LoadAllowlist reads allowlisted finding fingerprints, one per line, as in
+// a .gitleaksignore file. Empty lines and lines starting with # are ignored.
+func LoadAllowlist(file string) ([]string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fingerprints = append(fingerprints, line)
+	}
+	return fingerprints, scanner.Err()
+}
+
+// ProcessReport parses a gitleaks (JSON) or trufflehog (JSON lines) report,
+// counts the findings which are not allowlisted by rule and file, and returns
+// the report content with all secret values redacted
+func ProcessReport(file, format string, allowlist []string) (*SecretsData, []byte, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, nil, err
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var findings []Finding
+	var redacted []byte
+	switch format {
+	case FormatGitleaks:
+		findings, redacted, err = parseGitleaks(content)
+	case FormatTrufflehog:
+		findings, redacted, err = parseTrufflehog(content)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s report [%s]: %v", format, file, err)
+	}
+	return summarize(format, findings, allowlist), redacted, nil
+}
+
+func summarize(format string, findings []Finding, allowlist []string) *SecretsData {
+	allowed := map[string]bool{}
+	for _, fingerprint := range allowlist {
+		allowed[fingerprint] = false
+	}
+
+	data := &SecretsData{
+		SchemaVersion: 1,
+		Format:        format,
+		Total:         len(findings),
+		ByRule:        map[string]int{},
+		ByFile:        map[string]int{},
+		Findings:      []Finding{},
+	}
+	for _, finding := range findings {
+		if _, ok := allowed[finding.Fingerprint]; ok {
+			allowed[finding.Fingerprint] = true
+			data.Allowlisted = append(data.Allowlisted, finding)
+			continue
+		}
+		data.Findings = append(data.Findings, finding)
+		data.ByRule[finding.RuleID]++
+		data.ByFile[finding.File]++
+	}
+	data.Remaining = len(data.Findings)
+	for fingerprint, used := range allowed {
+		if !used {
+			data.UnusedAllowlist = append(data.UnusedAllowlist, fingerprint)
+		}
+	}
+	sort.Strings(data.UnusedAllowlist)
+	return data
+}
+
+// fingerprint has the same form as gitleaks fingerprints
+func fingerprint(commit, file, rule string, line int) string {
+	parts := []string{file, rule, fmt.Sprint(line)}
+	if commit != "" {
+		parts = append([]string{commit}, parts...)
+	}
+	return strings.Join(parts, ":")
+}
+
+// redact replaces the values of the given fields of a record, and any occurrence of
+// these values in the other strings of the record, e.g. in matched lines or commit messages
+func redact(record map[string]interface{}, fields ...string) {
+	values := []string{}
+	for _, field := range fields {
+		if value, ok := record[field].(string); ok && value != "" {
+			values = append(values, value)
+			record[field] = Redacted
+		}
+	}
+	// longer values first, in case a value contains another one
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for key, value := range record {
+		record[key] = redactValue(value, values)
+	}
+}
+
+func redactValue(value interface{}, secrets []string) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, secret := range secrets {
+			if len(secret) >= minSubstringRedaction {
+				v = strings.ReplaceAll(v, secret, Redacted)
+			}
+		}
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = redactValue(item, secrets)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, secrets)
+		}
+		return v
+	}
+	return value
+}
+
+func decodeRecord(content []byte) (map[string]interface{}, error) {
+	var record map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(&record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func stringField(record map[string]interface{}, field string) string {
+	value, _ := record[field].(string)
+	return value
+}
+
+func intField(record map[string]interface{}, field string) int {
+	switch value := record[field].(type) {
+	case json.Number:
+		n, _ := value.Int64()
+		return int(n)
+	case float64:
+		return int(value)
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// parseTrufflehog reads the output of 'trufflehog --json', one finding per line.
+// Raw and RawV2 hold the secret value and are redacted.
+// The location of a finding is read from its source metadata, whatever the source (git, filesystem, ...).
+func parseTrufflehog(content []byte) ([]Finding, []byte, error) {
+	findings := []Finding{}
+	var redacted bytes.Buffer
+	encoder := json.NewEncoder(&redacted)
+	encoder.SetEscapeHTML(false)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		record, err := decodeRecord(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d is not a JSON object: %v", lineNumber, err)
+		}
+		rule := stringField(record, "DetectorName")
+		if rule == "" {
+			return nil, nil, fmt.Errorf("line %d has no DetectorName", lineNumber)
+		}
+
+		finding := Finding{RuleID: rule}
+		if metadata, ok := record["SourceMetadata"].(map[string]interface{}); ok {
+			if data, ok := metadata["Data"].(map[string]interface{}); ok {
+				for _, source := range data {
+					if location, ok := source.(map[string]interface{}); ok {
+						finding.File = stringField(location, "file")
+						finding.Line = intField(location, "line")
+						finding.Commit = stringField(location, "commit")
+					}
+				}
+			}
+		}
+		if verified, ok := record["Verified"].(bool); ok {
+			finding.Verified = &verified
+		}
+		finding.Fingerprint = fingerprint(finding.Commit, finding.File, finding.RuleID, finding.Line)
+		findings = append(findings, finding)
+
+		redact(record, "Raw", "RawV2")
+		// Encode writes each record on its own line
+		if err := encoder.Encode(record); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return findings, redacted.Bytes(), nil
+}
@@ -24,20 +24,22 @@ type ServerData struct {
 
 // ArtifactPathSpec represents specification for how to fingerprint an artifact
 type ArtifactPathSpec struct {
-	Path    string   `mapstructure:"path" validate:"required"`
-	Exclude []string `mapstructure:"exclude"`
+	Path                         string   `mapstructure:"path" validate:"required"`
+	Exclude                      []string `mapstructure:"exclude"`
+	digest.DirFingerprintOptions `mapstructure:",squash"`
 }
 
 // PathsSpec represents specification for how to fingerprint a list of artifacts
 type PathsSpec struct {
 	Version   int                         `mapstructure:"version" validate:"required,oneof=1"`
-	Artifacts map[string]ArtifactPathSpec `mapstructure:"artifacts" validate:"required"`
+	Artifacts map[string]ArtifactPathSpec `mapstructure:"artifacts" validate:"required,dive"`
 }
 
 // CreateServerArtifactsData creates a list of ServerData for server artifacts at given paths
 // and excludePaths can contain Glob patterns
 // if paths have Glob patterns, each path matching the pattern will be treated as an artifact
-func CreateServerArtifactsData(paths, excludePaths []string, logger *logger.Logger) ([]*ServerData, error) {
+// dirOptions are applied when fingerprinting directory artifacts
+func CreateServerArtifactsData(paths, excludePaths []string, dirOptions digest.DirFingerprintOptions, logger *logger.Logger) ([]*ServerData, error) {
 	result := []*ServerData{}
 
 	pathsToInclude := []string{}
@@ -54,7 +56,7 @@ func CreateServerArtifactsData(paths, excludePaths []string, logger *logger.Logg
 	}
 
 	for _, p := range pathsToInclude {
-		data, err := getArtifactDataForPath(p, "", excludePaths, logger)
+		data, err := getArtifactDataForPath(p, "", excludePaths, dirOptions, logger)
 		if err != nil {
 			return result, err
 		}
@@ -66,7 +68,7 @@ func CreateServerArtifactsData(paths, excludePaths []string, logger *logger.Logg
 // getArtifactDataForPath calculates the artifact fingerprint for path (while excluding excludePaths)
 // and returns a ServerData object.
 // If artifactName is empty, it is defaulted to the absolute path of the artifact path
-func getArtifactDataForPath(path, artifactName string, excludePaths []string, logger *logger.Logger) (*ServerData, error) {
+func getArtifactDataForPath(path, artifactName string, excludePaths []string, dirOptions digest.DirFingerprintOptions, logger *logger.Logger) (*ServerData, error) {
 	data := &ServerData{}
 	digests := make(map[string]string)
 
@@ -89,7 +91,7 @@ func getArtifactDataForPath(path, artifactName string, excludePaths []string, lo
 		}
 		fingerprint, err = digest.FileSha256(path)
 	} else {
-		fingerprint, err = digest.DirSha256(path, excludePaths, logger)
+		fingerprint, err = digest.DirSha256(path, excludePaths, dirOptions, logger)
 	}
 
 	if err != nil {
@@ -121,7 +123,7 @@ func CreatePathsArtifactsData(ps *PathsSpec, logger *logger.Logger) ([]*ServerDa
 	result := []*ServerData{}
 	for artifactName, pathSpec := range ps.Artifacts {
 		logger.Debug("fingerprinting artifact [%s] with spec [ Include: %s, Exclude: %s]", artifactName, pathSpec.Path, pathSpec.Exclude)
-		data, err := getArtifactDataForPath(pathSpec.Path, artifactName, pathSpec.Exclude, logger)
+		data, err := getArtifactDataForPath(pathSpec.Path, artifactName, pathSpec.Exclude, pathSpec.DirFingerprintOptions, logger)
 		if err != nil {
 			return result, fmt.Errorf("failed to calculate fingerprint for artifact [%s]: %v", artifactName, err)
 		}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/kosli-dev/cli/internal/digest"
 	"github.com/kosli-dev/cli/internal/logger"
 	"github.com/kosli-dev/cli/internal/utils"
 	"github.com/stretchr/testify/assert"
@@ -171,7 +172,7 @@ func (suite *ServerTestSuite) TestCreateServerArtifactsData() {
 				t.paths[i] = filepath.Join(suite.tmpDir, path)
 			}
 
-			serverData, err := CreateServerArtifactsData(t.paths, t.excludePaths, logger.NewStandardLogger())
+			serverData, err := CreateServerArtifactsData(t.paths, t.excludePaths, digest.DirFingerprintOptions{}, logger.NewStandardLogger())
 			require.NoErrorf(suite.Suite.T(), err, "error creating server artifact data: %v", err)
 
 			digestsList := []map[string]string{}
@@ -252,7 +253,7 @@ func (suite *ServerTestSuite) TestCreateServerArtifactsDataWithFiles() {
 				suite.createFileWithContent(path, t.args.content)
 			}
 
-			serverData, err := CreateServerArtifactsData(paths, []string{}, logger.NewStandardLogger())
+			serverData, err := CreateServerArtifactsData(paths, []string{}, digest.DirFingerprintOptions{}, logger.NewStandardLogger())
 			if t.expectError {
 				require.Errorf(suite.Suite.T(), err, "was expecting error during creating server artifact data but got none")
 			} else {
@@ -284,7 +285,7 @@ func (suite *ServerTestSuite) TestCreateServerArtifactsDataInvalid() {
 
 	paths := []string{"a/b/c"}
 
-	_, err := CreateServerArtifactsData(paths, []string{}, logger.NewStandardLogger())
+	_, err := CreateServerArtifactsData(paths, []string{}, digest.DirFingerprintOptions{}, logger.NewStandardLogger())
 	require.Errorf(suite.Suite.T(), err, "error was expected")
 }
 
@@ -0,0 +1,348 @@
+package signature
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/dsse"
+)
+
+const (
+	TypeDetached = "detached"
+	TypeDSSE     = "dsse"
+
+	// PayloadMatch and the following constants are the results of checking
+	// that a DSSE payload refers to the artifact fingerprint
+	PayloadMatch    = "match"
+	PayloadMismatch = "mismatch"
+	PayloadSkipped  = "skipped"
+)
+
+// PublicKey is a trusted public key
+type PublicKey struct {
+	// ID is the hex encoded sha256 of the DER encoded public key
+	ID        string
+	Algorithm string
+	key       crypto.PublicKey
+}
+
+type VerificationData struct {
+	SchemaVersion int    `json:"schema_version"`
+	SignatureType string `json:"signature_type"`
+	Verified      bool   `json:"verified"`
+	KeyID         string `json:"key_id,omitempty"`
+	Algorithm     string `json:"algorithm,omitempty"`
+	PayloadType   string `json:"payload_type,omitempty"`
+	PayloadCheck  string `json:"payload_check,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// Compliant returns true if the signature is verified and,
+// for DSSE envelopes, the payload refers to the artifact
+func (d *VerificationData) Compliant() bool {
+	return d.Verified && d.PayloadCheck != PayloadMismatch
+}
+
+// LoadPublicKeys loads the public keys in a PEM file. The file can contain
+// several PUBLIC KEY and CERTIFICATE blocks (a trust bundle).
+// Certificates must be valid at the given time and either be self-signed or chain
+// to a self-signed certificate of the file.
+// Only ECDSA and ed25519 keys are supported.
+func LoadPublicKeys(file string, now time.Time) ([]PublicKey, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := parseCertificates(file, content)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyCertificates(file, certs, now); err != nil {
+		return nil, err
+	}
+
+	keys := []PublicKey{}
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		var key crypto.PublicKey
+		switch block.Type {
+		case "PUBLIC KEY":
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		case "CERTIFICATE":
+			var cert *x509.Certificate
+			cert, err = x509.ParseCertificate(block.Bytes)
+			if err == nil {
+				key = cert.PublicKey
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s in [%s]: %s", strings.ToLower(block.Type), file, err)
+		}
+		publicKey, err := newPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported key in [%s]: %s", file, err)
+		}
+		keys = append(keys, publicKey)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no PEM encoded public key or certificate found in [%s]", file)
+	}
+	return keys, nil
+}
+
+// parseCertificates returns the certificates of the CERTIFICATE blocks in PEM content
+func parseCertificates(file string, content []byte) ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			return certs, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate in [%s]: %s", file, err)
+		}
+		certs = append(certs, cert)
+	}
+}
+
+// verifyCertificates checks that the certificates are valid at the given time, and that the ones
+// which are not self-signed chain to a self-signed certificate among them
+func verifyCertificates(file string, certs []*x509.Certificate, now time.Time) error {
+	roots := x509.NewCertPool()
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs {
+		if isSelfSigned(cert) {
+			roots.AddCert(cert)
+		} else if cert.IsCA {
+			intermediates.AddCert(cert)
+		}
+	}
+	for _, cert := range certs {
+		if now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+			return fmt.Errorf("certificate '%s' in [%s] is not valid at %s: it is valid from %s to %s", cert.Subject,
+				file, now.UTC().Format(time.RFC3339), cert.NotBefore.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+		if isSelfSigned(cert) {
+			continue
+		}
+		_, err := cert.Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+			CurrentTime:   now,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		})
+		if err != nil {
+			return fmt.Errorf("certificate '%s' in [%s] does not chain to a self-signed certificate of the file: %s", cert.Subject, file, err)
+		}
+	}
+	return nil
+}
+
+// isSelfSigned returns true if a certificate is signed by its own key
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) &&
+		cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature) == nil
+}
+
+func newPublicKey(key crypto.PublicKey) (PublicKey, error) {
+	algorithm := ""
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		algorithm = fmt.Sprintf("ecdsa-%s-sha256", strings.ToLower(strings.ReplaceAll(k.Curve.Params().Name, "-", "")))
+	case ed25519.PublicKey:
+		algorithm = "ed25519"
+	default:
+		return PublicKey{}, fmt.Errorf("only ECDSA and ed25519 public keys are supported, got %T", key)
+	}
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	sum := sha256.Sum256(der)
+	return PublicKey{ID: hex.EncodeToString(sum[:]), Algorithm: algorithm, key: key}, nil
+}
+
+// verifyDigest verifies a signature over a sha256 digest. ECDSA signatures
+// are verified against the digest as the message hash, ed25519 signatures
+// against the digest bytes as the message.
+func (k PublicKey) verifyDigest(digest, sig []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		return verifyECDSA(key, digest, sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, digest, sig)
+	}
+	return false
+}
+
+// verifyMessage verifies a signature over a message
+func (k PublicKey) verifyMessage(message, sig []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		return verifyECDSA(key, digest[:], sig)
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, sig)
+	}
+	return false
+}
+
+// verifyECDSA accepts ASN.1 DER and raw (r || s) encoded signatures
+func verifyECDSA(key *ecdsa.PublicKey, digest, sig []byte) bool {
+	if ecdsa.VerifyASN1(key, digest, sig) {
+		return true
+	}
+	size := (key.Curve.Params().BitSize + 7) / 8
+	if len(sig) != 2*size {
+		return false
+	}
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+	return ecdsa.Verify(key, digest, r, s)
+}
+
+// VerifyFile verifies a signature file with the trusted keys. The file is either a DSSE
+// envelope (JSON), or a detached signature (base64 or binary) over the artifact fingerprint.
+// A failed verification is reported in the returned data, not as an error.
+func VerifyFile(file, fingerprint string, keys []PublicKey) (*VerificationData, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := bytes.TrimSpace(content)
+
+	var envelope dsse.Envelope
+	if json.Valid(trimmed) {
+		if err := json.Unmarshal(trimmed, &envelope); err != nil || envelope.PayloadType == "" {
+			return nil, fmt.Errorf("failed to parse signature file [%s]: JSON signature files must be DSSE envelopes", file)
+		}
+		return verifyEnvelope(&envelope, fingerprint, keys)
+	}
+
+	if fingerprint == "" {
+		return nil, fmt.Errorf("verifying a detached signature requires the artifact fingerprint")
+	}
+	return verifyDetached(content, fingerprint, keys)
+}
+
+func verifyDetached(content []byte, fingerprint string, keys []PublicKey) (*VerificationData, error) {
+	data := &VerificationData{SchemaVersion: 1, SignatureType: TypeDetached}
+	digest, err := hex.DecodeString(fingerprint)
+	if err != nil || len(digest) != sha256.Size {
+		return nil, fmt.Errorf("%s is not a valid SHA256 fingerprint", fingerprint)
+	}
+	sig, err := dsse.DecodeBase64(string(bytes.TrimSpace(content)))
+	if err != nil {
+		// binary signatures are used as is, since trimming could drop signature bytes
+		sig = content
+	}
+	for _, key := range keys {
+		if key.verifyDigest(digest, sig) {
+			data.Verified = true
+			data.KeyID = key.ID
+			data.Algorithm = key.Algorithm
+			return data, nil
+		}
+	}
+	data.Reason = fmt.Sprintf("the signature does not verify the artifact fingerprint with any of the %d trusted key(s)", len(keys))
+	return data, nil
+}
+
+func verifyEnvelope(envelope *dsse.Envelope, fingerprint string, keys []PublicKey) (*VerificationData, error) {
+	data := &VerificationData{
+		SchemaVersion: 1,
+		SignatureType: TypeDSSE,
+		PayloadType:   envelope.PayloadType,
+		PayloadCheck:  PayloadSkipped,
+	}
+	payload, err := envelope.DecodedPayload()
+	if err != nil {
+		return nil, err
+	}
+	pae := dsse.PAE(envelope.PayloadType, payload)
+	for _, s := range envelope.Signatures {
+		sig, err := dsse.DecodeBase64(s.Sig)
+		if err != nil {
+			continue
+		}
+		for _, key := range keys {
+			if key.verifyMessage(pae, sig) {
+				data.Verified = true
+				data.KeyID = key.ID
+				data.Algorithm = key.Algorithm
+				break
+			}
+		}
+		if data.Verified {
+			break
+		}
+	}
+	if !data.Verified {
+		data.Reason = fmt.Sprintf("none of the %d envelope signature(s) verify with any of the %d trusted key(s)", len(envelope.Signatures), len(keys))
+	}
+
+	if fingerprint != "" {
+		data.PayloadCheck = PayloadMismatch
+		if payloadRefersTo(payload, fingerprint) {
+			data.PayloadCheck = PayloadMatch
+		} else if data.Verified {
+			data.Reason = fmt.Sprintf("the signed payload does not refer to the artifact fingerprint %s", fingerprint)
+		}
+	}
+	return data, nil
+}
+
+// payloadRefersTo checks that an in-toto statement has a subject with the fingerprint, or that
+// a cosign simple signing payload is about an image with the fingerprint as its manifest digest.
+// Other payloads do not refer to the fingerprint.
+func payloadRefersTo(payload []byte, fingerprint string) bool {
+	var statement struct {
+		Type    string `json:"_type"`
+		Subject []struct {
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+		Critical struct {
+			Type  string `json:"type"`
+			Image struct {
+				DockerManifestDigest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return false
+	}
+	switch {
+	case strings.HasPrefix(statement.Type, "https://in-toto.io/Statement/"):
+		for _, subject := range statement.Subject {
+			if strings.EqualFold(subject.Digest["sha256"], fingerprint) {
+				return true
+			}
+		}
+	case statement.Critical.Type == "cosign container image signature":
+		return strings.EqualFold(statement.Critical.Image.DockerManifestDigest, "sha256:"+fingerprint)
+	}
+	return false
+}
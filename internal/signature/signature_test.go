@@ -0,0 +1,295 @@
+package signature
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fingerprint  = "7509e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9"
+	ecdsaKeyID   = "ad77a777bf93142f182eac98424f3271db512a7460122786def2c38e15f70244"
+	ed25519KeyID = "8e36daf443f8c100787802fae240f9ab76dddf544ed2489587ea9b28482b4f93"
+)
+
+func TestLoadPublicKeys(t *testing.T) {
+	keys, err := LoadPublicKeys("testdata/ecdsa.pub", time.Now())
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, ecdsaKeyID, keys[0].ID)
+	assert.Equal(t, "ecdsa-p256-sha256", keys[0].Algorithm)
+
+	keys, err = LoadPublicKeys("testdata/trust-bundle.pem", time.Now())
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "ecdsa-p256-sha256", keys[0].Algorithm)
+	assert.Equal(t, ed25519KeyID, keys[1].ID)
+	assert.Equal(t, "ed25519", keys[1].Algorithm)
+
+	_, err = LoadPublicKeys("testdata/ecdsa.sig", time.Now())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no PEM encoded public key or certificate found")
+}
+
+// certificate creates an ECDSA certificate valid from notBefore to notAfter, signed by the parent
+// certificate and key, or self-signed when parent is nil
+func certificate(t *testing.T, name string, isCA bool, notBefore, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// trustBundle writes the certificates to a PEM file
+func trustBundle(t *testing.T, certs ...*x509.Certificate) string {
+	content := []byte{}
+	for _, cert := range certs {
+		content = append(content, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	file := filepath.Join(t.TempDir(), "trust-bundle.pem")
+	require.NoError(t, os.WriteFile(file, content, 0600))
+	return file
+}
+
+func TestLoadPublicKeysCertificates(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	notBefore, notAfter := now.AddDate(-1, 0, 0), now.AddDate(1, 0, 0)
+	root, rootKey := certificate(t, "root", true, notBefore, notAfter, nil, nil)
+	intermediate, intermediateKey := certificate(t, "intermediate", true, notBefore, notAfter, root, rootKey)
+	leaf, _ := certificate(t, "leaf", false, notBefore, notAfter, intermediate, intermediateKey)
+	selfSignedLeaf, _ := certificate(t, "self-signed leaf", false, notBefore, notAfter, nil, nil)
+	expired, _ := certificate(t, "expired", false, notBefore, now.AddDate(0, -1, 0), root, rootKey)
+	notYetValid, _ := certificate(t, "not yet valid", false, now.AddDate(0, 1, 0), notAfter, root, rootKey)
+
+	tests := []struct {
+		name     string
+		certs    []*x509.Certificate
+		wantKeys int
+		wantErr  string
+	}{
+		{
+			name:     "a self-signed certificate is trusted",
+			certs:    []*x509.Certificate{selfSignedLeaf},
+			wantKeys: 1,
+		},
+		{
+			name:     "a certificate chaining to a root certificate of the bundle is trusted",
+			certs:    []*x509.Certificate{leaf, intermediate, root},
+			wantKeys: 3,
+		},
+		{
+			name:    "a certificate which does not chain to a root certificate of the bundle causes an error",
+			certs:   []*x509.Certificate{leaf, intermediate},
+			wantErr: "certificate 'CN=leaf' in [%s] does not chain to a self-signed certificate of the file",
+		},
+		{
+			name:    "an expired certificate causes an error",
+			certs:   []*x509.Certificate{root, expired},
+			wantErr: "certificate 'CN=expired' in [%s] is not valid at 2026-06-01T00:00:00Z",
+		},
+		{
+			name:    "a certificate which is not yet valid causes an error",
+			certs:   []*x509.Certificate{notYetValid, root},
+			wantErr: "certificate 'CN=not yet valid' in [%s] is not valid at 2026-06-01T00:00:00Z",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := trustBundle(t, tt.certs...)
+			keys, err := LoadPublicKeys(file, now)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), fmt.Sprintf(tt.wantErr, file))
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, keys, tt.wantKeys)
+		})
+	}
+}
+
+func TestPayloadRefersTo(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    bool
+	}{
+		{
+			name:    "an in-toto statement with a subject with the fingerprint",
+			payload: `{"_type":"https://in-toto.io/Statement/v1","subject":[{"name":"a","digest":{"sha256":"` + fingerprint + `"}}]}`,
+			want:    true,
+		},
+		{
+			name:    "an in-toto statement with the fingerprint outside its subjects",
+			payload: `{"_type":"https://in-toto.io/Statement/v1","subject":[{"name":"a","digest":{"sha256":"1234"}}],"predicate":{"digest":"` + fingerprint + `"}}`,
+		},
+		{
+			name:    "a cosign simple signing payload about an image with the fingerprint",
+			payload: `{"critical":{"identity":{"docker-reference":"registry.example.com/app"},"image":{"docker-manifest-digest":"sha256:` + fingerprint + `"},"type":"cosign container image signature"},"optional":null}`,
+			want:    true,
+		},
+		{
+			name:    "a cosign simple signing payload with the fingerprint outside its image",
+			payload: `{"critical":{"identity":{"docker-reference":"registry.example.com/app"},"image":{"docker-manifest-digest":"sha256:1234"},"type":"cosign container image signature"},"optional":{"ref":"` + fingerprint + `"}}`,
+		},
+		{
+			name:    "another JSON payload with the fingerprint",
+			payload: `{"not_this":"` + fingerprint + `"}`,
+		},
+		{
+			name:    "a payload which is not JSON",
+			payload: fingerprint,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, payloadRefersTo([]byte(tt.payload), fingerprint))
+		})
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		signature     string
+		keys          string
+		fingerprint   string
+		want          *VerificationData
+		wantCompliant bool
+		wantErr       string
+	}{
+		{
+			name:        "a base64 ECDSA detached signature over the fingerprint is verified",
+			signature:   "testdata/ecdsa.sig",
+			keys:        "testdata/ecdsa.pub",
+			fingerprint: fingerprint,
+			want: &VerificationData{
+				SchemaVersion: 1, SignatureType: TypeDetached, Verified: true,
+				KeyID: ecdsaKeyID, Algorithm: "ecdsa-p256-sha256",
+			},
+			wantCompliant: true,
+		},
+		{
+			name:        "a binary ed25519 detached signature is verified with a certificate in a trust bundle",
+			signature:   "testdata/ed25519.sig",
+			keys:        "testdata/trust-bundle.pem",
+			fingerprint: fingerprint,
+			want: &VerificationData{
+				SchemaVersion: 1, SignatureType: TypeDetached, Verified: true,
+				KeyID: ed25519KeyID, Algorithm: "ed25519",
+			},
+			wantCompliant: true,
+		},
+		{
+			name:        "a detached signature over another fingerprint is not verified",
+			signature:   "testdata/ecdsa.sig",
+			keys:        "testdata/ecdsa.pub",
+			fingerprint: "1234e5bda0c762d2bac7f90d758b5b2263fa01ccbc542ab5e3df163be08e6ca9",
+			want: &VerificationData{
+				SchemaVersion: 1, SignatureType: TypeDetached,
+				Reason: "the signature does not verify the artifact fingerprint with any of the 1 trusted key(s)",
+			},
+		},
+		{
+			name:      "a detached signature requires a fingerprint",
+			signature: "testdata/ecdsa.sig",
+			keys:      "testdata/ecdsa.pub",
+			wantErr:   "verifying a detached signature requires the artifact fingerprint",
+		},
+		{
+			name:        "a DSSE envelope with an in-toto statement about the artifact is verified",
+			signature:   "testdata/ecdsa-intoto.dsse.json",
+			keys:        "testdata/ecdsa.pub",
+			fingerprint: fingerprint,
+			want: &VerificationData{
+				SchemaVersion: 1, SignatureType: TypeDSSE, Verified: true,
+				KeyID: ecdsaKeyID, Algorithm: "ecdsa-p256-sha256",
+				PayloadType: "application/vnd.in-toto+json", PayloadCheck: PayloadMatch,
+			},
+			wantCompliant: true,
+		},
+		{
+			name:      "a DSSE envelope is verified without a fingerprint",
+			signature: "testdata/ecdsa-intoto.dsse.json",
+			keys:      "testdata/ecdsa.pub",
+			want: &VerificationData{
+				SchemaVersion: 1, SignatureType: TypeDSSE, Verified: true,
+				KeyID: ecdsaKeyID, Algorithm: "ecdsa-p256-sha256",
+				PayloadType: "application/vnd.in-toto+json", PayloadCheck: PayloadSkipped,
+			},
+			wantCompliant: true,
+		},
+		{
+			name:        "a DSSE envelope signed with an untrusted key is not verified",
+			signature:   "testdata/ecdsa-intoto.dsse.json",
+			keys:        "testdata/other-ecdsa.pub",
+			fingerprint: fingerprint,
+			want: &VerificationData{
+				SchemaVersion: 1, SignatureType: TypeDSSE,
+				PayloadType: "application/vnd.in-toto+json", PayloadCheck: PayloadMatch,
+				Reason: "none of the 1 envelope signature(s) verify with any of the 1 trusted key(s)",
+			},
+		},
+		{
+			name:        "a verified DSSE envelope about another digest is not compliant",
+			signature:   "testdata/ed25519-other-digest.dsse.json",
+			keys:        "testdata/trust-bundle.pem",
+			fingerprint: fingerprint,
+			want: &VerificationData{
+				SchemaVersion: 1, SignatureType: TypeDSSE, Verified: true,
+				KeyID: ed25519KeyID, Algorithm: "ed25519",
+				PayloadType: "application/vnd.dev.cosign.simplesigning.v1+json", PayloadCheck: PayloadMismatch,
+				Reason: "the signed payload does not refer to the artifact fingerprint " + fingerprint,
+			},
+		},
+		{
+			name:        "a JSON file which is not a DSSE envelope causes an error",
+			signature:   "testdata/not-an-envelope.json",
+			keys:        "testdata/ecdsa.pub",
+			fingerprint: fingerprint,
+			wantErr:     "JSON signature files must be DSSE envelopes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := LoadPublicKeys(tt.keys, time.Now())
+			require.NoError(t, err)
+			data, err := VerifyFile(tt.signature, tt.fingerprint, keys)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, data)
+			assert.Equal(t, tt.wantCompliant, data.Compliant())
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/kosli-dev/cli/internal/baseline"
 	"github.com/owenrumney/go-sarif/v2/sarif"
 )
 
@@ -34,10 +36,19 @@ type SnykResult struct {
 	Low         []Vulnerability `json:"low,omitempty"`
 }
 
+// AcceptedVulnerability is a vulnerability matched by an entry in a baseline file
+type AcceptedVulnerability struct {
+	Vulnerability
+	Severity   string         `json:"severity"`
+	Acceptance baseline.Entry `json:"acceptance"`
+}
+
 type SnykData struct {
-	SchemaVersion int          `json:"schema_version"`
-	Tool          SnykTool     `json:"tool"`
-	Results       []SnykResult `json:"results"`
+	SchemaVersion      int                     `json:"schema_version"`
+	Tool               SnykTool                `json:"tool"`
+	Results            []SnykResult            `json:"results"`
+	Accepted           []AcceptedVulnerability `json:"accepted,omitempty"`
+	ExpiredAcceptances []AcceptedVulnerability `json:"expired_acceptances,omitempty"`
 }
 
 // ProcessSnykResultFile takes a path to a Snyk scan results file
@@ -96,6 +107,48 @@ func ProcessSnykResultFile(file string) (*SnykData, error) {
 	return data, nil
 }
 
+// ApplyBaseline moves the vulnerabilities accepted by a baseline file to the Accepted section.
+// Vulnerabilities matched only by expired entries are kept in the results
+// and recorded in ExpiredAcceptances.
+func (d *SnykData) ApplyBaseline(b *baseline.Baseline, now time.Time) {
+	for i := range d.Results {
+		result := &d.Results[i]
+		result.High = d.filterAccepted(b, now, "high", result.High)
+		result.Medium = d.filterAccepted(b, now, "medium", result.Medium)
+		result.Low = d.filterAccepted(b, now, "low", result.Low)
+		result.HighCount = len(result.High)
+		result.MediumCount = len(result.Medium)
+		result.LowCount = len(result.Low)
+	}
+}
+
+func (d *SnykData) filterAccepted(b *baseline.Baseline, now time.Time, severity string, vulnerabilities []Vulnerability) []Vulnerability {
+	remaining := []Vulnerability{}
+	for _, v := range vulnerabilities {
+		uris := []string{}
+		for _, l := range v.Locations {
+			uris = append(uris, l.URI)
+		}
+		entry, expired := b.Match(v.ID, v.Message, uris, now)
+		if entry == nil {
+			remaining = append(remaining, v)
+			continue
+		}
+		accepted := AcceptedVulnerability{Vulnerability: v, Severity: severity, Acceptance: *entry}
+		if expired {
+			d.ExpiredAcceptances = append(d.ExpiredAcceptances, accepted)
+			remaining = append(remaining, v)
+		} else {
+			d.Accepted = append(d.Accepted, accepted)
+		}
+	}
+	if len(remaining) == 0 {
+		// keep omitting empty severity lists from the payload
+		return nil
+	}
+	return remaining
+}
+
 func createVulnerability(r *sarif.Result) Vulnerability {
 	locations := []Location{}
 	for _, l := range r.Locations {
@@ -2,6 +2,11 @@ package snyk
 
 import (
 	"testing"
+	"time"
+
+	"github.com/kosli-dev/cli/internal/baseline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProcessSnykResultFile(t *testing.T) {
@@ -171,3 +176,26 @@ func TestProcessSnykResultFile(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyBaseline(t *testing.T) {
+	b, err := baseline.Load("testdata/baseline.yml")
+	require.NoError(t, err)
+
+	data, err := ProcessSnykResultFile("sarif-os.json")
+	require.NoError(t, err)
+	data.ApplyBaseline(b, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+	require.Len(t, data.Accepted, 1)
+	assert.Equal(t, "SNYK-JS-GOT-2932019", data.Accepted[0].ID)
+	assert.Equal(t, "medium", data.Accepted[0].Severity)
+	assert.Equal(t, "Only used at build time", data.Accepted[0].Acceptance.Reason)
+	assert.Empty(t, data.ExpiredAcceptances)
+	assert.Equal(t, 5, data.Results[0].MediumCount)
+	assert.Len(t, data.Results[0].Medium, 5)
+
+	data, err = ProcessSnykResultFile("sarif-os.json")
+	require.NoError(t, err)
+	data.ApplyBaseline(b, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Empty(t, data.Accepted)
+	require.Len(t, data.ExpiredAcceptances, 1)
+	assert.Equal(t, 6, data.Results[0].MediumCount)
+}
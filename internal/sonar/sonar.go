@@ -3,20 +3,48 @@ package sonar
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
+)
+
+// Statuses of SonarQube compute-engine tasks
+const (
+	TaskPending    = "PENDING"
+	TaskInProgress = "IN_PROGRESS"
+	TaskSuccess    = "SUCCESS"
+	TaskFailed     = "FAILED"
+	TaskCanceled   = "CANCELED"
+)
+
+// BranchTypePullRequest is the branch type of the analysis of a pull request, as in SonarQube's webhook payload
+const BranchTypePullRequest = "PULL_REQUEST"
+
+// initialPollInterval is the interval before polling SonarQube again when waiting.
+// It doubles after each poll, up to maxPollInterval.
+var (
+	initialPollInterval = 2 * time.Second
+	maxPollInterval     = 30 * time.Second
 )
 
 type SonarConfig struct {
 	APIToken   string
 	WorkingDir string
 	CETaskUrl  string
-	revision   string
-	projectKey string
-	serverURL  string
+	// Wait makes GetSonarResults wait for the analysis to complete, for at most Timeout
+	Wait    bool
+	Timeout time.Duration
+	// Branch or PullRequest select the analysis of a branch or pull request when using the project key and revision
+	Branch      string
+	PullRequest string
+	revision    string
+	projectKey  string
+	serverURL   string
 }
 
 // Structs to build the JSON for our attestation payload
@@ -86,6 +114,8 @@ type Task struct {
 	Status        string `json:"status"`
 	Branch        string `json:"branch"`
 	BranchType    string `json:"branchType"`
+	PullRequest   string `json:"pullRequest"`
+	ErrorMessage  string `json:"errorMessage"`
 }
 
 type ActivityResponse struct {
@@ -145,8 +175,21 @@ func (sc *SonarConfig) GetSonarResults() (*SonarResults, error) {
 			project.Key = sc.projectKey
 			sonarResults.ServerUrl = sc.serverURL
 			sonarResults.Revision = sc.revision
-			project.Url = fmt.Sprintf("%s/dashboard?id=%s", sonarResults.ServerUrl, project.Key)
-			analysisID, err = GetProjectAnalysisFromRevision(httpClient, sonarResults, project, sc.revision, tokenHeader)
+			sonarResults.Branch = sc.branch()
+			project.Url = fmt.Sprintf("%s/dashboard?id=%s%s", sonarResults.ServerUrl, project.Key, branchQuery(sonarResults.Branch))
+			if sc.Wait {
+				// the analysis of the revision only exists once its task is complete
+				err = sc.poll(fmt.Sprintf("the analysis of revision %s of project %s%s", sc.revision, project.Key, describeBranch(sonarResults.Branch)), func() (bool, error) {
+					analysisID, err = GetProjectAnalysisFromRevision(httpClient, sonarResults, project, sc.revision, tokenHeader)
+					var notFound *analysisNotFoundError
+					if errors.As(err, &notFound) {
+						return false, nil
+					}
+					return err == nil, err
+				})
+			} else {
+				analysisID, err = GetProjectAnalysisFromRevision(httpClient, sonarResults, project, sc.revision, tokenHeader)
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -158,6 +201,13 @@ func (sc *SonarConfig) GetSonarResults() (*SonarResults, error) {
 	}
 
 	if analysisID == "" {
+		if sc.Wait {
+			err = sc.waitForCETask(httpClient, tokenHeader)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		//Get the analysis ID, status, project name and branch data from the ceTaskURL (ce API)
 		analysisID, err = GetCETaskData(httpClient, project, sonarResults, sc.CETaskUrl, tokenHeader)
 		if err != nil {
@@ -210,23 +260,138 @@ func (sc *SonarConfig) readFile(project *Project, results *SonarResults) error {
 	return nil
 }
 
-func GetCETaskData(httpClient *http.Client, project *Project, sonarResults *SonarResults, ceTaskURL, tokenHeader string) (string, error) {
+// branch returns the branch or pull request given for the project key and revision, if any
+func (sc *SonarConfig) branch() *Branch {
+	if sc.PullRequest != "" {
+		return &Branch{Name: sc.PullRequest, Type: BranchTypePullRequest}
+	}
+	if sc.Branch != "" {
+		return &Branch{Name: sc.Branch, Type: "BRANCH"}
+	}
+	return nil
+}
+
+// branchQuery returns the query parameters selecting the analyses of a branch or pull request in the SonarQube APIs.
+// The analyses of the main branch are selected when branch is nil.
+func branchQuery(branch *Branch) string {
+	switch {
+	case branch == nil || branch.Name == "":
+		return ""
+	case branch.Type == BranchTypePullRequest:
+		return "&pullRequest=" + url.QueryEscape(branch.Name)
+	default:
+		return "&branch=" + url.QueryEscape(branch.Name)
+	}
+}
+
+// taskBranch returns the branch or pull request analysed by a compute-engine task, if any
+func taskBranch(task *Task) *Branch {
+	if task.PullRequest != "" {
+		return &Branch{Name: task.PullRequest, Type: BranchTypePullRequest}
+	}
+	if task.Branch != "" {
+		return &Branch{Name: task.Branch, Type: task.BranchType}
+	}
+	return nil
+}
+
+// waitForCETask polls the compute-engine task of the analysis until it is complete, with backoff.
+// It fails when the task does not succeed, or does not complete within the timeout.
+func (sc *SonarConfig) waitForCETask(httpClient *http.Client, tokenHeader string) error {
+	var task *Task
+	err := sc.poll("the SonarQube analysis task "+sc.CETaskUrl, func() (bool, error) {
+		var err error
+		task, err = getCETask(httpClient, sc.CETaskUrl, tokenHeader)
+		if err != nil {
+			return false, err
+		}
+		return task.Status != TaskPending && task.Status != TaskInProgress, nil
+	})
+	if err != nil {
+		return err
+	}
+	if task.Status != TaskSuccess {
+		return taskError(task)
+	}
+	return nil
+}
+
+// poll calls check until it is done, waiting longer between each call, for at most the timeout
+func (sc *SonarConfig) poll(description string, check func() (bool, error)) error {
+	deadline := time.Now().Add(sc.Timeout)
+	interval := initialPollInterval
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("timed out after %s waiting for %s to complete", sc.Timeout, description)
+		}
+		time.Sleep(min(interval, remaining))
+		interval = min(interval*2, maxPollInterval)
+	}
+}
+
+// analysisNotFoundError is the error when a project has no analysis for a revision
+type analysisNotFoundError struct {
+	revision   string
+	projectKey string
+	branch     *Branch
+}
+
+func (e *analysisNotFoundError) Error() string {
+	return fmt.Sprintf("analysis for revision %s of project %s%s not found. Check the revision is correct. Snapshot may also have been deleted by SonarQube", e.revision, e.projectKey, describeBranch(e.branch))
+}
+
+// describeBranch describes the branch or pull request of an analysis in messages
+func describeBranch(branch *Branch) string {
+	switch {
+	case branch == nil || branch.Name == "":
+		return ""
+	case branch.Type == BranchTypePullRequest:
+		return fmt.Sprintf(" (pull request %s)", branch.Name)
+	default:
+		return fmt.Sprintf(" (branch %s)", branch.Name)
+	}
+}
+
+// taskError is the error for a compute-engine task which did not succeed
+func taskError(task *Task) error {
+	if task.ErrorMessage != "" {
+		return fmt.Errorf("SonarQube analysis task %s is %s: %s", task.TaskID, task.Status, task.ErrorMessage)
+	}
+	return fmt.Errorf("SonarQube analysis task %s is %s", task.TaskID, task.Status)
+}
+
+func getCETask(httpClient *http.Client, ceTaskURL, tokenHeader string) (*Task, error) {
 	taskRequest, err := http.NewRequest("GET", ceTaskURL, nil)
-	taskRequest.Header.Add("Authorization", tokenHeader)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	taskRequest.Header.Add("Authorization", tokenHeader)
 
 	taskResponse, err := httpClient.Do(taskRequest)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	defer taskResponse.Body.Close()
 
 	taskResponseData := &TaskResponse{}
 	err = json.NewDecoder(taskResponse.Body).Decode(taskResponseData)
 	if err != nil {
-		return "", fmt.Errorf("please check your API token is correct and you have the correct permissions in SonarQube")
+		return nil, fmt.Errorf("please check your API token is correct and you have the correct permissions in SonarQube")
+	}
+	return &taskResponseData.Task, nil
+}
+
+func GetCETaskData(httpClient *http.Client, project *Project, sonarResults *SonarResults, ceTaskURL, tokenHeader string) (string, error) {
+	task, err := getCETask(httpClient, ceTaskURL, tokenHeader)
+	if err != nil {
+		return "", err
 	}
+	taskResponseData := &TaskResponse{Task: *task}
 
 	project.Name = taskResponseData.Task.ComponentName
 	project.Key = taskResponseData.Task.ComponentKey
@@ -235,6 +400,12 @@ func GetCETaskData(httpClient *http.Client, project *Project, sonarResults *Sona
 	sonarResults.Status = taskResponseData.Task.Status
 
 	if analysisId == "" {
+		switch sonarResults.Status {
+		case TaskPending, TaskInProgress:
+			return "", fmt.Errorf("SonarQube analysis task %s is %s, the analysis is not finished yet. Use --wait to wait for it to complete", sonarResults.TaskID, sonarResults.Status)
+		case TaskFailed, TaskCanceled:
+			return "", taskError(task)
+		}
 		return "", fmt.Errorf("analysis ID not found on %s", sonarResults.ServerUrl) // This should never happen
 	}
 
@@ -242,22 +413,15 @@ func GetCETaskData(httpClient *http.Client, project *Project, sonarResults *Sona
 		project.Url = fmt.Sprintf("%s/dashboard?id=%s", sonarResults.ServerUrl, project.Key)
 	}
 
-	if taskResponseData.Task.Branch != "" {
-		sonarResults.Branch = &Branch{}
-		sonarResults.Branch.Name = taskResponseData.Task.Branch
-		sonarResults.Branch.Type = taskResponseData.Task.BranchType
-	} else {
-		sonarResults.Branch = nil
-	}
+	sonarResults.Branch = taskBranch(task)
 
-	taskResponse.Body.Close()
 	return analysisId, nil
 }
 
 func GetProjectAnalysisFromRevision(httpClient *http.Client, sonarResults *SonarResults, project *Project, revision, tokenHeader string) (string, error) {
 	var analysisID string
 
-	projectAnalysesURL := fmt.Sprintf("%s/api/project_analyses/search?project=%s", sonarResults.ServerUrl, project.Key)
+	projectAnalysesURL := fmt.Sprintf("%s/api/project_analyses/search?project=%s%s", sonarResults.ServerUrl, project.Key, branchQuery(sonarResults.Branch))
 	projectAnalysesRequest, err := http.NewRequest("GET", projectAnalysesURL, nil)
 	projectAnalysesRequest.Header.Add("Authorization", tokenHeader)
 	if err != nil {
@@ -288,7 +452,7 @@ func GetProjectAnalysisFromRevision(httpClient *http.Client, sonarResults *Sonar
 	}
 
 	if sonarResults.AnalaysedAt == "" {
-		return "", fmt.Errorf("analysis for revision %s of project %s not found. Check the revision is correct. Snapshot may also have been deleted by SonarQube", revision, project.Key)
+		return "", &analysisNotFoundError{revision: revision, projectKey: project.Key, branch: sonarResults.Branch}
 	}
 	projectAnalysesResponse.Body.Close()
 
@@ -296,7 +460,7 @@ func GetProjectAnalysisFromRevision(httpClient *http.Client, sonarResults *Sonar
 }
 
 func GetProjectAnalysisFromAnalysisID(httpClient *http.Client, sonarResults *SonarResults, project *Project, analysisID, tokenHeader string) error {
-	projectAnalysesURL := fmt.Sprintf("%s/api/project_analyses/search?project=%s", sonarResults.ServerUrl, project.Key)
+	projectAnalysesURL := fmt.Sprintf("%s/api/project_analyses/search?project=%s%s", sonarResults.ServerUrl, project.Key, branchQuery(sonarResults.Branch))
 	projectAnalysesRequest, err := http.NewRequest("GET", projectAnalysesURL, nil)
 	projectAnalysesRequest.Header.Add("Authorization", tokenHeader)
 	if err != nil {
@@ -393,13 +557,7 @@ func GetTaskID(httpClient *http.Client, sonarResults *SonarResults, project *Pro
 			sonarResults.TaskID = CEActivityData.Tasks[task].TaskID
 			sonarResults.Status = CEActivityData.Tasks[task].Status
 			project.Name = CEActivityData.Tasks[task].ComponentName
-			if CEActivityData.Tasks[task].Branch != "" {
-				sonarResults.Branch = &Branch{}
-				sonarResults.Branch.Name = CEActivityData.Tasks[task].Branch
-				sonarResults.Branch.Type = CEActivityData.Tasks[task].BranchType
-			} else {
-				sonarResults.Branch = nil
-			}
+			sonarResults.Branch = taskBranch(&CEActivityData.Tasks[task])
 			break
 		}
 	}
@@ -0,0 +1,262 @@
+package sonar
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	projectAnalysesBody = `{"analyses": [{"key": "AZ-analysis-1", "date": "2024-03-01T10:00:00+0000", "revision": "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6"}]}`
+	qualityGateBody     = `{"projectStatus": {"status": "OK", "conditions": [{"status": "OK", "metricKey": "new_coverage", "comparator": "LT", "errorThreshold": "80", "actualValue": "85.0"}]}}`
+)
+
+// fakeSonarQube is a SonarQube server whose analysis task goes through the given statuses, one per poll.
+// The task is polled from its task URL or, with pollAnalyses, from the analyses of the project,
+// where the analysis only exists once the task has succeeded.
+type fakeSonarQube struct {
+	*httptest.Server
+	taskStatuses []string
+	pollAnalyses bool
+	taskPolls    int
+}
+
+func newFakeSonarQube(t *testing.T, pollAnalyses bool, taskStatuses ...string) *fakeSonarQube {
+	fake := &fakeSonarQube{taskStatuses: taskStatuses, pollAnalyses: pollAnalyses}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/ce/task", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer some-token", r.Header.Get("Authorization"))
+		status := fake.currentTaskStatus()
+		fake.taskPolls++
+		analysisID, errorMessage := "", ""
+		switch status {
+		case TaskSuccess:
+			analysisID = "AZ-analysis-1"
+		case TaskFailed:
+			errorMessage = "Unsupported language: cobol"
+		}
+		fmt.Fprintf(w, `{"task": {"id": "AZ-task-1", "componentKey": "acme_shop", "componentName": "Shop",
+			"status": "%s", "analysisId": "%s", "errorMessage": "%s"}}`, status, analysisID, errorMessage)
+	})
+	mux.HandleFunc("/api/project_analyses/search", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "acme_shop", r.URL.Query().Get("project"))
+		if fake.pollAnalyses {
+			status := fake.currentTaskStatus()
+			fake.taskPolls++
+			if status != TaskSuccess {
+				fmt.Fprint(w, `{"analyses": []}`)
+				return
+			}
+		}
+		fmt.Fprint(w, projectAnalysesBody)
+	})
+	mux.HandleFunc("/api/ce/activity", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"tasks": [{"id": "AZ-task-1", "componentKey": "acme_shop", "componentName": "Shop", "status": "SUCCESS", "analysisId": "AZ-analysis-1"}]}`)
+	})
+	mux.HandleFunc("/api/qualitygates/project_status", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "AZ-analysis-1", r.URL.Query().Get("analysisId"))
+		fmt.Fprint(w, qualityGateBody)
+	})
+	fake.Server = httptest.NewServer(mux)
+	return fake
+}
+
+// currentTaskStatus returns the status of the task at this poll, the last status once all have been returned
+func (f *fakeSonarQube) currentTaskStatus() string {
+	if f.taskPolls < len(f.taskStatuses) {
+		return f.taskStatuses[f.taskPolls]
+	}
+	return f.taskStatuses[len(f.taskStatuses)-1]
+}
+
+// writeReportTask writes the metadata file of the Sonar scanner, which points at the task of the fake server
+func writeReportTask(t *testing.T, serverURL string) string {
+	dir := t.TempDir()
+	content := fmt.Sprintf("projectKey=acme_shop\nserverUrl=%s\ndashboardUrl=%s/dashboard?id=acme_shop\nceTaskId=AZ-task-1\nceTaskUrl=%s/api/ce/task?id=AZ-task-1\n",
+		serverURL, serverURL, serverURL)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report-task.txt"), []byte(content), 0600))
+	return dir
+}
+
+func TestGetSonarResultsWaitsForTheAnalysisTask(t *testing.T) {
+	initialPollInterval = time.Millisecond
+	maxPollInterval = 4 * time.Millisecond
+
+	for _, tt := range []struct {
+		name           string
+		taskStatuses   []string
+		wait           bool
+		timeout        time.Duration
+		useRevision    bool
+		wantTaskPolls  int
+		wantErrorRegex string
+	}{
+		{
+			name:          "a complete task is not waited for",
+			taskStatuses:  []string{TaskSuccess},
+			wantTaskPolls: 1,
+		},
+		{
+			name:           "an unfinished task is an error without --wait",
+			taskStatuses:   []string{TaskInProgress},
+			wantTaskPolls:  1,
+			wantErrorRegex: "^SonarQube analysis task AZ-task-1 is IN_PROGRESS, the analysis is not finished yet. Use --wait to wait for it to complete$",
+		},
+		{
+			name:          "an unfinished task is polled until it succeeds",
+			taskStatuses:  []string{TaskPending, TaskInProgress, TaskInProgress, TaskSuccess},
+			wait:          true,
+			timeout:       time.Minute,
+			wantTaskPolls: 5, // the successful task is fetched once more to read the analysis
+		},
+		{
+			name:           "a failed task is an error with its error message",
+			taskStatuses:   []string{TaskInProgress, TaskFailed},
+			wait:           true,
+			timeout:        time.Minute,
+			wantTaskPolls:  2,
+			wantErrorRegex: "^SonarQube analysis task AZ-task-1 is FAILED: Unsupported language: cobol$",
+		},
+		{
+			name:           "a canceled task is an error",
+			taskStatuses:   []string{TaskPending, TaskCanceled},
+			wait:           true,
+			timeout:        time.Minute,
+			wantTaskPolls:  2,
+			wantErrorRegex: "^SonarQube analysis task AZ-task-1 is CANCELED$",
+		},
+		{
+			name:           "a task which does not complete in time is an error",
+			taskStatuses:   []string{TaskInProgress},
+			wait:           true,
+			timeout:        20 * time.Millisecond,
+			wantErrorRegex: "^timed out after 20ms waiting for the SonarQube analysis task .*/api/ce/task\\?id=AZ-task-1 to complete$",
+		},
+		{
+			name:          "the analysis of a revision is polled until it exists",
+			taskStatuses:  []string{TaskPending, TaskInProgress, TaskSuccess},
+			wait:          true,
+			timeout:       time.Minute,
+			useRevision:   true,
+			wantTaskPolls: 3,
+		},
+		{
+			name:           "the analysis of a revision which does not exist in time is an error",
+			taskStatuses:   []string{TaskInProgress},
+			wait:           true,
+			timeout:        20 * time.Millisecond,
+			useRevision:    true,
+			wantErrorRegex: "^timed out after 20ms waiting for the analysis of revision 9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6 of project acme_shop to complete$",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSonarQube(t, tt.useRevision, tt.taskStatuses...)
+			defer fake.Close()
+
+			workingDir := writeReportTask(t, fake.URL)
+			if tt.useRevision {
+				workingDir = t.TempDir()
+			}
+			sc := NewSonarConfig("some-token", workingDir, "", "acme_shop", fake.URL, "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6")
+			sc.Wait = tt.wait
+			sc.Timeout = tt.timeout
+
+			results, err := sc.GetSonarResults()
+			if tt.wantErrorRegex != "" {
+				require.Error(t, err)
+				require.Regexp(t, tt.wantErrorRegex, err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, "SUCCESS", results.Status)
+				require.Equal(t, "AZ-task-1", results.TaskID)
+				require.Equal(t, "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6", results.Revision)
+				require.Equal(t, "acme_shop", results.Project.Key)
+				require.Equal(t, "OK", results.QualityGate.Status)
+				require.Len(t, results.QualityGate.Conditions, 1)
+			}
+			if tt.wantTaskPolls > 0 {
+				require.Equal(t, tt.wantTaskPolls, fake.taskPolls)
+			}
+		})
+	}
+}
+
+func TestGetSonarResultsOfABranchOrPullRequest(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		branch          string
+		pullRequest     string
+		useMetadata     bool
+		task            string
+		wantBranchQuery string
+		wantBranch      *Branch
+		wantProjectURL  string
+	}{
+		{
+			name:            "the analysis of a pull request is found by project key and revision",
+			pullRequest:     "42",
+			task:            `"pullRequest": "42"`,
+			wantBranchQuery: "&pullRequest=42",
+			wantBranch:      &Branch{Name: "42", Type: BranchTypePullRequest},
+			wantProjectURL:  "/dashboard?id=acme_shop&pullRequest=42",
+		},
+		{
+			name:            "the analysis of a branch is found by project key and revision",
+			branch:          "feature/checkout",
+			task:            `"branch": "feature/checkout", "branchType": "BRANCH"`,
+			wantBranchQuery: "&branch=feature%2Fcheckout",
+			wantBranch:      &Branch{Name: "feature/checkout", Type: "BRANCH"},
+			wantProjectURL:  "/dashboard?id=acme_shop&branch=feature%2Fcheckout",
+		},
+		{
+			name:            "the analysis of a pull request is found from the metadata of the scan",
+			useMetadata:     true,
+			task:            `"pullRequest": "42"`,
+			wantBranchQuery: "&pullRequest=42",
+			wantBranch:      &Branch{Name: "42", Type: BranchTypePullRequest},
+			wantProjectURL:  "/dashboard?id=acme_shop",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			task := fmt.Sprintf(`{"id": "AZ-task-1", "componentKey": "acme_shop", "componentName": "Shop", "status": "SUCCESS", "analysisId": "AZ-analysis-1", %s}`, tt.task)
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/ce/task", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"task": %s}`, task)
+			})
+			mux.HandleFunc("/api/ce/activity", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `{"tasks": [%s]}`, task)
+			})
+			mux.HandleFunc("/api/project_analyses/search", func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "project=acme_shop"+tt.wantBranchQuery, r.URL.RawQuery)
+				fmt.Fprint(w, projectAnalysesBody)
+			})
+			mux.HandleFunc("/api/qualitygates/project_status", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, qualityGateBody)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			workingDir := t.TempDir()
+			if tt.useMetadata {
+				workingDir = writeReportTask(t, server.URL)
+			}
+			sc := NewSonarConfig("some-token", workingDir, "", "acme_shop", server.URL, "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6")
+			sc.Branch = tt.branch
+			sc.PullRequest = tt.pullRequest
+
+			results, err := sc.GetSonarResults()
+			require.NoError(t, err)
+			require.Equal(t, "AZ-task-1", results.TaskID)
+			require.Equal(t, "9f1c2e3d4b5a69788796a5b4c3d2e1f0a9b8c7d6", results.Revision)
+			require.Equal(t, tt.wantBranch, results.Branch)
+			require.Equal(t, server.URL+tt.wantProjectURL, results.Project.Url)
+			require.Equal(t, "OK", results.QualityGate.Status)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package sonar
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// thresholdOperators are the comparison operators of metric thresholds, longest first so that they are matched greedily
+var thresholdOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+var metricThresholdRegex = regexp.MustCompile(`^\s*([a-zA-Z0-9_.:-]+)\s*(>=|<=|==|!=|>|<)\s*(\S+)\s*$`)
+
+// MetricThreshold is a condition on the value of a SonarQube metric, e.g. new_coverage>=80
+type MetricThreshold struct {
+	Metric   string
+	Operator string
+	Value    float64
+}
+
+// MetricThresholdResult is the outcome of evaluating a MetricThreshold against the measures of an analysis
+type MetricThresholdResult struct {
+	Threshold string `json:"threshold"`
+	Metric    string `json:"metric"`
+	// Value is empty when SonarQube has no measure of the metric
+	Value  string `json:"value,omitempty"`
+	Passed bool   `json:"passed"`
+}
+
+// These are the structs for the response from the measures/search_history API
+type MeasuresHistoryResponse struct {
+	Measures []MeasureHistory `json:"measures"`
+	Errors   []Error          `json:"errors,omitempty"`
+}
+
+// MeasureHistory is the values of a metric in each analysis. The value of metrics on new code (new_*)
+// is also in the value of the analysis.
+type MeasureHistory struct {
+	Metric  string            `json:"metric"`
+	History []MeasureAnalysis `json:"history"`
+}
+
+type MeasureAnalysis struct {
+	Date  string `json:"date"`
+	Value string `json:"value,omitempty"`
+}
+
+// ParseMetricThresholds parses thresholds of the form METRIC OPERATOR VALUE, e.g. new_vulnerabilities==0
+func ParseMetricThresholds(thresholds []string) ([]MetricThreshold, error) {
+	parsed := []MetricThreshold{}
+	for _, threshold := range thresholds {
+		matches := metricThresholdRegex.FindStringSubmatch(threshold)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid metric threshold '%s'. It must be of the form METRIC OPERATOR VALUE, e.g. new_coverage>=80, where OPERATOR is one of: %s",
+				threshold, strings.Join(thresholdOperators, ", "))
+		}
+		value, err := strconv.ParseFloat(matches[3], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric threshold '%s'. The value %s is not a number", threshold, matches[3])
+		}
+		parsed = append(parsed, MetricThreshold{Metric: matches[1], Operator: matches[2], Value: value})
+	}
+	return parsed, nil
+}
+
+func (t MetricThreshold) String() string {
+	return fmt.Sprintf("%s%s%s", t.Metric, t.Operator, strconv.FormatFloat(t.Value, 'f', -1, 64))
+}
+
+// IsMetBy is true when the value satisfies the threshold
+func (t MetricThreshold) IsMetBy(value float64) bool {
+	switch t.Operator {
+	case ">=":
+		return value >= t.Value
+	case "<=":
+		return value <= t.Value
+	case "==":
+		return value == t.Value
+	case "!=":
+		return value != t.Value
+	case ">":
+		return value > t.Value
+	case "<":
+		return value < t.Value
+	}
+	return false
+}
+
+// EvaluateMetricThresholds gets the measures of the analysis of the results, rather than the latest analysis
+// of the project, branch or pull request, and evaluates the thresholds against them. A threshold on a metric without a measure is not met.
+func (sc *SonarConfig) EvaluateMetricThresholds(sonarResults *SonarResults, thresholds []MetricThreshold) ([]MetricThresholdResult, error) {
+	if len(thresholds) == 0 {
+		return []MetricThresholdResult{}, nil
+	}
+	if sc.APIToken == "" {
+		return nil, fmt.Errorf("API token must be given to retrieve data from SonarQube")
+	}
+
+	metrics := []string{}
+	for _, threshold := range thresholds {
+		metrics = append(metrics, threshold.Metric)
+	}
+	values, err := GetMeasures(&http.Client{}, sonarResults, metrics, fmt.Sprintf("Bearer %s", sc.APIToken))
+	if err != nil {
+		return nil, err
+	}
+
+	results := []MetricThresholdResult{}
+	for _, threshold := range thresholds {
+		result := MetricThresholdResult{Threshold: threshold.String(), Metric: threshold.Metric}
+		if value, ok := values[threshold.Metric]; ok {
+			result.Value = value
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("the value %s of metric %s is not a number", value, threshold.Metric)
+			}
+			result.Passed = threshold.IsMetBy(number)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// GetMeasures returns the values of the metrics in the analysis of the results, which is selected by its date
+// among the analyses of the project, branch or pull request. Metrics without a measure are left out.
+func GetMeasures(httpClient *http.Client, sonarResults *SonarResults, metrics []string, tokenHeader string) (map[string]string, error) {
+	if sonarResults.AnalaysedAt == "" {
+		return nil, fmt.Errorf("the date of the analysis of project %s is not known, so its measures cannot be retrieved", sonarResults.Project.Key)
+	}
+	analysedAt := url.QueryEscape(sonarResults.AnalaysedAt)
+	measuresURL := fmt.Sprintf("%s/api/measures/search_history?component=%s&metrics=%s&from=%s&to=%s%s", sonarResults.ServerUrl,
+		url.QueryEscape(sonarResults.Project.Key), url.QueryEscape(strings.Join(metrics, ",")), analysedAt, analysedAt,
+		branchQuery(sonarResults.Branch))
+	measuresRequest, err := http.NewRequest("GET", measuresURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	measuresRequest.Header.Add("Authorization", tokenHeader)
+
+	measuresResponse, err := httpClient.Do(measuresRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer measuresResponse.Body.Close()
+
+	measuresData := &MeasuresHistoryResponse{}
+	err = json.NewDecoder(measuresResponse.Body).Decode(measuresData)
+	if err != nil {
+		return nil, fmt.Errorf("please check your API token is correct and you have the correct permissions in SonarQube")
+	}
+	if measuresData.Errors != nil {
+		return nil, fmt.Errorf("sonar error: %s", measuresData.Errors[0].Msg)
+	}
+
+	values := map[string]string{}
+	for _, measure := range measuresData.Measures {
+		for _, analysis := range measure.History {
+			if analysis.Date == sonarResults.AnalaysedAt && analysis.Value != "" {
+				values[measure.Metric] = analysis.Value
+			}
+		}
+	}
+	return values, nil
+}
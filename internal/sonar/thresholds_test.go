@@ -0,0 +1,185 @@
+package sonar
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetricThresholds(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		thresholds     []string
+		want           []MetricThreshold
+		wantErrorRegex string
+	}{
+		{
+			name:       "no thresholds",
+			thresholds: []string{},
+			want:       []MetricThreshold{},
+		},
+		{
+			name:       "thresholds with each operator",
+			thresholds: []string{"new_coverage>=80", "new_duplicated_lines_density <= 3.5", "new_vulnerabilities==0", "bugs!=1", "coverage>50", "new_security_rating<2"},
+			want: []MetricThreshold{
+				{Metric: "new_coverage", Operator: ">=", Value: 80},
+				{Metric: "new_duplicated_lines_density", Operator: "<=", Value: 3.5},
+				{Metric: "new_vulnerabilities", Operator: "==", Value: 0},
+				{Metric: "bugs", Operator: "!=", Value: 1},
+				{Metric: "coverage", Operator: ">", Value: 50},
+				{Metric: "new_security_rating", Operator: "<", Value: 2},
+			},
+		},
+		{
+			name:           "a threshold without an operator is an error",
+			thresholds:     []string{"new_coverage=80"},
+			wantErrorRegex: "^invalid metric threshold 'new_coverage=80'. It must be of the form METRIC OPERATOR VALUE, e.g. new_coverage>=80, where OPERATOR is one of: >=, <=, ==, !=, >, <$",
+		},
+		{
+			name:           "a threshold whose value is not a number is an error",
+			thresholds:     []string{"alert_status==OK"},
+			wantErrorRegex: "^invalid metric threshold 'alert_status==OK'. The value OK is not a number$",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			thresholds, err := ParseMetricThresholds(tt.thresholds)
+			if tt.wantErrorRegex != "" {
+				require.Error(t, err)
+				require.Regexp(t, tt.wantErrorRegex, err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, thresholds)
+			}
+		})
+	}
+}
+
+func TestMetricThresholdString(t *testing.T) {
+	thresholds, err := ParseMetricThresholds([]string{"new_coverage >= 80.0", "new_duplicated_lines_density<=3.5"})
+	require.NoError(t, err)
+	require.Equal(t, "new_coverage>=80", thresholds[0].String())
+	require.Equal(t, "new_duplicated_lines_density<=3.5", thresholds[1].String())
+}
+
+func TestEvaluateMetricThresholds(t *testing.T) {
+	const analysedAt = "2024-05-02T10:15:00+0000"
+	const history = "from=2024-05-02T10%3A15%3A00%2B0000&to=2024-05-02T10%3A15%3A00%2B0000"
+	for _, tt := range []struct {
+		name           string
+		branch         *Branch
+		wantQuery      string
+		thresholds     []string
+		measures       string
+		want           []MetricThresholdResult
+		wantErrorRegex string
+	}{
+		{
+			name:       "thresholds are evaluated against the measures of the analysis on the main branch",
+			wantQuery:  "component=acme_shop&metrics=new_coverage%2Cnew_vulnerabilities%2Cbugs&" + history,
+			thresholds: []string{"new_coverage>=80", "new_vulnerabilities==0", "bugs<=1"},
+			measures: `[{"metric": "new_coverage", "history": [{"date": "2024-05-02T10:15:00+0000", "value": "72.5"}]},
+				{"metric": "new_vulnerabilities", "history": [{"date": "2024-05-02T10:15:00+0000", "value": "0"}]},
+				{"metric": "bugs", "history": [{"date": "2024-05-02T10:15:00+0000", "value": "1"}]}]`,
+			want: []MetricThresholdResult{
+				{Threshold: "new_coverage>=80", Metric: "new_coverage", Value: "72.5", Passed: false},
+				{Threshold: "new_vulnerabilities==0", Metric: "new_vulnerabilities", Value: "0", Passed: true},
+				{Threshold: "bugs<=1", Metric: "bugs", Value: "1", Passed: true},
+			},
+		},
+		{
+			name:       "thresholds are evaluated against the measures of the analysis on a pull request",
+			branch:     &Branch{Name: "42", Type: BranchTypePullRequest},
+			wantQuery:  "component=acme_shop&metrics=new_coverage&" + history + "&pullRequest=42",
+			thresholds: []string{"new_coverage>=80"},
+			measures:   `[{"metric": "new_coverage", "history": [{"date": "2024-05-02T10:15:00+0000", "value": "91.0"}]}]`,
+			want: []MetricThresholdResult{
+				{Threshold: "new_coverage>=80", Metric: "new_coverage", Value: "91.0", Passed: true},
+			},
+		},
+		{
+			name:       "thresholds are evaluated against the measures of the analysis on a branch",
+			branch:     &Branch{Name: "feature/checkout", Type: "BRANCH"},
+			wantQuery:  "component=acme_shop&metrics=new_coverage&" + history + "&branch=feature%2Fcheckout",
+			thresholds: []string{"new_coverage>=80"},
+			measures:   `[{"metric": "new_coverage", "history": [{"date": "2024-05-02T10:15:00+0000", "value": "80"}]}]`,
+			want: []MetricThresholdResult{
+				{Threshold: "new_coverage>=80", Metric: "new_coverage", Value: "80", Passed: true},
+			},
+		},
+		{
+			name:       "the measures of other analyses are not used",
+			wantQuery:  "component=acme_shop&metrics=new_coverage&" + history,
+			thresholds: []string{"new_coverage>=80"},
+			measures:   `[{"metric": "new_coverage", "history": [{"date": "2024-05-03T08:00:00+0000", "value": "95"}]}]`,
+			want: []MetricThresholdResult{
+				{Threshold: "new_coverage>=80", Metric: "new_coverage", Passed: false},
+			},
+		},
+		{
+			name:       "a threshold on a metric without a measure is not met",
+			wantQuery:  "component=acme_shop&metrics=new_coverage&" + history,
+			thresholds: []string{"new_coverage>=80"},
+			measures:   `[{"metric": "new_coverage", "history": [{"date": "2024-05-02T10:15:00+0000"}]}]`,
+			want: []MetricThresholdResult{
+				{Threshold: "new_coverage>=80", Metric: "new_coverage", Passed: false},
+			},
+		},
+		{
+			name:           "a metric whose value is not a number is an error",
+			wantQuery:      "component=acme_shop&metrics=alert_status&" + history,
+			thresholds:     []string{"alert_status==1"},
+			measures:       `[{"metric": "alert_status", "history": [{"date": "2024-05-02T10:15:00+0000", "value": "OK"}]}]`,
+			wantErrorRegex: "^the value OK of metric alert_status is not a number$",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/api/measures/search_history", r.URL.Path)
+				require.Equal(t, tt.wantQuery, r.URL.RawQuery)
+				require.Equal(t, "Bearer some-token", r.Header.Get("Authorization"))
+				fmt.Fprintf(w, `{"paging": {"pageIndex": 1, "pageSize": 100, "total": 1}, "measures": %s}`, tt.measures)
+			}))
+			defer server.Close()
+
+			thresholds, err := ParseMetricThresholds(tt.thresholds)
+			require.NoError(t, err)
+			sc := NewSonarConfig("some-token", "", "", "", "", "")
+			sonarResults := &SonarResults{ServerUrl: server.URL, AnalaysedAt: analysedAt, Project: Project{Key: "acme_shop"}, Branch: tt.branch}
+
+			results, err := sc.EvaluateMetricThresholds(sonarResults, thresholds)
+			if tt.wantErrorRegex != "" {
+				require.Error(t, err)
+				require.Regexp(t, tt.wantErrorRegex, err.Error())
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, results)
+			}
+		})
+	}
+}
+
+func TestEvaluateMetricThresholdsReportsSonarErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": [{"msg": "The following metric keys are not found: new_covrage"}]}`)
+	}))
+	defer server.Close()
+
+	thresholds, err := ParseMetricThresholds([]string{"new_covrage>=80"})
+	require.NoError(t, err)
+	sc := NewSonarConfig("some-token", "", "", "", "", "")
+	_, err = sc.EvaluateMetricThresholds(&SonarResults{ServerUrl: server.URL, AnalaysedAt: "2024-05-02T10:15:00+0000",
+		Project: Project{Key: "acme_shop"}}, thresholds)
+	require.EqualError(t, err, "sonar error: The following metric keys are not found: new_covrage")
+}
+
+func TestEvaluateMetricThresholdsRequiresTheAnalysisDate(t *testing.T) {
+	thresholds, err := ParseMetricThresholds([]string{"new_coverage>=80"})
+	require.NoError(t, err)
+	sc := NewSonarConfig("some-token", "", "", "", "", "")
+	_, err = sc.EvaluateMetricThresholds(&SonarResults{ServerUrl: "http://localhost:9000", Project: Project{Key: "acme_shop"}}, thresholds)
+	require.EqualError(t, err, "the date of the analysis of project acme_shop is not known, so its measures cannot be retrieved")
+}
@@ -10,6 +10,7 @@ import (
 	billy "github.com/go-git/go-billy/v5"
 	git "github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/require"
 
 	"github.com/go-git/go-billy/v5/osfs"
@@ -80,6 +81,11 @@ func InitializeGitRepo(repoPath string) (*git.Repository, *git.Worktree, billy.F
 }
 
 func CommitToRepo(w *git.Worktree, fs billy.Filesystem, commitMessage string) (string, error) {
+	return CommitToRepoWithOptions(w, fs, commitMessage, &git.CommitOptions{})
+}
+
+// CommitToRepoWithOptions commits a new file with the given options, e.g. to sign the commit
+func CommitToRepoWithOptions(w *git.Worktree, fs billy.Filesystem, commitMessage string, options *git.CommitOptions) (string, error) {
 	filePath := fmt.Sprintf("file-%d.txt", time.Now().UnixNano())
 	newFile, err := fs.Create(filePath)
 	if err != nil {
@@ -97,7 +103,7 @@ func CommitToRepo(w *git.Worktree, fs billy.Filesystem, commitMessage string) (s
 	if err != nil {
 		return "", err
 	}
-	hash, err := w.Commit(commitMessage, &git.CommitOptions{})
+	hash, err := w.Commit(commitMessage, options)
 	if err != nil {
 		return "", err
 	}
@@ -105,6 +111,46 @@ func CommitToRepo(w *git.Worktree, fs billy.Filesystem, commitMessage string) (s
 	return hash.String(), nil
 }
 
+// CommitMergedSideBranch commits messages[0] on the current branch, messages[1] on a new side branch,
+// messages[2] on the current branch after it, and merges the side branch without fast-forward with messages[3].
+// The side branch commit is older than the commit after it, as when a branch is merged some time after it was
+// committed. It returns the SHAs of the four commits.
+func CommitMergedSideBranch(w *git.Worktree, fs billy.Filesystem, messages [4]string) ([]string, error) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(hours int) *git.CommitOptions {
+		signature := &object.Signature{Name: "Test", Email: "test@example.com", When: start.Add(time.Duration(hours) * time.Hour)}
+		return &git.CommitOptions{Author: signature, Committer: signature}
+	}
+
+	base, err := CommitToRepoWithOptions(w, fs, messages[0], at(0))
+	if err != nil {
+		return nil, err
+	}
+	err = CheckoutNewBranch(w, "side")
+	if err != nil {
+		return nil, err
+	}
+	side, err := CommitToRepoWithOptions(w, fs, messages[1], at(1))
+	if err != nil {
+		return nil, err
+	}
+	err = w.Checkout(&git.CheckoutOptions{Branch: plumbing.Master})
+	if err != nil {
+		return nil, err
+	}
+	after, err := CommitToRepoWithOptions(w, fs, messages[2], at(2))
+	if err != nil {
+		return nil, err
+	}
+	mergeOptions := at(3)
+	mergeOptions.Parents = []plumbing.Hash{plumbing.NewHash(after), plumbing.NewHash(side)}
+	merge, err := CommitToRepoWithOptions(w, fs, messages[3], mergeOptions)
+	if err != nil {
+		return nil, err
+	}
+	return []string{base, side, after, merge}, nil
+}
+
 func CheckoutNewBranch(w *git.Worktree, branchName string) error {
 	return w.Checkout(&git.CheckoutOptions{
 		Create: true,
@@ -0,0 +1,64 @@
+package testresults
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+type cucumberFeature struct {
+	Name     string `json:"name"`
+	URI      string `json:"uri"`
+	Elements []struct {
+		Name  string `json:"name"`
+		Type  string `json:"type"`
+		Line  int    `json:"line"`
+		Steps []struct {
+			Result struct {
+				Status   string `json:"status"`
+				Duration int64  `json:"duration"`
+			} `json:"result"`
+		} `json:"steps"`
+	} `json:"elements"`
+}
+
+// parseCucumber reads Cucumber JSON reports. Each feature is a suite and each
+// scenario a test. A scenario fails when a step failed or is ambiguous, and is
+// skipped when a step is skipped, pending or undefined.
+func parseCucumber(content []byte) ([]testCase, error) {
+	// other JSON files found in result directories (e.g. package.json) are ignored
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		return nil, nil
+	}
+	var features []cucumberFeature
+	if err := json.Unmarshal(content, &features); err != nil {
+		return nil, err
+	}
+	cases := []testCase{}
+	for _, feature := range features {
+		suite := feature.Name
+		if suite == "" {
+			suite = feature.URI
+		}
+		for _, element := range feature.Elements {
+			if element.Type == "background" {
+				continue
+			}
+			c := testCase{suite: suite, name: element.Name, status: StatusPassed}
+			for _, step := range element.Steps {
+				// durations are in nanoseconds
+				c.duration += time.Duration(step.Result.Duration)
+				switch step.Result.Status {
+				case "failed", "ambiguous":
+					c.status = StatusFailed
+				case "skipped", "pending", "undefined":
+					if c.status == StatusPassed {
+						c.status = StatusSkipped
+					}
+				}
+			}
+			cases = append(cases, c)
+		}
+	}
+	return cases, nil
+}
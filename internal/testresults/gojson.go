@@ -0,0 +1,61 @@
+package testresults
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+type goTestEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+}
+
+// parseGoJSON reads the output of 'go test -json'. Each package is a suite.
+// A package which fails without any failed test (e.g. a build failure) is an error.
+func parseGoJSON(content []byte) ([]testCase, error) {
+	cases := []testCase{}
+	started := map[string]time.Time{}
+	failedTests := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		// go test -json passes through lines which are not events, e.g. build output,
+		// and files which are not go test output are ignored in result directories
+		var event goTestEvent
+		if err := json.Unmarshal(bytes.TrimSpace(scanner.Bytes()), &event); err != nil || event.Action == "" {
+			continue
+		}
+		key := event.Package + "\x00" + event.Test
+		switch event.Action {
+		case "run":
+			started[key] = event.Time
+		case "pass", "fail", "skip":
+			if event.Test == "" {
+				if event.Action == "fail" && !failedTests[event.Package] {
+					cases = append(cases, testCase{suite: event.Package, name: "(package)", status: StatusError, timestamp: event.Time})
+				}
+				continue
+			}
+			status := map[string]string{"pass": StatusPassed, "fail": StatusFailed, "skip": StatusSkipped}[event.Action]
+			if status == StatusFailed {
+				failedTests[event.Package] = true
+			}
+			cases = append(cases, testCase{
+				suite:     event.Package,
+				name:      event.Test,
+				status:    status,
+				duration:  time.Duration(event.Elapsed * float64(time.Second)),
+				timestamp: started[key],
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
@@ -0,0 +1,33 @@
+package testresults
+
+import (
+	"github.com/joshdk/go-junit"
+)
+
+func parseJunit(content []byte) ([]testCase, error) {
+	suites, err := junit.Ingest(content)
+	if err != nil {
+		return nil, err
+	}
+	cases := []testCase{}
+	var collect func(suites []junit.Suite)
+	collect = func(suites []junit.Suite) {
+		for _, suite := range suites {
+			for _, test := range suite.Tests {
+				name := test.Name
+				if test.Classname != "" {
+					name = test.Classname + "." + test.Name
+				}
+				cases = append(cases, testCase{
+					suite:    suite.Name,
+					name:     name,
+					status:   string(test.Status),
+					duration: test.Duration,
+				})
+			}
+			collect(suite.Suites)
+		}
+	}
+	collect(suites)
+	return cases, nil
+}
@@ -0,0 +1,68 @@
+package testresults
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	tapPlan   = regexp.MustCompile(`^1\.\.(\d+)`)
+	tapResult = regexp.MustCompile(`^(not ok|ok)\b\s*(\d+)?\s*(?:-\s*)?([^#]*?)\s*(?:#\s*(\S+)\s*(.*))?$`)
+)
+
+// parseTap reads a TAP (Test Anything Protocol) stream. Subtests and YAML
+// diagnostics are indented and ignored. Tests marked TODO or SKIP are skipped.
+// Tests missing from the plan, or not run after a 'Bail out!', are errors.
+func parseTap(content []byte, suite string) ([]testCase, error) {
+	cases := []testCase{}
+	planned := -1
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "Bail out!") {
+			break
+		}
+		if match := tapPlan.FindStringSubmatch(line); match != nil {
+			planned, _ = strconv.Atoi(match[1])
+			continue
+		}
+		match := tapResult.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		number := len(cases) + 1
+		if match[2] != "" {
+			number, _ = strconv.Atoi(match[2])
+		}
+		name := match[3]
+		if name == "" {
+			name = fmt.Sprintf("test %d", number)
+		}
+		c := testCase{suite: suite, name: name, status: StatusPassed}
+		directive := strings.ToUpper(match[4])
+		switch {
+		case strings.HasPrefix(directive, "SKIP"), strings.HasPrefix(directive, "TODO"):
+			c.status = StatusSkipped
+		case match[1] == "not ok":
+			c.status = StatusFailed
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if planned < 0 && len(cases) == 0 {
+		return nil, fmt.Errorf("no TAP plan or test lines found")
+	}
+	for number := len(cases) + 1; number <= planned; number++ {
+		cases = append(cases, testCase{suite: suite, name: fmt.Sprintf("test %d (not run)", number), status: StatusError})
+	}
+	return cases, nil
+}
@@ -0,0 +1,292 @@
+package testresults
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	FormatJunit    = "junit"
+	FormatTrx      = "trx"
+	FormatTap      = "tap"
+	FormatGoJSON   = "go-json"
+	FormatCucumber = "cucumber"
+
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusError   = "error"
+	StatusSkipped = "skipped"
+)
+
+var AllowedFormats = []string{FormatJunit, FormatTrx, FormatTap, FormatGoJSON, FormatCucumber}
+
+// extensions are the file extensions read from result directories, per format
+var extensions = map[string][]string{
+	FormatJunit:    {".xml"},
+	FormatTrx:      {".trx"},
+	FormatTap:      {".tap"},
+	FormatGoJSON:   {".json", ".jsonl"},
+	FormatCucumber: {".json"},
+}
+
+// Suite has the same shape as the suites of JUnit attestations
+type Suite struct {
+	Name      string  `json:"name"`
+	Failures  int     `json:"failures"`
+	Errors    int     `json:"errors"`
+	Skipped   int     `json:"skipped"`
+	Total     int     `json:"total"`
+	Duration  float64 `json:"duration"`
+	Timestamp float64 `json:"timestamp,omitempty"`
+}
+
+// FlakyTest is a test which failed before passing on a retry
+type FlakyTest struct {
+	Suite    string `json:"suite"`
+	Name     string `json:"name"`
+	Attempts int    `json:"attempts"`
+}
+
+type Totals struct {
+	Total    int `json:"total"`
+	Passed   int `json:"passed"`
+	Failures int `json:"failures"`
+	Errors   int `json:"errors"`
+	Skipped  int `json:"skipped"`
+	Flaky    int `json:"flaky"`
+}
+
+// Thresholds decide the compliance of test results. A nil MaxSkipped is not checked.
+type Thresholds struct {
+	MaxFailures int  `json:"max_failures"`
+	MaxSkipped  *int `json:"max_skipped,omitempty"`
+}
+
+type TestResultsData struct {
+	SchemaVersion int         `json:"schema_version"`
+	Format        string      `json:"format"`
+	Totals        Totals      `json:"totals"`
+	Suites        []*Suite    `json:"suites"`
+	Flaky         []FlakyTest `json:"flaky,omitempty"`
+	Thresholds    Thresholds  `json:"thresholds"`
+	Violations    []string    `json:"violations,omitempty"`
+	Files         []string    `json:"-"`
+}
+
+// testCase is one execution of a test, as read from a results file
+type testCase struct {
+	suite     string
+	name      string
+	status    string
+	duration  time.Duration
+	timestamp time.Time
+}
+
+// ValidateFormat checks that format is one of AllowedFormats
+func ValidateFormat(format string) error {
+	for _, allowed := range AllowedFormats {
+		if format == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not a valid test results format. Allowed formats are: %s", format, strings.Join(AllowedFormats, ", "))
+}
+
+// ProcessResults parses the test results of the given format found in paths.
+// A path can be a file or a directory, which is searched recursively for files
+// with the extensions of the format.
+// Tests which are reported more than once (retries) are counted once: a test which
+// passed on any attempt counts as passed, and is flagged as flaky if another attempt failed.
+func ProcessResults(paths []string, format string) (*TestResultsData, error) {
+	if err := ValidateFormat(format); err != nil {
+		return nil, err
+	}
+	files, err := resultFiles(paths, format)
+	if err != nil {
+		return nil, err
+	}
+
+	cases := []testCase{}
+	parsedFiles := []string{}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var fileCases []testCase
+		switch format {
+		case FormatJunit:
+			fileCases, err = parseJunit(content)
+		case FormatTrx:
+			fileCases, err = parseTrx(content)
+		case FormatTap:
+			fileCases, err = parseTap(content, strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+		case FormatGoJSON:
+			fileCases, err = parseGoJSON(content)
+		case FormatCucumber:
+			fileCases, err = parseCucumber(content)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s test results [%s]: %v", format, file, err)
+		}
+		if len(fileCases) > 0 {
+			cases = append(cases, fileCases...)
+			parsedFiles = append(parsedFiles, file)
+		}
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no %s test results found in %s", format, strings.Join(paths, ", "))
+	}
+
+	data := summarize(cases)
+	data.Format = format
+	data.Files = parsedFiles
+	return data, nil
+}
+
+// Evaluate checks the totals against the thresholds, records the violations
+// and returns whether the results are compliant. Errors count as failures.
+func (data *TestResultsData) Evaluate(thresholds Thresholds) bool {
+	data.Thresholds = thresholds
+	data.Violations = nil
+	failures := data.Totals.Failures + data.Totals.Errors
+	if failures > thresholds.MaxFailures {
+		data.Violations = append(data.Violations, fmt.Sprintf("%d failed test(s), the maximum is %d", failures, thresholds.MaxFailures))
+	}
+	if thresholds.MaxSkipped != nil && data.Totals.Skipped > *thresholds.MaxSkipped {
+		data.Violations = append(data.Violations, fmt.Sprintf("%d skipped test(s), the maximum is %d", data.Totals.Skipped, *thresholds.MaxSkipped))
+	}
+	return len(data.Violations) == 0
+}
+
+func resultFiles(paths []string, format string) ([]string, error) {
+	files := []string{}
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+		err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.Mode().IsRegular() && hasExtension(path, format) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func hasExtension(path, format string) bool {
+	for _, extension := range extensions[format] {
+		if strings.EqualFold(filepath.Ext(path), extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// summarize reconciles the attempts of each test and counts the tests per suite
+func summarize(cases []testCase) *TestResultsData {
+	type attempts struct {
+		suite, name string
+		statuses    []string
+		duration    time.Duration
+		timestamp   time.Time
+	}
+	tests := map[string]*attempts{}
+	order := []string{}
+	for _, c := range cases {
+		key := c.suite + "\x00" + c.name
+		test, ok := tests[key]
+		if !ok {
+			test = &attempts{suite: c.suite, name: c.name}
+			tests[key] = test
+			order = append(order, key)
+		}
+		test.statuses = append(test.statuses, c.status)
+		test.duration += c.duration
+		if !c.timestamp.IsZero() && (test.timestamp.IsZero() || c.timestamp.Before(test.timestamp)) {
+			test.timestamp = c.timestamp
+		}
+	}
+
+	data := &TestResultsData{SchemaVersion: 1, Suites: []*Suite{}}
+	suites := map[string]*Suite{}
+	for _, key := range order {
+		test := tests[key]
+		suite, ok := suites[test.suite]
+		if !ok {
+			suite = &Suite{Name: test.suite}
+			suites[test.suite] = suite
+			data.Suites = append(data.Suites, suite)
+		}
+		suite.Total++
+		suite.Duration += test.duration.Seconds()
+		if !test.timestamp.IsZero() {
+			timestamp := float64(test.timestamp.UTC().Unix())
+			if suite.Timestamp == 0 || timestamp < suite.Timestamp {
+				suite.Timestamp = timestamp
+			}
+		}
+
+		switch status := reconcile(test.statuses); status {
+		case StatusPassed:
+			data.Totals.Passed++
+			if len(test.statuses) > 1 && (contains(test.statuses, StatusFailed) || contains(test.statuses, StatusError)) {
+				data.Flaky = append(data.Flaky, FlakyTest{Suite: test.suite, Name: test.name, Attempts: len(test.statuses)})
+			}
+		case StatusFailed:
+			suite.Failures++
+			data.Totals.Failures++
+		case StatusError:
+			suite.Errors++
+			data.Totals.Errors++
+		case StatusSkipped:
+			suite.Skipped++
+			data.Totals.Skipped++
+		}
+	}
+	for _, suite := range data.Suites {
+		suite.Duration = math.Round(suite.Duration*1000) / 1000
+	}
+	sort.SliceStable(data.Suites, func(i, j int) bool {
+		return data.Suites[i].Name < data.Suites[j].Name
+	})
+	data.Totals.Total = len(order)
+	data.Totals.Flaky = len(data.Flaky)
+	return data
+}
+
+// reconcile decides the status of a test from the statuses of its attempts
+func reconcile(statuses []string) string {
+	for _, status := range []string{StatusPassed, StatusFailed, StatusError} {
+		if contains(statuses, status) {
+			return status
+		}
+	}
+	return StatusSkipped
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
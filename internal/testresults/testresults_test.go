@@ -0,0 +1,184 @@
+package testresults
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessResults(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   []string
+		format  string
+		want    *TestResultsData
+		wantErr string
+	}{
+		{
+			name:   "junit results are read recursively from a directory and retries are reconciled",
+			paths:  []string{"testdata/junit"},
+			format: FormatJunit,
+			want: &TestResultsData{
+				SchemaVersion: 1,
+				Format:        FormatJunit,
+				Totals:        Totals{Total: 5, Passed: 2, Failures: 1, Errors: 1, Skipped: 1, Flaky: 1},
+				Suites: []*Suite{
+					{Name: "integration", Failures: 1, Errors: 1, Total: 2, Duration: 3},
+					{Name: "unit", Skipped: 1, Total: 3, Duration: 1},
+				},
+				Flaky: []FlakyTest{{Suite: "unit", Name: "app.Calc.divides", Attempts: 2}},
+				Files: []string{"testdata/junit/nested/integration.xml", "testdata/junit/results.xml"},
+			},
+		},
+		{
+			name:   "results are combined from several files and directories",
+			paths:  []string{"testdata/junit/results.xml", "testdata/junit/nested"},
+			format: FormatJunit,
+			want: &TestResultsData{
+				SchemaVersion: 1,
+				Format:        FormatJunit,
+				Totals:        Totals{Total: 5, Passed: 2, Failures: 1, Errors: 1, Skipped: 1, Flaky: 1},
+				Suites: []*Suite{
+					{Name: "integration", Failures: 1, Errors: 1, Total: 2, Duration: 3},
+					{Name: "unit", Skipped: 1, Total: 3, Duration: 1},
+				},
+				Flaky: []FlakyTest{{Suite: "unit", Name: "app.Calc.divides", Attempts: 2}},
+				Files: []string{"testdata/junit/results.xml", "testdata/junit/nested/integration.xml"},
+			},
+		},
+		{
+			name:   "trx results are grouped by test class",
+			paths:  []string{"testdata"},
+			format: FormatTrx,
+			want: &TestResultsData{
+				SchemaVersion: 1,
+				Format:        FormatTrx,
+				Totals:        Totals{Total: 4, Passed: 2, Errors: 1, Skipped: 1, Flaky: 1},
+				Suites: []*Suite{
+					{Name: "App.Tests.CalcTests", Skipped: 1, Total: 3, Duration: 2.5, Timestamp: 1714557601},
+					{Name: "App.Tests.DbTests", Errors: 1, Total: 1, Duration: 60, Timestamp: 1714557604},
+				},
+				Flaky: []FlakyTest{{Suite: "App.Tests.CalcTests", Name: "Divides", Attempts: 2}},
+				Files: []string{"testdata/results.trx"},
+			},
+		},
+		{
+			name:   "tap results skip TODO tests and count tests not run after bailing out as errors",
+			paths:  []string{"testdata/results.tap"},
+			format: FormatTap,
+			want: &TestResultsData{
+				SchemaVersion: 1,
+				Format:        FormatTap,
+				Totals:        Totals{Total: 6, Passed: 2, Failures: 1, Errors: 1, Skipped: 2},
+				Suites: []*Suite{
+					{Name: "results", Failures: 1, Errors: 1, Skipped: 2, Total: 6},
+				},
+				Files: []string{"testdata/results.tap"},
+			},
+		},
+		{
+			name:   "go test -json results count reruns once and packages failing to build as errors",
+			paths:  []string{"testdata/go"},
+			format: FormatGoJSON,
+			want: &TestResultsData{
+				SchemaVersion: 1,
+				Format:        FormatGoJSON,
+				Totals:        Totals{Total: 4, Passed: 2, Errors: 1, Skipped: 1, Flaky: 1},
+				Suites: []*Suite{
+					{Name: "example.com/app", Skipped: 1, Total: 3, Duration: 2, Timestamp: 1714557600},
+					{Name: "example.com/broken", Errors: 1, Total: 1, Timestamp: 1714557604},
+				},
+				Flaky: []FlakyTest{{Suite: "example.com/app", Name: "TestDivide", Attempts: 2}},
+				Files: []string{"testdata/go/test.json"},
+			},
+		},
+		{
+			name:   "cucumber scenarios are tests of their feature and other JSON files are ignored",
+			paths:  []string{"testdata/cucumber"},
+			format: FormatCucumber,
+			want: &TestResultsData{
+				SchemaVersion: 1,
+				Format:        FormatCucumber,
+				Totals:        Totals{Total: 4, Passed: 2, Failures: 1, Skipped: 1, Flaky: 1},
+				Suites: []*Suite{
+					{Name: "Login", Total: 2, Duration: 1.5},
+					{Name: "Logout", Failures: 1, Skipped: 1, Total: 2, Duration: 1},
+				},
+				Flaky: []FlakyTest{{Suite: "Login", Name: "invalid credentials", Attempts: 2}},
+				Files: []string{"testdata/cucumber/cucumber.json"},
+			},
+		},
+		{
+			name:    "invalid format causes an error",
+			paths:   []string{"testdata"},
+			format:  "xunit",
+			wantErr: "xunit is not a valid test results format. Allowed formats are: junit, trx, tap, go-json, cucumber",
+		},
+		{
+			name:    "invalid results file causes an error",
+			paths:   []string{"testdata/invalid.json"},
+			format:  FormatCucumber,
+			wantErr: "failed to parse cucumber test results [testdata/invalid.json]",
+		},
+		{
+			name:    "no results causes an error",
+			paths:   []string{"testdata/junit"},
+			format:  FormatTrx,
+			wantErr: "no trx test results found in testdata/junit",
+		},
+		{
+			name:    "missing path causes an error",
+			paths:   []string{"testdata/missing"},
+			format:  FormatJunit,
+			wantErr: "no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ProcessResults(tt.paths, tt.format)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, data)
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		name       string
+		thresholds Thresholds
+		want       bool
+		violations []string
+	}{
+		{
+			name:       "failures and errors above the maximum are not compliant",
+			thresholds: Thresholds{MaxFailures: 1},
+			violations: []string{"2 failed test(s), the maximum is 1"},
+		},
+		{
+			name:       "failures within the maximum are compliant and skipped tests are not checked by default",
+			thresholds: Thresholds{MaxFailures: 2},
+			want:       true,
+		},
+		{
+			name:       "skipped tests above the maximum are not compliant",
+			thresholds: Thresholds{MaxFailures: 2, MaxSkipped: &zero},
+			violations: []string{"1 skipped test(s), the maximum is 0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ProcessResults([]string{"testdata/junit"}, FormatJunit)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, data.Evaluate(tt.thresholds))
+			assert.Equal(t, tt.violations, data.Violations)
+			assert.Equal(t, tt.thresholds, data.Thresholds)
+		})
+	}
+}
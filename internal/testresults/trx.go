@@ -0,0 +1,91 @@
+package testresults
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type trxRun struct {
+	XMLName xml.Name `xml:"TestRun"`
+	Results []struct {
+		TestID    string `xml:"testId,attr"`
+		TestName  string `xml:"testName,attr"`
+		Outcome   string `xml:"outcome,attr"`
+		Duration  string `xml:"duration,attr"`
+		StartTime string `xml:"startTime,attr"`
+	} `xml:"Results>UnitTestResult"`
+	Definitions []struct {
+		ID     string `xml:"id,attr"`
+		Method struct {
+			ClassName string `xml:"className,attr"`
+		} `xml:"TestMethod"`
+	} `xml:"TestDefinitions>UnitTest"`
+}
+
+// parseTrx reads Visual Studio test results (e.g. from 'dotnet test --logger trx').
+// Tests are grouped in suites by their class name.
+func parseTrx(content []byte) ([]testCase, error) {
+	var run trxRun
+	if err := xml.Unmarshal(content, &run); err != nil {
+		return nil, err
+	}
+	classNames := map[string]string{}
+	for _, definition := range run.Definitions {
+		classNames[definition.ID] = definition.Method.ClassName
+	}
+
+	cases := []testCase{}
+	for _, result := range run.Results {
+		c := testCase{
+			suite: classNames[result.TestID],
+			name:  result.TestName,
+		}
+		switch result.Outcome {
+		case "Passed", "PassedButRunAborted", "Warning":
+			c.status = StatusPassed
+		case "Failed":
+			c.status = StatusFailed
+		case "NotExecuted", "Inconclusive", "NotRunnable", "Disconnected", "Pending", "InProgress":
+			c.status = StatusSkipped
+		default:
+			// Error, Timeout, Aborted
+			c.status = StatusError
+		}
+		if result.Duration != "" {
+			duration, err := parseTrxDuration(result.Duration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid duration %q of test %s", result.Duration, result.TestName)
+			}
+			c.duration = duration
+		}
+		if result.StartTime != "" {
+			c.timestamp, _ = time.Parse(time.RFC3339Nano, result.StartTime)
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// parseTrxDuration parses durations of the form hh:mm:ss.fffffff
+func parseTrxDuration(value string) (time.Duration, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid duration")
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, err
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), nil
+}
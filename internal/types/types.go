@@ -1,15 +1,258 @@
 package types
 
+import (
+	"fmt"
+	"strings"
+)
+
+// Review states, normalized across git providers
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
+	ReviewCommented        = "COMMENTED"
+	ReviewDismissed        = "DISMISSED"
+	ReviewUnapproved       = "UNAPPROVED"
+)
+
 type PREvidence struct {
 	MergeCommit string   `json:"merge_commit"`
 	URL         string   `json:"url"`
 	State       string   `json:"state"`
 	Approvers   []string `json:"approvers"`
-	// LastCommit             string `json:"lastCommit"`
-	// LastCommitter          string `json:"lastCommitter"`
-	// SelfApproved           bool   `json:"selfApproved"`
+	// Author is the user who opened the pull request
+	Author string `json:"author"`
+	// CommitAuthors are the distinct authors of the commits in the pull request
+	CommitAuthors []string `json:"commit_authors"`
+	// UnresolvedCommitAuthors are the git names of commit authors who could not be matched to a provider account
+	UnresolvedCommitAuthors []string   `json:"unresolved_commit_authors,omitempty"`
+	Reviews                 []PRReview `json:"reviews"`
+	// LastCommit is the head commit of the pull request before it was merged
+	LastCommit *PRCommit `json:"last_commit,omitempty"`
+	// ApprovedAfterLastCommit is true when an approval given on the last commit is still standing
+	ApprovedAfterLastCommit bool `json:"approved_after_last_commit"`
+	// SelfApproved is true when the pull request author or a commit author approved it
+	SelfApproved bool `json:"self_approved"`
+	// IndependentApproval is only set when independent approval is required
+	IndependentApproval *IndependentApproval `json:"independent_approval,omitempty"`
+}
+
+// IndependentApproval is the outcome of checking that a pull request was approved by
+// enough reviewers who are neither its author nor a commit author, after its last commit
+type IndependentApproval struct {
+	Required  int      `json:"required"`
+	Approvers []string `json:"approvers"`
+	Compliant bool     `json:"compliant"`
+	Reason    string   `json:"reason"`
+}
+
+// PRReview is one review decision on a pull request
+type PRReview struct {
+	Reviewer string `json:"reviewer"`
+	State    string `json:"state"`
+	// Timestamp is in unix seconds, 0 when the provider does not record it
+	Timestamp int64 `json:"timestamp"`
+	// CommitSha1 is the head commit the review was given on, empty when the provider does not record it
+	CommitSha1 string `json:"commit_sha1,omitempty"`
+	// AfterLastCommit is nil when it is unknown whether the review was given on the last commit
+	AfterLastCommit *bool `json:"after_last_commit"`
+}
+
+// PRCommit is a commit in a pull request
+type PRCommit struct {
+	Sha1 string `json:"sha1"`
+	// Author is the provider account of the commit author, or the git author name when AuthorUnresolved
+	Author           string `json:"author"`
+	AuthorUnresolved bool   `json:"author_unresolved,omitempty"`
+	// Timestamp is the committer date, which is set by whoever made the commit
+	Timestamp int64 `json:"timestamp"`
+	// PushedAt is when the provider recorded the commit as the head of the pull request, 0 when unknown
+	PushedAt int64 `json:"pushed_at,omitempty"`
 }
 
 type PRRetriever interface {
 	PREvidenceForCommit(string) ([]*PREvidence, error)
 }
+
+// ReviewHistoryRetriever is a PRRetriever which only gets the review history of pull requests, e.g. the
+// push times and the accounts of commit authors needed to evaluate independent approval, once it is enabled.
+// Getting it takes more API calls, and permissions the token may not have.
+type ReviewHistoryRetriever interface {
+	EnableReviewHistory()
+}
+
+// IssueInfo is the outcome of looking up an issue reference in an issue tracker
+type IssueInfo struct {
+	IssueID     string `json:"issue_id"`
+	IssueURL    string `json:"issue_url"`
+	IssueExists bool   `json:"issue_exists"`
+	// IsPullRequest is set when the reference is to a pull request rather than an issue
+	IsPullRequest bool `json:"-"`
+	// Status is the state of the issue as named by the tracker, e.g. "closed" or "In Progress"
+	Status      string                 `json:"status,omitempty"`
+	IssueFields map[string]interface{} `json:"issue_fields,omitempty"`
+}
+
+// IssueTracker looks up the issues referenced in commit messages and branch names
+type IssueTracker interface {
+	// ReferencePattern is the regular expression matching issue references, e.g. "#12" or "ABC-12"
+	ReferencePattern() string
+	// GetIssueInfo looks up a referenced issue. An issue that does not exist is not an error.
+	GetIssueInfo(reference string) (*IssueInfo, error)
+}
+
+// Summarize records the commits of the pull request and classifies its reviews against
+// the last commit, which is headSha1 when it is among commits and unknown otherwise.
+// Committer dates can be set to anything by the committer, so a review is only placed after
+// the last commit by the commit it was given on, or by when the provider recorded the push.
+// Author and Reviews must be set before calling it.
+func (e *PREvidence) Summarize(commits []PRCommit, headSha1 string) {
+	e.CommitAuthors = []string{}
+	e.UnresolvedCommitAuthors = nil
+	e.LastCommit = nil
+	for i, commit := range commits {
+		switch {
+		case commit.AuthorUnresolved:
+			if !containsIdentity(e.UnresolvedCommitAuthors, commit.Author) {
+				e.UnresolvedCommitAuthors = append(e.UnresolvedCommitAuthors, commit.Author)
+			}
+		case commit.Author != "" && !containsIdentity(e.CommitAuthors, commit.Author):
+			e.CommitAuthors = append(e.CommitAuthors, commit.Author)
+		}
+		if commit.Sha1 == headSha1 && headSha1 != "" {
+			e.LastCommit = &commits[i]
+		}
+	}
+
+	if e.Reviews == nil {
+		e.Reviews = []PRReview{}
+	}
+	for i := range e.Reviews {
+		e.Reviews[i].AfterLastCommit = e.afterLastCommit(e.Reviews[i])
+	}
+
+	e.ApprovedAfterLastCommit = len(e.ApprovalsAfterLastCommit()) > 0
+	e.SelfApproved = false
+	for _, reviewer := range e.standingApprovers() {
+		if EqualIdentity(reviewer, e.Author) || containsIdentity(e.CommitAuthors, reviewer) {
+			e.SelfApproved = true
+		}
+	}
+}
+
+// afterLastCommit places a review against the last commit, returning nil when it cannot be placed
+func (e *PREvidence) afterLastCommit(review PRReview) *bool {
+	if e.LastCommit == nil {
+		return nil
+	}
+	after := false
+	switch {
+	case review.CommitSha1 != "":
+		after = review.CommitSha1 == e.LastCommit.Sha1
+	case review.Timestamp > 0 && e.LastCommit.PushedAt > 0:
+		after = review.Timestamp >= e.LastCommit.PushedAt
+	default:
+		return nil
+	}
+	return &after
+}
+
+// ApprovalsAfterLastCommit returns the reviewers whose standing approval was given on the last commit
+func (e *PREvidence) ApprovalsAfterLastCommit() []string {
+	reviewers := []string{}
+	latest := e.latestDecisions()
+	for _, reviewer := range e.standingApprovers() {
+		if after := latest[strings.ToLower(reviewer)].AfterLastCommit; after != nil && *after {
+			reviewers = append(reviewers, reviewer)
+		}
+	}
+	return reviewers
+}
+
+// EvaluateIndependentApproval checks that at least required reviewers, distinct from the pull request
+// author and from every commit author, have a standing approval given on the last commit.
+// It fails when a commit author is not matched to a provider account, since that author could be any reviewer.
+// Summarize must be called first.
+func (e *PREvidence) EvaluateIndependentApproval(required int) *IndependentApproval {
+	result := &IndependentApproval{Required: required, Approvers: []string{}}
+	excluded := []string{}
+	latest := e.latestDecisions()
+	for _, reviewer := range e.standingApprovers() {
+		after := latest[strings.ToLower(reviewer)].AfterLastCommit
+		switch {
+		case EqualIdentity(reviewer, e.Author):
+			excluded = append(excluded, fmt.Sprintf("%s (pull request author)", reviewer))
+		case containsIdentity(e.CommitAuthors, reviewer):
+			excluded = append(excluded, fmt.Sprintf("%s (commit author)", reviewer))
+		case e.LastCommit == nil:
+			excluded = append(excluded, fmt.Sprintf("%s (last commit unknown)", reviewer))
+		case after == nil:
+			excluded = append(excluded, fmt.Sprintf("%s (unknown whether approved after the last commit)", reviewer))
+		case !*after:
+			excluded = append(excluded, fmt.Sprintf("%s (approved before the last commit)", reviewer))
+		default:
+			result.Approvers = append(result.Approvers, reviewer)
+		}
+	}
+	result.Compliant = len(result.Approvers) >= required && len(e.UnresolvedCommitAuthors) == 0
+
+	reason := fmt.Sprintf("%d of %d required independent approval(s) after the last commit", len(result.Approvers), required)
+	if e.LastCommit == nil {
+		reason = fmt.Sprintf("%d of %d required independent approval(s), the last commit is unknown", len(result.Approvers), required)
+	}
+	if len(result.Approvers) > 0 {
+		reason += fmt.Sprintf(": %s", strings.Join(result.Approvers, ", "))
+	}
+	if len(excluded) > 0 {
+		reason += fmt.Sprintf("; not counted: %s", strings.Join(excluded, ", "))
+	}
+	if len(e.UnresolvedCommitAuthors) > 0 {
+		reason += fmt.Sprintf("; independence cannot be verified, commit author(s) not matched to an account: %s",
+			strings.Join(e.UnresolvedCommitAuthors, ", "))
+	}
+	result.Reason = reason
+	return result
+}
+
+// standingApprovers returns the reviewers whose latest decision is an approval
+func (e *PREvidence) standingApprovers() []string {
+	reviewers := []string{}
+	latest := e.latestDecisions()
+	for _, review := range e.Reviews {
+		decision, ok := latest[strings.ToLower(review.Reviewer)]
+		if ok && decision.State == ReviewApproved && !containsIdentity(reviewers, review.Reviewer) {
+			reviewers = append(reviewers, review.Reviewer)
+		}
+	}
+	return reviewers
+}
+
+// latestDecisions returns the latest review of each reviewer, ignoring comments.
+// Reviews without timestamps are taken in the order they are listed.
+func (e *PREvidence) latestDecisions() map[string]PRReview {
+	latest := map[string]PRReview{}
+	for _, review := range e.Reviews {
+		if review.State == ReviewCommented {
+			continue
+		}
+		key := strings.ToLower(review.Reviewer)
+		previous, ok := latest[key]
+		if !ok || review.Timestamp >= previous.Timestamp {
+			latest[key] = review
+		}
+	}
+	return latest
+}
+
+// EqualIdentity compares two user identities from a git provider, ignoring case
+func EqualIdentity(a, b string) bool {
+	return a != "" && strings.EqualFold(a, b)
+}
+
+func containsIdentity(identities []string, identity string) bool {
+	for _, i := range identities {
+		if EqualIdentity(i, identity) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,312 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	// c2 has the latest committer date, but c3 was pushed last
+	commits := []PRCommit{
+		{Sha1: "c1", Author: "alice", Timestamp: 100},
+		{Sha1: "c2", Author: "Bob", Timestamp: 300},
+		{Sha1: "c3", Author: "alice", Timestamp: 200, PushedAt: 260},
+	}
+	tests := []struct {
+		name                    string
+		author                  string
+		reviews                 []PRReview
+		commits                 []PRCommit
+		headSha1                string
+		wantCommitAuthors       []string
+		wantUnresolvedAuthors   []string
+		wantLastCommit          string
+		wantAfterLastCommit     []*bool
+		wantApprovals           []string
+		wantApprovedAfterCommit bool
+		wantSelfApproved        bool
+	}{
+		{
+			name:                    "a review given on the head commit is after the last commit",
+			author:                  "alice",
+			reviews:                 []PRReview{{Reviewer: "carol", State: ReviewApproved, Timestamp: 250, CommitSha1: "c3"}},
+			commits:                 commits,
+			headSha1:                "c3",
+			wantCommitAuthors:       []string{"alice", "Bob"},
+			wantLastCommit:          "c3",
+			wantAfterLastCommit:     []*bool{boolPtr(true)},
+			wantApprovals:           []string{"carol"},
+			wantApprovedAfterCommit: true,
+		},
+		{
+			name:                "a review given on an earlier commit is before the last commit, whatever its time",
+			author:              "alice",
+			reviews:             []PRReview{{Reviewer: "carol", State: ReviewApproved, Timestamp: 900, CommitSha1: "c2"}},
+			commits:             commits,
+			headSha1:            "c3",
+			wantCommitAuthors:   []string{"alice", "Bob"},
+			wantLastCommit:      "c3",
+			wantAfterLastCommit: []*bool{boolPtr(false)},
+			wantApprovals:       []string{},
+		},
+		{
+			name:   "without the review commit, reviews are placed against the push of the last commit",
+			author: "alice",
+			reviews: []PRReview{
+				{Reviewer: "carol", State: ReviewApproved, Timestamp: 250},
+				{Reviewer: "dave", State: ReviewApproved, Timestamp: 270},
+			},
+			commits:                 commits,
+			headSha1:                "c3",
+			wantCommitAuthors:       []string{"alice", "Bob"},
+			wantLastCommit:          "c3",
+			wantAfterLastCommit:     []*bool{boolPtr(false), boolPtr(true)},
+			wantApprovals:           []string{"dave"},
+			wantApprovedAfterCommit: true,
+		},
+		{
+			name:                "committer dates are not used to place reviews",
+			author:              "alice",
+			reviews:             []PRReview{{Reviewer: "carol", State: ReviewApproved, Timestamp: 400}},
+			commits:             commits,
+			headSha1:            "c2",
+			wantCommitAuthors:   []string{"alice", "Bob"},
+			wantLastCommit:      "c2",
+			wantAfterLastCommit: []*bool{nil},
+			wantApprovals:       []string{},
+		},
+		{
+			name:                "without a known head, the last commit is unknown",
+			author:              "alice",
+			reviews:             []PRReview{{Reviewer: "carol", State: ReviewApproved, Timestamp: 250, CommitSha1: "c3"}},
+			commits:             commits,
+			wantCommitAuthors:   []string{"alice", "Bob"},
+			wantAfterLastCommit: []*bool{nil},
+			wantApprovals:       []string{},
+		},
+		{
+			name:   "a later unapproval withdraws an approval",
+			author: "alice",
+			reviews: []PRReview{
+				{Reviewer: "carol", State: ReviewApproved, Timestamp: 400, CommitSha1: "c3"},
+				{Reviewer: "carol", State: ReviewUnapproved, Timestamp: 500, CommitSha1: "c3"},
+				{Reviewer: "dave", State: ReviewCommented, Timestamp: 600, CommitSha1: "c3"},
+			},
+			commits:             commits,
+			headSha1:            "c3",
+			wantCommitAuthors:   []string{"alice", "Bob"},
+			wantLastCommit:      "c3",
+			wantAfterLastCommit: []*bool{boolPtr(true), boolPtr(true), boolPtr(true)},
+			wantApprovals:       []string{},
+		},
+		{
+			name:   "a comment does not withdraw an approval, and commit authors approving is self-approval",
+			author: "alice",
+			reviews: []PRReview{
+				{Reviewer: "bob", State: ReviewApproved, Timestamp: 400, CommitSha1: "c3"},
+				{Reviewer: "bob", State: ReviewCommented, Timestamp: 500, CommitSha1: "c3"},
+			},
+			commits:                 commits,
+			headSha1:                "c3",
+			wantCommitAuthors:       []string{"alice", "Bob"},
+			wantLastCommit:          "c3",
+			wantAfterLastCommit:     []*bool{boolPtr(true), boolPtr(true)},
+			wantApprovals:           []string{"bob"},
+			wantApprovedAfterCommit: true,
+			wantSelfApproved:        true,
+		},
+		{
+			name:                "the pull request author approving is self-approval",
+			author:              "erin",
+			reviews:             []PRReview{{Reviewer: "erin", State: ReviewApproved, Timestamp: 50}},
+			commits:             commits,
+			headSha1:            "c3",
+			wantCommitAuthors:   []string{"alice", "Bob"},
+			wantLastCommit:      "c3",
+			wantAfterLastCommit: []*bool{boolPtr(false)},
+			wantApprovals:       []string{},
+			wantSelfApproved:    true,
+		},
+		{
+			name:                "reviews without a commit or timestamp cannot be placed",
+			author:              "alice",
+			reviews:             []PRReview{{Reviewer: "carol", State: ReviewApproved}},
+			commits:             commits,
+			headSha1:            "c3",
+			wantCommitAuthors:   []string{"alice", "Bob"},
+			wantLastCommit:      "c3",
+			wantAfterLastCommit: []*bool{nil},
+			wantApprovals:       []string{},
+		},
+		{
+			name:   "commit authors without an account are kept apart",
+			author: "alice",
+			reviews: []PRReview{
+				{Reviewer: "bob", State: ReviewApproved, CommitSha1: "c2"},
+			},
+			commits: []PRCommit{
+				{Sha1: "c1", Author: "alice"},
+				{Sha1: "c2", Author: "Bob Jones", AuthorUnresolved: true},
+			},
+			headSha1:                "c2",
+			wantCommitAuthors:       []string{"alice"},
+			wantUnresolvedAuthors:   []string{"Bob Jones"},
+			wantLastCommit:          "c2",
+			wantAfterLastCommit:     []*bool{boolPtr(true)},
+			wantApprovals:           []string{"bob"},
+			wantApprovedAfterCommit: true,
+		},
+		{
+			name:                "no commits means no last commit",
+			author:              "alice",
+			reviews:             []PRReview{{Reviewer: "carol", State: ReviewApproved, Timestamp: 50}},
+			headSha1:            "c3",
+			wantCommitAuthors:   []string{},
+			wantAfterLastCommit: []*bool{nil},
+			wantApprovals:       []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evidence := &PREvidence{Author: tt.author, Reviews: tt.reviews}
+			evidence.Summarize(tt.commits, tt.headSha1)
+
+			assert.Equal(t, tt.wantCommitAuthors, evidence.CommitAuthors)
+			assert.Equal(t, tt.wantUnresolvedAuthors, evidence.UnresolvedCommitAuthors)
+			if tt.wantLastCommit == "" {
+				assert.Nil(t, evidence.LastCommit)
+			} else {
+				require.NotNil(t, evidence.LastCommit)
+				assert.Equal(t, tt.wantLastCommit, evidence.LastCommit.Sha1)
+			}
+			afterLastCommit := []*bool{}
+			for _, review := range evidence.Reviews {
+				afterLastCommit = append(afterLastCommit, review.AfterLastCommit)
+			}
+			assert.Equal(t, tt.wantAfterLastCommit, afterLastCommit)
+			assert.Equal(t, tt.wantApprovals, evidence.ApprovalsAfterLastCommit())
+			assert.Equal(t, tt.wantApprovedAfterCommit, evidence.ApprovedAfterLastCommit)
+			assert.Equal(t, tt.wantSelfApproved, evidence.SelfApproved)
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestEvaluateIndependentApproval(t *testing.T) {
+	commits := []PRCommit{
+		{Sha1: "c1", Author: "alice", Timestamp: 100},
+		{Sha1: "c2", Author: "bob", Timestamp: 300, PushedAt: 300},
+	}
+	tests := []struct {
+		name          string
+		reviews       []PRReview
+		commits       []PRCommit
+		headSha1      string
+		required      int
+		wantApprovers []string
+		wantCompliant bool
+		wantReason    string
+	}{
+		{
+			name: "independent approvals after the last commit are counted",
+			reviews: []PRReview{
+				{Reviewer: "carol", State: ReviewApproved, Timestamp: 400},
+				{Reviewer: "dave", State: ReviewApproved, Timestamp: 500},
+			},
+			commits:       commits,
+			headSha1:      "c2",
+			required:      2,
+			wantApprovers: []string{"carol", "dave"},
+			wantCompliant: true,
+			wantReason:    "2 of 2 required independent approval(s) after the last commit: carol, dave",
+		},
+		{
+			name: "authors and approvals before the last commit are not counted",
+			reviews: []PRReview{
+				{Reviewer: "Alice", State: ReviewApproved, Timestamp: 400},
+				{Reviewer: "bob", State: ReviewApproved, Timestamp: 400},
+				{Reviewer: "carol", State: ReviewApproved, Timestamp: 200},
+				{Reviewer: "dave", State: ReviewApproved, Timestamp: 400},
+			},
+			commits:       commits,
+			headSha1:      "c2",
+			required:      1,
+			wantApprovers: []string{"dave"},
+			wantCompliant: true,
+			wantReason: "1 of 1 required independent approval(s) after the last commit: dave; " +
+				"not counted: Alice (pull request author), bob (commit author), carol (approved before the last commit)",
+		},
+		{
+			name: "withdrawn approvals are not counted",
+			reviews: []PRReview{
+				{Reviewer: "carol", State: ReviewApproved, Timestamp: 400},
+				{Reviewer: "carol", State: ReviewDismissed, Timestamp: 450},
+			},
+			commits:       commits,
+			headSha1:      "c2",
+			required:      1,
+			wantApprovers: []string{},
+			wantCompliant: false,
+			wantReason:    "0 of 1 required independent approval(s) after the last commit",
+		},
+		{
+			name:          "approvals that cannot be placed against the last commit are not counted",
+			reviews:       []PRReview{{Reviewer: "carol", State: ReviewApproved}},
+			commits:       commits,
+			headSha1:      "c2",
+			required:      1,
+			wantApprovers: []string{},
+			wantCompliant: false,
+			wantReason: "0 of 1 required independent approval(s) after the last commit; " +
+				"not counted: carol (unknown whether approved after the last commit)",
+		},
+		{
+			name:    "a commit author without an account makes independence unverifiable",
+			reviews: []PRReview{{Reviewer: "bob", State: ReviewApproved, CommitSha1: "c2"}},
+			commits: []PRCommit{
+				{Sha1: "c1", Author: "alice"},
+				{Sha1: "c2", Author: "Bob Jones", AuthorUnresolved: true},
+			},
+			headSha1:      "c2",
+			required:      1,
+			wantApprovers: []string{"bob"},
+			wantCompliant: false,
+			wantReason: "1 of 1 required independent approval(s) after the last commit: bob; " +
+				"independence cannot be verified, commit author(s) not matched to an account: Bob Jones",
+		},
+		{
+			name:          "approvals cannot be placed without the head commit",
+			reviews:       []PRReview{{Reviewer: "carol", State: ReviewApproved, Timestamp: 400}},
+			commits:       commits,
+			required:      1,
+			wantApprovers: []string{},
+			wantCompliant: false,
+			wantReason:    "0 of 1 required independent approval(s), the last commit is unknown; not counted: carol (last commit unknown)",
+		},
+		{
+			name:          "approvals cannot be placed without commits",
+			reviews:       []PRReview{{Reviewer: "carol", State: ReviewApproved, Timestamp: 400}},
+			required:      1,
+			wantApprovers: []string{},
+			wantCompliant: false,
+			wantReason:    "0 of 1 required independent approval(s), the last commit is unknown; not counted: carol (last commit unknown)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evidence := &PREvidence{Author: "alice", Reviews: tt.reviews}
+			evidence.Summarize(tt.commits, tt.headSha1)
+
+			result := evidence.EvaluateIndependentApproval(tt.required)
+			assert.Equal(t, tt.required, result.Required)
+			assert.Equal(t, tt.wantApprovers, result.Approvers)
+			assert.Equal(t, tt.wantCompliant, result.Compliant)
+			assert.Equal(t, tt.wantReason, result.Reason)
+		})
+	}
+}